@@ -0,0 +1,51 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"pomodoroService/internal/auth"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// requestIDHeader carries the request ID back to the client, so a failed
+// request can be matched with its access log line
+const requestIDHeader = "X-Request-Id"
+
+// accessLog logs one line per request with its method, path, status, latency,
+// request ID and, once authenticated, the user ID. It must run after
+// middleware.RequestID.
+func accessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		requestID := middleware.GetReqID(r.Context())
+		if requestID != "" {
+			w.Header().Set(requestIDHeader, requestID)
+		}
+
+		ctx, userID := auth.WithAuthenticatedUser(r.Context())
+		// The wrapper records the status and keeps Hijack working for WebSockets
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		status := ww.Status()
+		if status == 0 {
+			// Nothing was written, so net/http replies 200
+			status = http.StatusOK
+		}
+
+		attrs := []any{
+			"request_id", requestID,
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", status,
+			"latency", time.Since(start),
+			"bytes", ww.BytesWritten(),
+		}
+		if *userID != "" {
+			attrs = append(attrs, "user_id", *userID)
+		}
+		slog.Info("request", attrs...)
+	})
+}
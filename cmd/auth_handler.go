@@ -4,21 +4,29 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"pomodoroService/internal/auth"
+	"pomodoroService/internal/httpjson"
 	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
 )
 
 // AuthHandler handles authentication-related HTTP requests
 type AuthHandler struct {
-	authRepo auth.AuthRepository
+	authRepo      auth.AuthRepository
+	verifications *auth.VerificationStore
 }
 
-// NewAuthHandler creates a new auth handler
-func NewAuthHandler(authRepo auth.AuthRepository) *AuthHandler {
+// NewAuthHandler creates a new auth handler. verifications may be nil, in which
+// case new accounts stay unverified until an admin verifies them manually.
+func NewAuthHandler(authRepo auth.AuthRepository, verifications *auth.VerificationStore) *AuthHandler {
 	return &AuthHandler{
-		authRepo: authRepo,
+		authRepo:      authRepo,
+		verifications: verifications,
 	}
 }
 
@@ -30,8 +38,8 @@ func (h *AuthHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req auth.NewUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON", "Failed to parse request body")
+	if err := httpjson.Decode(w, r, &req, httpjson.DefaultMaxBodyBytes); err != nil {
+		h.writeErrorResponse(w, httpjson.StatusCode(err), "Invalid JSON", err.Error())
 		return
 	}
 
@@ -51,16 +59,17 @@ func (h *AuthHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 
 	// Create user from request
 	user := auth.NewUserFromRequest(&req)
-	user.Email = &req.Email // Ensure email is set
 
 	// Create user in repository
 	if err := h.authRepo.CreateUser(user); err != nil {
 		log.Printf("Failed to create user: %v", err)
 
 		// Handle specific error messages
-		if strings.Contains(err.Error(), "username already exists") ||
-			strings.Contains(err.Error(), "email already exists") ||
-			strings.Contains(err.Error(), "already exists") {
+		if errors.Is(err, auth.ErrInvalidRole) {
+			h.writeErrorResponse(w, http.StatusBadRequest, "Validation error", err.Error())
+			return
+		}
+		if errors.Is(err, auth.ErrUserExists) {
 			h.writeErrorResponse(w, http.StatusConflict, "User already exists", err.Error())
 			return
 		}
@@ -77,6 +86,8 @@ func (h *AuthHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.startEmailVerification(user)
+
 	// Generate JWT token for the newly created user
 	token, err := auth.GenerateJWT(user)
 	if err != nil {
@@ -118,8 +129,8 @@ func (h *AuthHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var creds auth.UserLoginCredentials
-	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON", "Failed to parse request body")
+	if err := httpjson.Decode(w, r, &creds, httpjson.DefaultMaxBodyBytes); err != nil {
+		h.writeErrorResponse(w, httpjson.StatusCode(err), "Invalid JSON", err.Error())
 		return
 	}
 
@@ -133,8 +144,19 @@ func (h *AuthHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Look the user up by the normalized username they registered with
+	creds.Username = auth.NormalizeUsername(creds.Username)
+
 	// Authenticate user
 	isAuthenticated, err := h.authRepo.AuthenticateUser(&creds)
+	if errors.Is(err, auth.ErrAccountLocked) {
+		h.writeErrorResponse(w, http.StatusLocked, "Account locked", "Too many failed logins, try again later")
+		return
+	}
+	if errors.Is(err, auth.ErrInvalidCredentials) {
+		h.writeErrorResponse(w, http.StatusUnauthorized, "Invalid credentials", "Username or password is incorrect")
+		return
+	}
 	if err != nil {
 		log.Printf("Authentication error: %v", err)
 		h.writeErrorResponse(w, http.StatusInternalServerError, "Authentication failed", "Internal server error")
@@ -151,6 +173,10 @@ func (h *AuthHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 	// log.Printf("user: %v", user)
 	if err != nil {
 		log.Printf("Failed to get user info: %v", err)
+		if errors.Is(err, auth.ErrUserNotFound) {
+			h.writeErrorResponse(w, http.StatusUnauthorized, "Invalid credentials", "Username or password is incorrect")
+			return
+		}
 		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to get user info", "Internal server error")
 		return
 	}
@@ -207,17 +233,22 @@ func (h *AuthHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	user, err := h.authRepo.GetUserInfo(username)
 	if err != nil {
 		log.Printf("Failed to get user info: %v", err)
+		if errors.Is(err, auth.ErrUserNotFound) {
+			h.writeErrorResponse(w, http.StatusNotFound, "User not found", "No user with that username")
+			return
+		}
 		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to get user info", "Internal server error")
 		return
 	}
 
 	// Return user profile
 	profile := map[string]interface{}{
-		"id":         *user.ID,
-		"username":   *user.Username,
-		"email":      *user.Email,
-		"role":       *user.Role,
-		"created_at": *user.CreatedAt,
+		"id":             *user.ID,
+		"username":       *user.Username,
+		"email":          *user.Email,
+		"role":           *user.Role,
+		"created_at":     *user.CreatedAt,
+		"email_verified": user.EmailVerified != nil && *user.EmailVerified,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -228,8 +259,40 @@ func (h *AuthHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// RegisterAdminUser handles admin user registration
-// WARNING: Development/testing only - disable in production
+// WhoAmI describes the caller's token from the request context without querying
+// the database, including how long the token stays valid so clients can
+// schedule a refresh
+func (h *AuthHandler) WhoAmI(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaimsFromContext(r.Context())
+	_, _, role, hasUser := auth.GetUserFromContext(r.Context())
+	if !ok || !hasUser || claims.ExpiresAt == nil {
+		h.writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized", "Token claims not found in context")
+		return
+	}
+
+	response := auth.WhoAmIResponse{
+		UserID:    claims.UserID,
+		Username:  claims.Username,
+		Role:      role,
+		Issuer:    claims.Issuer,
+		Audience:  claims.Audience,
+		ExpiresAt: claims.ExpiresAt.UTC().Format(time.RFC3339),
+		ExpiresIn: max(int64(time.Until(claims.ExpiresAt.Time).Seconds()), 0),
+	}
+	if claims.IssuedAt != nil {
+		response.IssuedAt = claims.IssuedAt.UTC().Format(time.RFC3339)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Failed to encode whoami response: %v", err)
+	}
+}
+
+// RegisterAdminUser handles admin user registration.
+// Requires an existing admin; prefer POST /admin/users for new code.
 func (h *AuthHandler) RegisterAdminUser(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		h.writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "Use POST method")
@@ -237,8 +300,8 @@ func (h *AuthHandler) RegisterAdminUser(w http.ResponseWriter, r *http.Request)
 	}
 
 	var req auth.NewUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON", "Failed to parse request body")
+	if err := httpjson.Decode(w, r, &req, httpjson.DefaultMaxBodyBytes); err != nil {
+		h.writeErrorResponse(w, httpjson.StatusCode(err), "Invalid JSON", err.Error())
 		return
 	}
 
@@ -258,16 +321,13 @@ func (h *AuthHandler) RegisterAdminUser(w http.ResponseWriter, r *http.Request)
 
 	// Create admin user from request
 	user := auth.NewAdminUserFromRequest(&req)
-	user.Email = &req.Email // Ensure email is set
 
 	// Create user in repository
 	if err := h.authRepo.CreateUser(user); err != nil {
 		log.Printf("Failed to create admin user: %v", err)
 
 		// Handle specific error messages
-		if strings.Contains(err.Error(), "username already exists") ||
-			strings.Contains(err.Error(), "email already exists") ||
-			strings.Contains(err.Error(), "already exists") {
+		if errors.Is(err, auth.ErrUserExists) {
 			h.writeErrorResponse(w, http.StatusConflict, "User already exists", err.Error())
 			return
 		}
@@ -284,6 +344,8 @@ func (h *AuthHandler) RegisterAdminUser(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	h.startEmailVerification(user)
+
 	// Generate JWT token for the newly created admin user
 	token, err := auth.GenerateJWT(user)
 	if err != nil {
@@ -315,3 +377,283 @@ func (h *AuthHandler) RegisterAdminUser(w http.ResponseWriter, r *http.Request)
 		return
 	}
 }
+
+// CreateUserWithRole handles admin provisioning of a user account with an explicit role
+func (h *AuthHandler) CreateUserWithRole(w http.ResponseWriter, r *http.Request) {
+	var req auth.CreateUserRequest
+	if err := httpjson.Decode(w, r, &req, httpjson.DefaultMaxBodyBytes); err != nil {
+		h.writeErrorResponse(w, httpjson.StatusCode(err), "Invalid JSON", err.Error())
+		return
+	}
+
+	// Validate request
+	if strings.TrimSpace(req.Username) == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Validation error", "Username is required")
+		return
+	}
+	if strings.TrimSpace(req.Password) == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Validation error", "Password is required")
+		return
+	}
+	if strings.TrimSpace(req.Email) == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Validation error", "Email is required")
+		return
+	}
+	if _, err := auth.ParseRole(req.Role); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Validation error", err.Error())
+		return
+	}
+
+	user := auth.NewUserWithRoleFromRequest(&req)
+
+	// Create user in repository
+	if err := h.authRepo.CreateUser(user); err != nil {
+		log.Printf("Failed to create user: %v", err)
+
+		// Handle specific error messages
+		if errors.Is(err, auth.ErrInvalidRole) {
+			h.writeErrorResponse(w, http.StatusBadRequest, "Validation error", err.Error())
+			return
+		}
+		if errors.Is(err, auth.ErrUserExists) {
+			h.writeErrorResponse(w, http.StatusConflict, "User already exists", err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "invalid email format") {
+			h.writeErrorResponse(w, http.StatusBadRequest, "Invalid email format", err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "required") {
+			h.writeErrorResponse(w, http.StatusBadRequest, "Validation error", err.Error())
+			return
+		}
+
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to create user", "Internal server error")
+		return
+	}
+
+	h.startEmailVerification(user)
+
+	// No token is issued; the new user logs in with their own credentials
+	response := auth.UserRegistrationResponse{
+		ID:       *user.ID,
+		Username: *user.Username,
+		Email:    *user.Email,
+		Role:     *user.Role,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Failed to encode response: %v", err)
+	}
+}
+
+// GetRoles lists the valid user roles and the role given to self-registered users
+func (h *AuthHandler) GetRoles(w http.ResponseWriter, r *http.Request) {
+	response := auth.RolesResponse{
+		Roles:       auth.ValidRoles(),
+		DefaultRole: auth.GetDefaultRole(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Failed to encode response: %v", err)
+	}
+}
+
+// UpdateUserRole handles promoting or demoting an existing user.
+// Roles are re-read from the database on every request, so the change applies to existing tokens immediately.
+func (h *AuthHandler) UpdateUserRole(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+	if strings.TrimSpace(username) == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Validation error", "Username is required")
+		return
+	}
+
+	var req auth.UpdateRoleRequest
+	if err := httpjson.Decode(w, r, &req, httpjson.DefaultMaxBodyBytes); err != nil {
+		h.writeErrorResponse(w, httpjson.StatusCode(err), "Invalid JSON", err.Error())
+		return
+	}
+	if _, err := auth.ParseRole(req.Role); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Validation error", err.Error())
+		return
+	}
+
+	if err := h.authRepo.UpdateUserRole(username, req.Role); err != nil {
+		log.Printf("Failed to update user role: %v", err)
+		if errors.Is(err, auth.ErrUserNotFound) {
+			h.writeErrorResponse(w, http.StatusNotFound, "User not found", "No user with that username")
+			return
+		}
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to update role", "Internal server error")
+		return
+	}
+
+	user, err := h.authRepo.GetUserInfo(username)
+	if err != nil {
+		log.Printf("Failed to get user info: %v", err)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to get user info", "Internal server error")
+		return
+	}
+
+	response := auth.UserRegistrationResponse{
+		ID:       *user.ID,
+		Username: *user.Username,
+		Email:    *user.Email,
+		Role:     *user.Role,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Failed to encode response: %v", err)
+	}
+}
+
+// startEmailVerification issues a verification token for a newly created user.
+// Email delivery is not wired up yet, so the verification link is logged.
+func (h *AuthHandler) startEmailVerification(user *auth.User) {
+	if h.verifications == nil {
+		log.Printf("Email verification unavailable; %s stays unverified", *user.Username)
+		return
+	}
+
+	token, err := h.verifications.CreateToken(*user.Username)
+	if err != nil {
+		log.Printf("Failed to create verification token for %s: %v", *user.Username, err)
+		return
+	}
+
+	log.Printf("Email verification link for %s: /auth/verify?token=%s", *user.Username, token)
+}
+
+// VerifyEmail handles the link sent to new users and marks their email as verified
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if strings.TrimSpace(token) == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Validation error", "Token is required")
+		return
+	}
+
+	if h.verifications == nil {
+		h.writeErrorResponse(w, http.StatusServiceUnavailable, "Verification unavailable", "Email verification is not configured")
+		return
+	}
+
+	username, err := h.verifications.ConsumeToken(token)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidVerificationToken) {
+			h.writeErrorResponse(w, http.StatusBadRequest, "Invalid token", err.Error())
+			return
+		}
+		log.Printf("Failed to consume verification token: %v", err)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to verify email", "Internal server error")
+		return
+	}
+
+	if err := h.authRepo.VerifyEmail(username); err != nil {
+		log.Printf("Failed to verify email for %s: %v", username, err)
+		if errors.Is(err, auth.ErrUserNotFound) {
+			h.writeErrorResponse(w, http.StatusNotFound, "User not found", "No user with that username")
+			return
+		}
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to verify email", "Internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(auth.SuccessResponse{Success: true, Message: "Email verified"}); err != nil {
+		log.Printf("Failed to encode response: %v", err)
+	}
+}
+
+// DeleteUser handles an admin removing another user's account
+func (h *AuthHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+	if strings.TrimSpace(username) == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Validation error", "Username is required")
+		return
+	}
+
+	user, err := h.authRepo.GetUserInfo(username)
+	if err != nil {
+		log.Printf("Failed to get user info: %v", err)
+		if errors.Is(err, auth.ErrUserNotFound) {
+			h.writeErrorResponse(w, http.StatusNotFound, "User not found", "No user with that username")
+			return
+		}
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to get user info", "Internal server error")
+		return
+	}
+
+	h.deleteUser(w, *user.ID, username)
+}
+
+// DeleteAccount handles a user deleting their own account. The password is
+// re-confirmed so a stolen token alone cannot remove the account; failures
+// count towards the login lockout.
+func (h *AuthHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	userID, username, _, ok := auth.GetUserFromContext(r.Context())
+	if !ok {
+		h.writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized", "User information not found in context")
+		return
+	}
+
+	var req auth.DeleteAccountRequest
+	if err := httpjson.Decode(w, r, &req, httpjson.DefaultMaxBodyBytes); err != nil {
+		h.writeErrorResponse(w, httpjson.StatusCode(err), "Invalid JSON", err.Error())
+		return
+	}
+	if strings.TrimSpace(req.Password) == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Validation error", "Password is required")
+		return
+	}
+
+	isAuthenticated, err := h.authRepo.AuthenticateUser(&auth.UserLoginCredentials{Username: username, Password: req.Password})
+	if errors.Is(err, auth.ErrAccountLocked) {
+		h.writeErrorResponse(w, http.StatusLocked, "Account locked", "Too many failed logins, try again later")
+		return
+	}
+	if errors.Is(err, auth.ErrInvalidCredentials) || (err == nil && !isAuthenticated) {
+		h.writeErrorResponse(w, http.StatusUnauthorized, "Invalid credentials", "Password is incorrect")
+		return
+	}
+	if err != nil {
+		log.Printf("Authentication error: %v", err)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Authentication failed", "Internal server error")
+		return
+	}
+
+	h.deleteUser(w, userID, username)
+}
+
+// deleteUser removes the account and writes the response. Tokens already
+// issued to the user stop working because the middleware reloads the user on
+// every request.
+func (h *AuthHandler) deleteUser(w http.ResponseWriter, userID, username string) {
+	if err := h.authRepo.DeleteUser(userID); err != nil {
+		log.Printf("Failed to delete user %s: %v", username, err)
+		if errors.Is(err, auth.ErrUserNotFound) {
+			h.writeErrorResponse(w, http.StatusNotFound, "User not found", "No user with that username")
+			return
+		}
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to delete user", "Internal server error")
+		return
+	}
+	log.Printf("Deleted user %s", username)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(auth.SuccessResponse{Success: true, Message: "User deleted"}); err != nil {
+		log.Printf("Failed to encode response: %v", err)
+	}
+}
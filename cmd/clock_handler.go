@@ -2,9 +2,14 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 	"pomodoroService/internal/clock"
+	"pomodoroService/internal/httpjson"
 	"time"
+
+	"github.com/go-chi/chi/v5"
 )
 
 func NewClockHandler(clockRunner *clock.ClockRunner) *ClockHandler {
@@ -22,20 +27,54 @@ type SystemStateResponse struct {
 		LongBreakDuration  int    `json:"longBreakDuration"`
 		ShortBreakDuration int    `json:"shortBreakDuration"`
 		Scheduling         string `json:"scheduling"`
+		IdleTimeout        int    `json:"idleTimeout"` // in minutes, 0 when disabled
+		AutoStartNext      bool   `json:"autoStartNext"`
+		DailyWorkGoal      int    `json:"dailyWorkGoal"` // work sessions per day, 0 when unset
 	} `json:"pomodoroSetting"`
 	CurrentSession int    `json:"currentSession"`
 	EndTime        string `json:"endTime"`
 	ServerTime     string `json:"serverTime"`
+	Timezone       string `json:"timezone"`
 	IsActive       bool   `json:"isActive"`
+	// PauseReason is why the session was paused; omitted unless paused with a reason
+	PauseReason   string `json:"pauseReason,omitempty"`
+	CycleProgress struct {
+		Total   int64   `json:"total"`   // in seconds
+		Elapsed int64   `json:"elapsed"` // in seconds
+		Percent float64 `json:"percent"`
+	} `json:"cycleProgress"`
+	// SessionProgress is how far the current session has run; zero while idle
+	SessionProgress struct {
+		Total   int64   `json:"total"`   // in seconds
+		Elapsed int64   `json:"elapsed"` // in seconds
+		Percent float64 `json:"percent"`
+	} `json:"sessionProgress"`
+	// Work time completed today in the clock's timezone, in seconds and as a
+	// label such as "2 hours 15 minutes"
+	FocusTimeToday      int64  `json:"focusTimeToday"`
+	FocusTimeTodayLabel string `json:"focusTimeTodayLabel"`
+	// IsLastSession is true on the last session of the cycle, e.g. the long break
+	IsLastSession bool                 `json:"isLastSession"`
+	NextSession   *NextSessionResponse `json:"nextSession"` // nil when the current session is not in the schedule
+}
+
+// NextSessionResponse previews the session that follows the current one
+type NextSessionResponse struct {
+	Session  int    `json:"session"` // 0-based
+	State    string `json:"state"`
+	Duration int64  `json:"duration"` // in seconds
+	// NewCycle is true when the next session starts the cycle over
+	NewCycle bool `json:"newCycle"`
 }
 
 func (h *ClockHandler) GetSystemState(w http.ResponseWriter, r *http.Request) {
-	// Get current time
-	now := time.Now()
+	// Get current time in the clock's timezone
+	loc := h.clockRunner.GetLocation()
+	now := time.Now().In(loc)
 
 	// Get schedule and format it
-	schedule := h.clockRunner.GetSchedule()
-	scheduling := clock.FormatScheduling(schedule)
+	schedule := h.clockRunner.GetScheduleSpecs()
+	scheduling := clock.FormatSchedulingSpecs(schedule)
 
 	// Load state from Redis once for consistency
 	var redisState *clock.SystemState
@@ -83,14 +122,49 @@ func (h *ClockHandler) GetSystemState(w http.ResponseWriter, r *http.Request) {
 	response.PomodoroSetting.LongBreakDuration = longBreakDuration
 	response.PomodoroSetting.ShortBreakDuration = shortBreakDuration
 	response.PomodoroSetting.Scheduling = scheduling
+	response.PomodoroSetting.IdleTimeout = int(h.clockRunner.GetIdleTimeout().Minutes())
+	response.PomodoroSetting.AutoStartNext = h.clockRunner.GetAutoStartNext()
+	response.PomodoroSetting.DailyWorkGoal = h.clockRunner.GetDailyWorkGoal()
 
 	// Set session info
 	response.CurrentSession = currentSession
 
+	// Preview the next session; the cycle wraps around after the last one
+	_, _, totalSessions, _ := h.clockRunner.GetSessionInfo()
+	if currentSession >= 0 && currentSession < totalSessions {
+		next := (currentSession + 1) % totalSessions
+		nextState, _, _, nextDuration := h.clockRunner.GetSessionInfoAt(next)
+		response.IsLastSession = currentSession == totalSessions-1
+		response.NextSession = &NextSessionResponse{
+			Session:  next,
+			State:    string(nextState),
+			Duration: int64(nextDuration.Seconds()),
+			NewCycle: next == 0,
+		}
+	}
+
 	// Set times
-	response.EndTime = endTime.Format(time.RFC3339)
+	response.EndTime = endTime.In(loc).Format(time.RFC3339)
 	response.ServerTime = now.Format(time.RFC3339)
+	response.Timezone = loc.String()
 	response.IsActive = h.clockRunner.IsRunning()
+	response.PauseReason = h.clockRunner.GetPauseReason()
+
+	// Set cycle progress
+	progress := h.clockRunner.GetCycleProgress()
+	response.CycleProgress.Total = int64(progress.Total.Seconds())
+	response.CycleProgress.Elapsed = int64(progress.Elapsed.Seconds())
+	response.CycleProgress.Percent = progress.Percent
+
+	sessionProgress := h.clockRunner.GetSessionProgress()
+	response.SessionProgress.Total = int64(sessionProgress.Total.Seconds())
+	response.SessionProgress.Elapsed = int64(sessionProgress.Elapsed.Seconds())
+	response.SessionProgress.Percent = sessionProgress.Percent
+
+	// Set focus time today
+	focusTime := h.clockRunner.GetTodayFocusTime()
+	response.FocusTimeToday = int64(focusTime.Seconds())
+	response.FocusTimeTodayLabel = clock.NewTimeFormatter().FormatDurationLong(focusTime)
 
 	// Set response headers
 	w.Header().Set("Content-Type", "application/json")
@@ -100,12 +174,699 @@ func (h *ClockHandler) GetSystemState(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// RawSystemStateResponse shows the system state stored in Redis before and
+// after repair, and the repairs applied
+type RawSystemStateResponse struct {
+	Raw      *clock.SystemState `json:"raw"`
+	Repaired *clock.SystemState `json:"repaired"`
+	Repairs  []string           `json:"repairs"`
+}
+
+// GetRawSystemState returns the system state as stored in Redis alongside the
+// repaired state the server uses, so operators can see when Redis holds bad data
+func (h *ClockHandler) GetRawSystemState(w http.ResponseWriter, r *http.Request) {
+	redisPersistence := h.clockRunner.GetRedisPersistence()
+	if redisPersistence == nil {
+		http.Error(w, "system state is not persisted without Redis", http.StatusServiceUnavailable)
+		return
+	}
+
+	load, err := redisPersistence.LoadSystemStateWithRepairs()
+	if err != nil {
+		log.Printf("Failed to load raw system state: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	response := RawSystemStateResponse{Raw: load.Raw, Repaired: load.Repaired, Repairs: load.Repairs}
+	if response.Repairs == nil {
+		response.Repairs = []string{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
 func (h *ClockHandler) StartNewPomodoro(w http.ResponseWriter, r *http.Request) {
-	err := h.clockRunner.Start()
+	// With ?idempotent=true, starting an already running clock is not an error;
+	// the current system state is returned instead
+	if r.URL.Query().Get("idempotent") == "true" {
+		if _, _, err := h.clockRunner.StartIdempotent(); err != nil {
+			http.Error(w, err.Error(), clockActionErrorStatus(err))
+			return
+		}
+		h.GetSystemState(w, r)
+		return
+	}
+
+	result, err := h.clockRunner.Start()
+	if err != nil {
+		http.Error(w, err.Error(), clockActionErrorStatus(err))
+		return
+	}
+
+	// Respond with the snapshot taken during the transition rather than re-reading
+	// the clock, which may already have moved on
+	response := newClockActionResponse(result, h.clockRunner.GetLocation())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// RepeatLastSession runs the session that just completed once more
+func (h *ClockHandler) RepeatLastSession(w http.ResponseWriter, r *http.Request) {
+	result, err := h.clockRunner.RepeatLastSession()
+	if err != nil {
+		switch {
+		case errors.Is(err, clock.ErrNothingToRepeat), errors.Is(err, clock.ErrTimerCompleting):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newClockActionResponse(result, h.clockRunner.GetLocation()))
+}
+
+// BackToPreviousSession abandons the current session and restarts the one before it
+func (h *ClockHandler) BackToPreviousSession(w http.ResponseWriter, r *http.Request) {
+	result, err := h.clockRunner.Back()
 	if err != nil {
+		switch {
+		case errors.Is(err, clock.ErrNoPreviousSession), errors.Is(err, clock.ErrNoActiveSession), errors.Is(err, clock.ErrTimerCompleting):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newClockActionResponse(result, h.clockRunner.GetLocation()))
+}
+
+// SessionInfoResponse describes the current session for compact displays
+type SessionInfoResponse struct {
+	State          string `json:"state"`          // the scheduled session state, even while idle or paused
+	CurrentSession int    `json:"currentSession"` // 0-based
+	TotalSessions  int    `json:"totalSessions"`
+	Duration       int64  `json:"duration"` // in seconds
+	Label          string `json:"label"`
+}
+
+// GetSessionInfo returns the current session and a display label such as
+// "Work Session 2/8 (25:00)"
+func (h *ClockHandler) GetSessionInfo(w http.ResponseWriter, r *http.Request) {
+	state, sessionNum, totalSessions, duration := h.clockRunner.GetSessionInfo()
+	response := SessionInfoResponse{
+		State:          string(state),
+		CurrentSession: sessionNum,
+		TotalSessions:  totalSessions,
+		Duration:       int64(duration.Seconds()),
+		Label:          h.clockRunner.FormatSessionInfo(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// SessionTagRequest carries the tag for the current session
+type SessionTagRequest struct {
+	Tag string `json:"tag"`
+}
+
+// SetSessionTag labels the current session; an empty tag clears the label
+func (h *ClockHandler) SetSessionTag(w http.ResponseWriter, r *http.Request) {
+	var req SessionTagRequest
+	if err := httpjson.Decode(w, r, &req, httpjson.DefaultMaxBodyBytes); err != nil {
+		http.Error(w, err.Error(), httpjson.StatusCode(err))
+		return
+	}
+
+	if err := h.clockRunner.SetSessionTag(req.Tag); err != nil {
+		switch {
+		case errors.Is(err, clock.ErrTagTooLong):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, clock.ErrNoActiveSession):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SessionTagRequest{Tag: h.clockRunner.GetSessionTag()})
+}
+
+// AdjustTimeRequest adds seconds to the current session; negative values remove time
+type AdjustTimeRequest struct {
+	DeltaSeconds int64 `json:"deltaSeconds"`
+}
+
+// AdjustTime adds or removes time from the current session, running or paused
+func (h *ClockHandler) AdjustTime(w http.ResponseWriter, r *http.Request) {
+	var req AdjustTimeRequest
+	if err := httpjson.Decode(w, r, &req, httpjson.DefaultMaxBodyBytes); err != nil {
+		http.Error(w, err.Error(), httpjson.StatusCode(err))
+		return
+	}
+	delta := time.Duration(req.DeltaSeconds) * time.Second
+	if delta/time.Second != time.Duration(req.DeltaSeconds) {
+		http.Error(w, "deltaSeconds is out of range", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.clockRunner.AdjustTime(delta)
+	if err != nil {
+		switch {
+		case errors.Is(err, clock.ErrNoActiveSession), errors.Is(err, clock.ErrTimerCompleting), errors.Is(err, clock.ErrNoTimer):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newClockActionResponse(result, h.clockRunner.GetLocation()))
+}
+
+// ClockActionResponse represents the clock state right after a start, pause, stop, or skip
+type ClockActionResponse struct {
+	State          string `json:"state"`
+	CurrentSession int    `json:"currentSession"`
+	TimeRemaining  int64  `json:"timeRemaining"` // in milliseconds
+	EndTime        string `json:"endTime,omitempty"`
+}
+
+// clockActionErrorStatus maps errors from clock transitions to a status code:
+// transitions the clock's current state does not allow conflict with it
+func clockActionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, clock.ErrNotStartable),
+		errors.Is(err, clock.ErrNotRunning),
+		errors.Is(err, clock.ErrAlreadyIdle):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func newClockActionResponse(result clock.StartResult, loc *time.Location) ClockActionResponse {
+	response := ClockActionResponse{
+		State:          string(result.State),
+		CurrentSession: result.Session,
+		TimeRemaining:  result.TimeRemaining.Milliseconds(),
+	}
+	if !result.EndTime.IsZero() {
+		response.EndTime = result.EndTime.In(loc).Format(time.RFC3339)
+	}
+	return response
+}
+
+// ScheduleEntry describes one session of the pomodoro schedule
+type ScheduleEntry struct {
+	Index     int    `json:"index"`
+	State     string `json:"state"`
+	Duration  int64  `json:"duration"` // in seconds
+	IsCurrent bool   `json:"isCurrent"`
+}
+
+// ScheduleResponse represents the full schedule with progress
+type ScheduleResponse struct {
+	Sessions       []ScheduleEntry `json:"sessions"`
+	CurrentSession int             `json:"currentSession"`
+	IsActive       bool            `json:"isActive"`
+	Summary        struct {
+		WorkSessions int `json:"workSessions"`
+		ShortBreaks  int `json:"shortBreaks"`
+		LongBreaks   int `json:"longBreaks"`
+		CustomBreaks int `json:"customBreaks"`
+	} `json:"summary"`
+}
+
+// GetSchedule returns the ordered list of sessions with the current one marked
+func (h *ClockHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	currentSession := h.clockRunner.GetCurrentSession()
+	totalSessions := h.clockRunner.GetTotalSessions()
+
+	response := ScheduleResponse{
+		Sessions:       make([]ScheduleEntry, 0, totalSessions),
+		CurrentSession: currentSession,
+		IsActive:       h.clockRunner.IsRunning(),
+	}
+
+	for i := 0; i < totalSessions; i++ {
+		state, _, _, duration := h.clockRunner.GetSessionInfoAt(i)
+		response.Sessions = append(response.Sessions, ScheduleEntry{
+			Index:     i,
+			State:     string(state),
+			Duration:  int64(duration.Seconds()),
+			IsCurrent: i == currentSession,
+		})
+	}
+
+	summary := h.clockRunner.GetScheduleSummary()
+	response.Summary.WorkSessions = summary[clock.StateWorking]
+	response.Summary.ShortBreaks = summary[clock.StateShortBreak]
+	response.Summary.LongBreaks = summary[clock.StateLongBreak]
+	response.Summary.CustomBreaks = summary[clock.StateCustomBreak]
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// FormattedDuration is a duration in seconds with its display forms: clock
+// ("25:00"), short ("25m") and long ("25 minutes")
+type FormattedDuration struct {
+	Seconds int64  `json:"seconds"`
+	Clock   string `json:"clock"`
+	Short   string `json:"short"`
+	Long    string `json:"long"`
+}
+
+func newFormattedDuration(formatter *clock.TimeFormatter, d time.Duration) FormattedDuration {
+	return FormattedDuration{
+		Seconds: int64(d.Seconds()),
+		Clock:   formatter.FormatDuration(d),
+		Short:   formatter.FormatDurationString(d),
+		Long:    formatter.FormatDurationLong(d),
+	}
+}
+
+// FormattedScheduleEntry describes one session with its formatted duration
+type FormattedScheduleEntry struct {
+	Index     int               `json:"index"`
+	State     string            `json:"state"`
+	Duration  FormattedDuration `json:"duration"`
+	IsCurrent bool              `json:"isCurrent"`
+}
+
+// FormattedScheduleResponse is the schedule with display-ready durations
+type FormattedScheduleResponse struct {
+	Sessions       []FormattedScheduleEntry `json:"sessions"`
+	CurrentSession int                      `json:"currentSession"`
+	Total          FormattedDuration        `json:"total"`
+}
+
+// GetFormattedSchedule returns the schedule like GetSchedule, with every
+// session's duration and the cycle total already formatted for display
+func (h *ClockHandler) GetFormattedSchedule(w http.ResponseWriter, r *http.Request) {
+	formatter := clock.NewTimeFormatter()
+	currentSession := h.clockRunner.GetCurrentSession()
+	totalSessions := h.clockRunner.GetTotalSessions()
+
+	response := FormattedScheduleResponse{
+		Sessions:       make([]FormattedScheduleEntry, 0, totalSessions),
+		CurrentSession: currentSession,
+	}
+	var total time.Duration
+	for i := 0; i < totalSessions; i++ {
+		state, _, _, duration := h.clockRunner.GetSessionInfoAt(i)
+		total += duration
+		response.Sessions = append(response.Sessions, FormattedScheduleEntry{
+			Index:     i,
+			State:     string(state),
+			Duration:  newFormattedDuration(formatter, duration),
+			IsCurrent: i == currentSession,
+		})
+	}
+	response.Total = newFormattedDuration(formatter, total)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// RemainingSessionsResponse counts the sessions left in the cycle, including
+// the current one
+type RemainingSessionsResponse struct {
+	CurrentSession int `json:"currentSession"`
+	WorkSessions   int `json:"workSessions"`
+	ShortBreaks    int `json:"shortBreaks"`
+	LongBreaks     int `json:"longBreaks"`
+	CustomBreaks   int `json:"customBreaks"`
+}
+
+// GetRemainingSessions returns how many sessions of each type are left from the
+// current session to the end of the cycle
+func (h *ClockHandler) GetRemainingSessions(w http.ResponseWriter, r *http.Request) {
+	remaining := h.clockRunner.GetRemainingSessions()
+	response := RemainingSessionsResponse{
+		CurrentSession: h.clockRunner.GetCurrentSession(),
+		WorkSessions:   remaining[clock.StateWorking],
+		ShortBreaks:    remaining[clock.StateShortBreak],
+		LongBreaks:     remaining[clock.StateLongBreak],
+		CustomBreaks:   remaining[clock.StateCustomBreak],
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// timeFormatMillis is RFC3339 with milliseconds, for timestamps clients compare
+// against their own clock
+const timeFormatMillis = "2006-01-02T15:04:05.000Z07:00"
+
+// ServerTimeResponse is the server's current time, for clients estimating the
+// offset between their clock and the server's
+type ServerTimeResponse struct {
+	ServerTime   string `json:"serverTime"`   // RFC3339 with milliseconds, in the clock's timezone
+	ServerTimeMs int64  `json:"serverTimeMs"` // Unix milliseconds
+	Timezone     string `json:"timezone"`
+	// RoundTripHint is how long the server held the request, in milliseconds;
+	// subtract it from the measured round trip before halving it
+	RoundTripHint int64 `json:"roundTripHint"`
+}
+
+// GetServerTime returns the current server time in the same timezone as the
+// endTime of GET /system/state. A client estimates its offset as
+// serverTimeMs + (roundTrip - roundTripHint) / 2 - its time on receipt.
+func (h *ClockHandler) GetServerTime(w http.ResponseWriter, r *http.Request) {
+	received := time.Now()
+	loc := h.clockRunner.GetLocation()
+
+	now := time.Now().In(loc)
+	response := ServerTimeResponse{
+		ServerTime:    now.Format(timeFormatMillis),
+		ServerTimeMs:  now.UnixMilli(),
+		Timezone:      loc.String(),
+		RoundTripHint: now.Sub(received).Milliseconds(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// BehaviorResponse is the effective behavior settings of the clock
+type BehaviorResponse struct {
+	AutoStartNext       bool  `json:"autoStartNext"`
+	StrictResume        bool  `json:"strictResume"`
+	IdleTimeoutSeconds  int64 `json:"idleTimeoutSeconds"`  // 0 when disabled
+	RepeatWindowSeconds int64 `json:"repeatWindowSeconds"` // 0 when disabled
+	PauseWhenUnwatched  bool  `json:"pauseWhenUnwatched"`
+	// UnwatchedGraceSeconds is how long the clock keeps running after the
+	// last WebSocket client disconnects before it pauses
+	UnwatchedGraceSeconds int64 `json:"unwatchedGraceSeconds"`
+}
+
+func newBehaviorResponse(behavior clock.BehaviorSettings) BehaviorResponse {
+	return BehaviorResponse{
+		AutoStartNext:       behavior.AutoStartNext,
+		StrictResume:        behavior.StrictResume,
+		IdleTimeoutSeconds:  int64(behavior.IdleTimeout.Seconds()),
+		RepeatWindowSeconds: int64(behavior.RepeatWindow.Seconds()),
+
+		PauseWhenUnwatched:    behavior.PauseWhenUnwatched,
+		UnwatchedGraceSeconds: int64(behavior.UnwatchedGrace.Seconds()),
+	}
+}
+
+// BehaviorRequest changes behavior settings; omitted fields keep their value
+type BehaviorRequest struct {
+	AutoStartNext       *bool  `json:"autoStartNext"`
+	StrictResume        *bool  `json:"strictResume"`
+	IdleTimeoutSeconds  *int64 `json:"idleTimeoutSeconds"`
+	RepeatWindowSeconds *int64 `json:"repeatWindowSeconds"`
+
+	PauseWhenUnwatched    *bool  `json:"pauseWhenUnwatched"`
+	UnwatchedGraceSeconds *int64 `json:"unwatchedGraceSeconds"`
+}
+
+// GetBehavior returns the behavior settings: auto-start-next, strict resume,
+// idle timeout, repeat window and pausing when unwatched
+func (h *ClockHandler) GetBehavior(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newBehaviorResponse(h.clockRunner.GetBehavior()))
+}
+
+// UpdateBehavior changes any of the behavior settings in one request and
+// returns the effective settings. Nothing changes if a field is invalid.
+func (h *ClockHandler) UpdateBehavior(w http.ResponseWriter, r *http.Request) {
+	var req BehaviorRequest
+	if err := httpjson.Decode(w, r, &req, httpjson.DefaultMaxBodyBytes); err != nil {
+		http.Error(w, err.Error(), httpjson.StatusCode(err))
+		return
+	}
+
+	behavior := h.clockRunner.GetBehavior()
+	if req.AutoStartNext != nil {
+		behavior.AutoStartNext = *req.AutoStartNext
+	}
+	if req.StrictResume != nil {
+		behavior.StrictResume = *req.StrictResume
+	}
+	if req.IdleTimeoutSeconds != nil {
+		behavior.IdleTimeout = time.Duration(*req.IdleTimeoutSeconds) * time.Second
+	}
+	if req.RepeatWindowSeconds != nil {
+		behavior.RepeatWindow = time.Duration(*req.RepeatWindowSeconds) * time.Second
+	}
+	if req.PauseWhenUnwatched != nil {
+		behavior.PauseWhenUnwatched = *req.PauseWhenUnwatched
+	}
+	if req.UnwatchedGraceSeconds != nil {
+		behavior.UnwatchedGrace = time.Duration(*req.UnwatchedGraceSeconds) * time.Second
+	}
+
+	effective, err := h.clockRunner.SetBehavior(behavior)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newBehaviorResponse(effective))
+}
+
+// GetPresets returns the named duration presets in minutes
+func (h *ClockHandler) GetPresets(w http.ResponseWriter, r *http.Request) {
+	presets := make(map[string]int)
+	for name, duration := range h.clockRunner.GetRecommendedDurations() {
+		presets[name] = int(duration.Minutes())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(presets)
+}
+
+// ApplyPresetRequest names the preset to apply
+type ApplyPresetRequest struct {
+	Preset string `json:"preset"`
+}
+
+// ApplyPresetResponse reports the durations set by a preset, in minutes
+type ApplyPresetResponse struct {
+	Preset             string `json:"preset"`
+	WorkTimeDuration   int    `json:"workTimeDuration"`
+	ShortBreakDuration int    `json:"shortBreakDuration"`
+	LongBreakDuration  int    `json:"longBreakDuration"`
+}
+
+// ApplyPreset sets the work and break durations from a named preset
+func (h *ClockHandler) ApplyPreset(w http.ResponseWriter, r *http.Request) {
+	var req ApplyPresetRequest
+	if err := httpjson.Decode(w, r, &req, httpjson.DefaultMaxBodyBytes); err != nil {
+		http.Error(w, err.Error(), httpjson.StatusCode(err))
+		return
+	}
+
+	if err := h.clockRunner.ApplyPreset(req.Preset); err != nil {
+		if errors.Is(err, clock.ErrUnknownPreset) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+
+	workDuration, shortBreakDuration, longBreakDuration := h.clockRunner.GetDurations()
+	response := ApplyPresetResponse{
+		Preset:             req.Preset,
+		WorkTimeDuration:   workDuration,
+		ShortBreakDuration: shortBreakDuration,
+		LongBreakDuration:  longBreakDuration,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetCompletionEvents returns the buffered session completions after the
+// optional ?since= RFC3339 timestamp, oldest first, so a reconnecting client
+// can catch up on what it missed
+func (h *ClockHandler) GetCompletionEvents(w http.ResponseWriter, r *http.Request) {
+	if h.clockRunner.GetRedisPersistence() == nil {
+		http.Error(w, "completion events require Redis", http.StatusServiceUnavailable)
+		return
+	}
+
+	var since time.Time
+	if value := r.URL.Query().Get("since"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "since must be an RFC3339 timestamp", http.StatusBadRequest)
+			return
+		}
+		since = parsed
+	}
+
+	events, err := h.clockRunner.GetCompletionEvents(since)
+	if err != nil {
+		log.Printf("Failed to load completion events: %v", err)
+		http.Error(w, "failed to load completion events", http.StatusInternalServerError)
+		return
+	}
+	loc := h.clockRunner.GetLocation()
+	response := make([]*ClockCompletionResponse, len(events))
+	for i, event := range events {
+		response[i] = newClockCompletionResponse(event, loc)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// ScheduleTemplateRequest is the body of POST /system/templates; durations are
+// in minutes and scheduling uses the SCHEDULING format without per-session durations
+type ScheduleTemplateRequest struct {
+	Name               string `json:"name"`
+	Scheduling         string `json:"scheduling"`
+	WorkTimeDuration   int    `json:"workTimeDuration"`
+	ShortBreakDuration int    `json:"shortBreakDuration"`
+	LongBreakDuration  int    `json:"longBreakDuration"`
+}
+
+func newScheduleTemplateResponse(template clock.ScheduleTemplate) ScheduleTemplateRequest {
+	return ScheduleTemplateRequest{
+		Name:               template.Name,
+		Scheduling:         clock.FormatScheduling(template.Schedule),
+		WorkTimeDuration:   template.WorkTime,
+		ShortBreakDuration: template.ShortBreakTime,
+		LongBreakDuration:  template.LongBreakTime,
+	}
+}
+
+// GetTemplates lists the saved schedule templates sorted by name
+func (h *ClockHandler) GetTemplates(w http.ResponseWriter, r *http.Request) {
+	if h.clockRunner.GetRedisPersistence() == nil {
+		http.Error(w, "schedule templates require Redis", http.StatusServiceUnavailable)
+		return
+	}
+
+	templates, err := h.clockRunner.ListTemplates()
+	if err != nil {
+		log.Printf("Failed to list schedule templates: %v", err)
+		http.Error(w, "failed to load templates", http.StatusInternalServerError)
+		return
+	}
+	response := make([]ScheduleTemplateRequest, len(templates))
+	for i, template := range templates {
+		response[i] = newScheduleTemplateResponse(template)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// SaveTemplate validates and stores a named schedule template, replacing any
+// template of the same name
+func (h *ClockHandler) SaveTemplate(w http.ResponseWriter, r *http.Request) {
+	if h.clockRunner.GetRedisPersistence() == nil {
+		http.Error(w, "schedule templates require Redis", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req ScheduleTemplateRequest
+	if err := httpjson.Decode(w, r, &req, httpjson.DefaultMaxBodyBytes); err != nil {
+		http.Error(w, err.Error(), httpjson.StatusCode(err))
+		return
+	}
+
+	specs, err := clock.ParseScheduling(req.Scheduling)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, spec := range specs {
+		if spec.Duration != 0 {
+			http.Error(w, "templates do not support per-session durations", http.StatusBadRequest)
+			return
+		}
+	}
+
+	schedule := clock.ScheduleStates(specs)
+	err = h.clockRunner.SaveTemplate(req.Name, schedule,
+		time.Duration(req.WorkTimeDuration)*time.Minute,
+		time.Duration(req.ShortBreakDuration)*time.Minute,
+		time.Duration(req.LongBreakDuration)*time.Minute)
+	if err != nil {
+		if errors.Is(err, clock.ErrInvalidTemplate) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Failed to save schedule template: %v", err)
+		http.Error(w, "failed to save template", http.StatusInternalServerError)
+		return
+	}
+
+	response := newScheduleTemplateResponse(clock.ScheduleTemplate{
+		Name:           req.Name,
+		Schedule:       schedule,
+		WorkTime:       req.WorkTimeDuration,
+		ShortBreakTime: req.ShortBreakDuration,
+		LongBreakTime:  req.LongBreakDuration,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// ApplyTemplate sets the durations and schedule from a saved template while the
+// clock is idle
+func (h *ClockHandler) ApplyTemplate(w http.ResponseWriter, r *http.Request) {
+	if h.clockRunner.GetRedisPersistence() == nil {
+		http.Error(w, "schedule templates require Redis", http.StatusServiceUnavailable)
+		return
+	}
+
+	template, err := h.clockRunner.ApplyTemplate(chi.URLParam(r, "name"))
+	if err != nil {
+		switch {
+		case errors.Is(err, clock.ErrTemplateNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, clock.ErrClockNotIdle):
+			http.Error(w, "stop the clock before applying a template", http.StatusConflict)
+		case errors.Is(err, clock.ErrInvalidTemplate):
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		default:
+			log.Printf("Failed to apply schedule template: %v", err)
+			http.Error(w, "failed to apply template", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Pomodoro started"))
+	json.NewEncoder(w).Encode(newScheduleTemplateResponse(*template))
 }
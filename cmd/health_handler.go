@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// readinessTimeout bounds each dependency check so a hung dependency cannot
+// hold the probe past the kubelet's own timeout
+const readinessTimeout = 2 * time.Second
+
+// readinessCheck reports whether one dependency is reachable
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+func NewHealthHandler(checks []readinessCheck) *HealthHandler {
+	return &HealthHandler{checks: checks}
+}
+
+type HealthHandler struct {
+	checks []readinessCheck
+}
+
+// HealthResponse represents the liveness and readiness response format. Checks
+// maps each dependency to "ok" or the reason it is down.
+type HealthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+	Down   []string          `json:"down,omitempty"`
+}
+
+// Liveness reports that the process is up and serving requests
+func (h *HealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(HealthResponse{Status: "ok"})
+}
+
+// Readiness pings every dependency and returns 503 listing the ones that are down
+func (h *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	response := HealthResponse{Status: "ok", Checks: make(map[string]string)}
+	for _, c := range h.checks {
+		if err := c.check(ctx); err != nil {
+			response.Checks[c.name] = err.Error()
+			response.Down = append(response.Down, c.name)
+			continue
+		}
+		response.Checks[c.name] = "ok"
+	}
+	sort.Strings(response.Down)
+
+	status := http.StatusOK
+	if len(response.Down) > 0 {
+		response.Status = "unavailable"
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// readinessChecks builds the dependency checks for /readyz. The clock falls back
+// to in-memory state when Redis is unavailable at startup; that is reported as
+// down so traffic is not routed to an instance that cannot persist.
+func (app *Config) readinessChecks() []readinessCheck {
+	return []readinessCheck{
+		{name: "postgres", check: func(ctx context.Context) error {
+			if app.DB == nil {
+				return errors.New("not connected")
+			}
+			return app.DB.Ping(ctx)
+		}},
+		{name: "redis", check: func(ctx context.Context) error {
+			redisPersistence := app.ClockRunner.GetRedisPersistence()
+			if redisPersistence == nil {
+				return errors.New("not connected")
+			}
+			return redisPersistence.Ping(ctx)
+		}},
+	}
+}
@@ -1,14 +1,22 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"pomodoroService/internal/auth"
 	"pomodoroService/internal/clock"
+	"pomodoroService/internal/migrate"
+	"strconv"
+	"time"
+	_ "time/tzdata" // embed zone data so TZ works in minimal images
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 )
 
 // var webPort = "8080"             // os.Getenv("WEB_PORT") or default
@@ -23,25 +31,58 @@ type Config struct {
 	PomodoroSetting PomodoroSetting
 	ClockRunner     *clock.ClockRunner
 	AuthRepo        auth.AuthRepository
+	SettingsRepo    auth.UserSettingsRepository
+	Verifications   *auth.VerificationStore
+	DB              *pgxpool.Pool
+	// MetricsToken protects GET /metrics when set
+	MetricsToken string
+	// AllowedOrigins lists the CORS origins allowed to send credentials; empty allows any origin without credentials
+	AllowedOrigins []string
+	// StrictDurations rejects user settings with inverted durations instead of warning
+	StrictDurations bool
 }
 
 func main() {
+	setupLogging()
+	if err := auth.LoadJWTKeys(); err != nil {
+		log.Fatalf("failed to load JWT keys: %v", err)
+	}
+	if err := auth.LoadBcryptCost(); err != nil {
+		log.Fatalf("failed to configure password hashing: %v", err)
+	}
+	if err := auth.LoadUsernameNormalization(); err != nil {
+		log.Fatalf("failed to configure username normalization: %v", err)
+	}
+	if err := auth.LoadDefaultRole(); err != nil {
+		log.Fatalf("failed to configure the default user role: %v", err)
+	}
+	if role := auth.GetDefaultRole(); role != auth.DefaultUserRole {
+		log.Printf("Warning: DEFAULT_USER_ROLE is %s, so anyone who registers gets that role", role)
+	}
+
 	// Initialize clock runner with Redis persistence
-	clockRunner, err := clock.NewClockRunnerWithRedis(redisAddr)
+	clockRunner, err := clock.NewClockRunnerWithRedisOptions(redisOptions(), redisRunnerOptions()...)
 	if err != nil {
 		log.Printf("Warning: failed to initialize Redis persistence, falling back to in-memory: %v", err)
 		clockRunner = clock.NewClockRunner()
 	}
-	conn := connectToDB()
-	if conn == nil {
-		log.Panic("Can't connect to Postgres")
+	conn, err := connectToDB(dbConnectSettings())
+	if err != nil {
+		log.Panicf("Can't connect to Postgres: %v", err)
+	}
+	if err := migrate.Run(context.Background(), conn); err != nil {
+		log.Panicf("Can't migrate the database: %v", err)
 	}
 
 	app := Config{
 		PomodoroSetting: defaultPomodoroSetting(),
 		ClockRunner:     clockRunner,
+		MetricsToken:    os.Getenv("METRICS_TOKEN"),
+		AllowedOrigins:  parseAllowedOrigins(os.Getenv("CORS_ALLOWED_ORIGINS")),
+		StrictDurations: envBool("STRICT_DURATIONS"),
 	}
 	app.setupRepo(conn)
+	app.setupVerifications()
 	app.init()
 	log.Printf("Starting pomodoro service on port %s\n", webPort)
 
@@ -56,6 +97,177 @@ func main() {
 	}
 }
 
+// setupLogging switches to structured JSON logs when LOG_FORMAT=json.
+// The default handler keeps the human-readable log package output.
+func setupLogging() {
+	if os.Getenv("LOG_FORMAT") == "json" {
+		slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+	}
+}
+
+// redisOptions builds the Redis client options from the environment. REDIS_URL
+// (e.g. redis://:password@host:6379/2) takes precedence; otherwise REDIS_ADDR is
+// combined with the optional REDIS_PASSWORD and REDIS_DB. REDIS_TLS=true
+// connects over TLS.
+func redisOptions() *redis.Options {
+	var opts *redis.Options
+	if redisURL := os.Getenv("REDIS_URL"); redisURL != "" {
+		parsed, err := redis.ParseURL(redisURL)
+		if err != nil {
+			// The URL may contain the password, so it is not echoed back
+			log.Fatalf("failed to parse REDIS_URL: invalid URL")
+		}
+		opts = parsed
+	} else {
+		opts = &redis.Options{
+			Addr:     redisAddr,
+			Password: os.Getenv("REDIS_PASSWORD"),
+		}
+		if redisDB := os.Getenv("REDIS_DB"); redisDB != "" {
+			db, err := strconv.Atoi(redisDB)
+			if err != nil {
+				log.Fatalf("failed to parse REDIS_DB: %v", err)
+			}
+			opts.DB = db
+		}
+	}
+
+	// A rediss:// URL already enables TLS; REDIS_TLS turns it on for the rest
+	if envBool("REDIS_TLS") && opts.TLSConfig == nil {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	if opts.TLSConfig != nil && envBool("REDIS_TLS_INSECURE_SKIP_VERIFY") {
+		log.Printf("Warning: Redis TLS certificate verification is disabled")
+		opts.TLSConfig.InsecureSkipVerify = true
+	}
+
+	log.Printf("Redis: using %s db %d (password set: %t, tls: %t)",
+		opts.Addr, opts.DB, opts.Password != "", opts.TLSConfig != nil)
+	return opts
+}
+
+// redisRunnerOptions reads the optional Redis retry and health check settings:
+// REDIS_RETRY_ATTEMPTS, REDIS_RETRY_BACKOFF and REDIS_HEALTH_CHECK_INTERVAL
+// (durations such as "200ms" or "10s"), STATE_SAVE_WINDOW for coalescing state
+// saves, STATS_RETENTION_DAYS for how long daily statistics are kept,
+// COMPLETION_EVENTS_LIMIT and COMPLETION_EVENTS_TTL for the completion events
+// kept for reconnecting clients, and
+// RESUME_POLICY, RESUME_GRACE_PERIOD and DUPLICATE_RECORD_WINDOW for
+// how a session saved in Redis is resumed. Unset values keep the clock defaults.
+func redisRunnerOptions() []clock.ClockRunnerOption {
+	var opts []clock.ClockRunnerOption
+
+	attempts, backoff := clock.DefaultRedisRetryAttempts, clock.DefaultRedisRetryBackoff
+	if value := os.Getenv("REDIS_RETRY_ATTEMPTS"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			log.Fatalf("failed to parse REDIS_RETRY_ATTEMPTS: %v", err)
+		}
+		attempts = parsed
+	}
+	if value := os.Getenv("REDIS_RETRY_BACKOFF"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("failed to parse REDIS_RETRY_BACKOFF: %v", err)
+		}
+		backoff = parsed
+	}
+	opts = append(opts, clock.WithRedisRetry(attempts, backoff))
+
+	if value := os.Getenv("STATS_RETENTION_DAYS"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 1 {
+			log.Fatalf("invalid STATS_RETENTION_DAYS %q: must be a positive number of days", value)
+		}
+		opts = append(opts, clock.WithStatsRetention(time.Duration(days)*24*time.Hour))
+	}
+
+	eventLimit, eventTTL := clock.DefaultCompletionEventLimit, clock.DefaultCompletionEventTTL
+	if value := os.Getenv("COMPLETION_EVENTS_LIMIT"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			log.Fatalf("failed to parse COMPLETION_EVENTS_LIMIT: %v", err)
+		}
+		eventLimit = parsed
+	}
+	if value := os.Getenv("COMPLETION_EVENTS_TTL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("failed to parse COMPLETION_EVENTS_TTL: %v", err)
+		}
+		eventTTL = parsed
+	}
+	opts = append(opts, clock.WithCompletionEventBuffer(eventLimit, eventTTL))
+
+	if value := os.Getenv("REDIS_HEALTH_CHECK_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("failed to parse REDIS_HEALTH_CHECK_INTERVAL: %v", err)
+		}
+		opts = append(opts, clock.WithRedisHealthCheckInterval(interval))
+	}
+
+	policy, err := clock.ParseResumePolicy(os.Getenv("RESUME_POLICY"))
+	if err != nil {
+		log.Fatalf("failed to parse RESUME_POLICY: %v", err)
+	}
+	opts = append(opts, clock.WithResumePolicy(policy))
+
+	if value := os.Getenv("RESUME_GRACE_PERIOD"); value != "" {
+		grace, err := time.ParseDuration(value)
+		if err != nil || grace < 0 {
+			log.Fatalf("invalid RESUME_GRACE_PERIOD %q: must be a non-negative duration", value)
+		}
+		opts = append(opts, clock.WithResumeGracePeriod(grace))
+	}
+	// When unset, the strict resume saved with the behavior settings is kept
+	if os.Getenv("STRICT_RESUME") != "" {
+		opts = append(opts, clock.WithStrictResume(envBool("STRICT_RESUME")))
+	}
+
+	if value := os.Getenv("STATE_SAVE_WINDOW"); value != "" {
+		window, err := time.ParseDuration(value)
+		if err != nil || window < 0 {
+			log.Fatalf("invalid STATE_SAVE_WINDOW %q: must be a non-negative duration", value)
+		}
+		opts = append(opts, clock.WithStateSaveWindow(window))
+	}
+
+	if value := os.Getenv("DUPLICATE_RECORD_WINDOW"); value != "" {
+		window, err := time.ParseDuration(value)
+		if err != nil || window < 0 {
+			log.Fatalf("invalid DUPLICATE_RECORD_WINDOW %q: must be a non-negative duration", value)
+		}
+		opts = append(opts, clock.WithDuplicateRecordWindow(window))
+	}
+	return opts
+}
+
+// envBool reports whether the named environment variable is set to a true
+// value such as "true" or "1". Unparsable values are fatal.
+func envBool(name string) bool {
+	value := os.Getenv(name)
+	if value == "" {
+		return false
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Fatalf("failed to parse %s: %v", name, err)
+	}
+	return enabled
+}
+
 func (app *Config) setupRepo(conn *pgxpool.Pool) {
+	app.DB = conn
 	app.AuthRepo = auth.NewAuthRepository(conn)
+	app.SettingsRepo = auth.NewUserSettingsRepository(conn)
+}
+
+func (app *Config) setupVerifications() {
+	verifications, err := auth.NewVerificationStoreWithOptions(redisOptions())
+	if err != nil {
+		log.Printf("Warning: email verification disabled: %v", err)
+		return
+	}
+	app.Verifications = verifications
 }
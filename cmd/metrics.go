@@ -0,0 +1,112 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"pomodoroService/internal/clock"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metricsStates are the values of the state label on pomodoro_current_state
+var metricsStates = []clock.ClockState{
+	clock.StateIdle,
+	clock.StateWorking,
+	clock.StateShortBreak,
+	clock.StateLongBreak,
+	clock.StateCustomBreak,
+	clock.StatePaused,
+	clock.StateWaiting,
+}
+
+// clockCollector exports the statistics and live clock state at scrape time,
+// so the values always match what the API reports
+type clockCollector struct {
+	clockRunner *clock.ClockRunner
+
+	sessionsCompleted *prometheus.Desc
+	pauses            *prometheus.Desc
+	currentState      *prometheus.Desc
+	timeRemaining     *prometheus.Desc
+}
+
+func newClockCollector(clockRunner *clock.ClockRunner) *clockCollector {
+	return &clockCollector{
+		clockRunner: clockRunner,
+		sessionsCompleted: prometheus.NewDesc("pomodoro_sessions_completed_total",
+			"Completed sessions by type (W, SB, LB, CB).", []string{"type"}, nil),
+		pauses: prometheus.NewDesc("pomodoro_pauses_total",
+			"Pauses of a running session since the process started.", nil, nil),
+		currentState: prometheus.NewDesc("pomodoro_current_state",
+			"Current clock state; 1 for the active state and 0 for the others.", []string{"state"}, nil),
+		timeRemaining: prometheus.NewDesc("pomodoro_time_remaining_seconds",
+			"Time remaining in the current session.", nil, nil),
+	}
+}
+
+func (c *clockCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.sessionsCompleted
+	ch <- c.pauses
+	ch <- c.currentState
+	ch <- c.timeRemaining
+}
+
+func (c *clockCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.clockRunner.GetStatisticsSnapshot()
+	completed := map[clock.ClockState]int{
+		clock.StateWorking:     stats.WorkSessions,
+		clock.StateShortBreak:  stats.ShortBreaks,
+		clock.StateLongBreak:   stats.LongBreaks,
+		clock.StateCustomBreak: stats.CustomBreaks,
+	}
+	for state, count := range completed {
+		ch <- prometheus.MustNewConstMetric(c.sessionsCompleted, prometheus.CounterValue, float64(count), string(state))
+	}
+	ch <- prometheus.MustNewConstMetric(c.pauses, prometheus.CounterValue, float64(stats.Pauses))
+
+	snapshot := c.clockRunner.Snapshot()
+	for _, state := range metricsStates {
+		value := 0.0
+		if state == snapshot.State {
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.currentState, prometheus.GaugeValue, value, string(state))
+	}
+	ch <- prometheus.MustNewConstMetric(c.timeRemaining, prometheus.GaugeValue, snapshot.TimeRemaining.Seconds())
+}
+
+func NewMetricsHandler(clockRunner *clock.ClockRunner, token string) *MetricsHandler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		newClockCollector(clockRunner),
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return &MetricsHandler{
+		handler: promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
+		token:   token,
+	}
+}
+
+type MetricsHandler struct {
+	handler http.Handler
+	// token, when set, must be sent as "Authorization: Bearer <token>"
+	token string
+}
+
+// ServeMetrics handles GET /metrics in the Prometheus text format
+func (h *MetricsHandler) ServeMetrics(w http.ResponseWriter, r *http.Request) {
+	if h.token != "" {
+		provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(h.token)) != 1 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	h.handler.ServeHTTP(w, r)
+}
@@ -13,36 +13,102 @@ import (
 func (app *Config) routes() http.Handler {
 	mux := chi.NewRouter()
 
-	mux.Use(cors.Handler(cors.Options{
-		// AllowedOrigins: []string{"https://*", "http://*"},
-		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: true,
-		MaxAge:           300,
-	}))
+	mux.Use(middleware.RequestID)
+	mux.Use(accessLog)
+	mux.Use(cors.Handler(corsOptions(app.AllowedOrigins)))
 
 	mux.Use(middleware.Heartbeat("/ping"))
 
+	// Kubernetes probes: liveness only checks the process, readiness its dependencies
+	healthHandler := NewHealthHandler(app.readinessChecks())
+	mux.Get("/healthz", healthHandler.Liveness)
+	mux.Get("/readyz", healthHandler.Readiness)
+
 	clockHandler := NewClockHandler(app.ClockRunner)
-	authHandler := NewAuthHandler(app.AuthRepo)
+	statsHandler := NewStatsHandler(app.ClockRunner)
+	streamHandler := NewClockStreamHandler(app.ClockRunner)
+	authHandler := NewAuthHandler(app.AuthRepo, app.Verifications)
+	settingsHandler := NewSettingsHandler(app.SettingsRepo, app.ClockRunner, app.StrictDurations)
+	metricsHandler := NewMetricsHandler(app.ClockRunner, app.MetricsToken)
+
+	// Prometheus scrape endpoint; protected by METRICS_TOKEN instead of user roles
+	mux.Get("/metrics", metricsHandler.ServeMetrics)
 
 	// Clock routes with role-based access control
 	// Basic users (USER role) can view system state
 	mux.With(auth.RequireAnyUserRole(app.AuthRepo)).Get("/system/state", clockHandler.GetSystemState)
+	mux.With(auth.RequireAnyUserRole(app.AuthRepo)).Get("/system/schedule", clockHandler.GetSchedule)
+	mux.With(auth.RequireAnyUserRole(app.AuthRepo)).Get("/system/schedule/formatted", clockHandler.GetFormattedSchedule)
+	mux.With(auth.RequireAnyUserRole(app.AuthRepo)).Get("/system/remaining", clockHandler.GetRemainingSessions)
+	mux.With(auth.RequireAnyUserRole(app.AuthRepo)).Get("/system/session", clockHandler.GetSessionInfo)
+	mux.With(auth.RequireAnyUserRole(app.AuthRepo)).Get("/system/presets", clockHandler.GetPresets)
+	mux.With(auth.RequireAnyUserRole(app.AuthRepo)).Get("/time", clockHandler.GetServerTime)
+	mux.With(auth.RequireAnyUserRole(app.AuthRepo)).Get("/system/templates", clockHandler.GetTemplates)
+	mux.With(auth.RequireAnyUserRole(app.AuthRepo)).Get("/system/behavior", clockHandler.GetBehavior)
+	mux.With(auth.RequireAnyUserRole(app.AuthRepo)).Get("/system/events", clockHandler.GetCompletionEvents)
+	// Any user can watch the stream; control messages on it are limited to admins
+	mux.With(auth.RequireAnyUserRole(app.AuthRepo)).Get("/system/ws", streamHandler.StreamClock)
 	// Only admins can start/modify the pomodoro system
+	mux.With(auth.RequireAdminRole(app.AuthRepo)).Get("/system/state/raw", clockHandler.GetRawSystemState)
 	mux.With(auth.RequireAdminRole(app.AuthRepo)).Post("/system/start", clockHandler.StartNewPomodoro)
+	mux.With(auth.RequireAdminRole(app.AuthRepo)).Post("/system/repeat", clockHandler.RepeatLastSession)
+	mux.With(auth.RequireAdminRole(app.AuthRepo)).Post("/system/back", clockHandler.BackToPreviousSession)
+	mux.With(auth.RequireAdminRole(app.AuthRepo)).Post("/system/settings/preset", clockHandler.ApplyPreset)
+	mux.With(auth.RequireAdminRole(app.AuthRepo)).Put("/system/behavior", clockHandler.UpdateBehavior)
+	mux.With(auth.RequireAdminRole(app.AuthRepo)).Post("/system/templates", clockHandler.SaveTemplate)
+	mux.With(auth.RequireAdminRole(app.AuthRepo)).Post("/system/templates/{name}/apply", clockHandler.ApplyTemplate)
+	mux.With(auth.RequireAdminRole(app.AuthRepo)).Post("/system/session/tag", clockHandler.SetSessionTag)
+	mux.With(auth.RequireAdminRole(app.AuthRepo)).Patch("/system/time", clockHandler.AdjustTime)
+
+	// Statistics routes
+	mux.With(auth.RequireAnyUserRole(app.AuthRepo)).Get("/stats/summary", statsHandler.GetStatisticsSummary)
+	mux.With(auth.RequireAnyUserRole(app.AuthRepo)).Get("/stats/today", statsHandler.GetTodayProgress)
+	mux.With(auth.RequireAnyUserRole(app.AuthRepo)).Get("/stats/range", statsHandler.GetStatisticsForRange)
+	mux.With(auth.RequireAnyUserRole(app.AuthRepo)).Get("/stats/distribution", statsHandler.GetDurationDistribution)
+	mux.With(auth.RequireAnyUserRole(app.AuthRepo)).Get("/stats/sessions", statsHandler.GetSessionsByState)
+	mux.With(auth.RequireAnyUserRole(app.AuthRepo)).Get("/stats/export", statsHandler.ExportStatistics)
+	mux.With(auth.RequireAdminRole(app.AuthRepo)).Post("/stats/reset", statsHandler.ResetStatistics)
+	mux.With(auth.RequireAdminRole(app.AuthRepo)).Post("/stats/import", statsHandler.ImportSessions)
 
 	// Authentication routes
 	mux.Post("/auth/register", authHandler.RegisterUser)
 	mux.Post("/auth/login", authHandler.LoginUser)
+	mux.Get("/auth/roles", authHandler.GetRoles)
+	mux.Get("/auth/verify", authHandler.VerifyEmail)
 
-	// Admin registration (development/testing only)
-	mux.Post("/auth/register-admin", authHandler.RegisterAdminUser)
+	// Admin user provisioning
+	mux.With(auth.RequireAdminRole(app.AuthRepo)).Post("/admin/users", authHandler.CreateUserWithRole)
+	mux.With(auth.RequireAdminRole(app.AuthRepo)).Put("/admin/users/{username}/role", authHandler.UpdateUserRole)
+	mux.With(auth.RequireAdminRole(app.AuthRepo)).Delete("/admin/users/{username}", authHandler.DeleteUser)
+	mux.With(auth.RequireAdminRole(app.AuthRepo)).Post("/auth/register-admin", authHandler.RegisterAdminUser)
 
 	// Protected routes (require JWT token)
 	mux.With(auth.RequireAnyUserRole(app.AuthRepo)).Get("/auth/profile", authHandler.GetProfile)
+	mux.With(auth.RequireAnyUserRole(app.AuthRepo)).Get("/auth/whoami", authHandler.WhoAmI)
+	mux.With(auth.RequireAnyUserRole(app.AuthRepo)).Delete("/auth/account", authHandler.DeleteAccount)
+
+	// Per-user settings; each user reads and writes only their own
+	mux.With(auth.RequireAnyUserRole(app.AuthRepo)).Get("/user/settings", settingsHandler.GetUserSettings)
+	mux.With(auth.RequireAnyUserRole(app.AuthRepo)).Put("/user/settings", settingsHandler.SaveUserSettings)
 
 	return mux
 }
+
+// corsOptions allows the given origins with credentials. Without an allowlist
+// any origin is accepted, as is handy in development, but credentials are
+// disabled since browsers reject them with a wildcard origin.
+func corsOptions(allowedOrigins []string) cors.Options {
+	options := cors.Options{
+		AllowedOrigins:   allowedOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		ExposedHeaders:   []string{"Link", requestIDHeader},
+		AllowCredentials: true,
+		MaxAge:           300,
+	}
+	if len(allowedOrigins) == 0 {
+		options.AllowedOrigins = []string{"*"}
+		options.AllowCredentials = false
+	}
+	return options
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"pomodoroService/internal/auth"
+	"pomodoroService/internal/clock"
+	"pomodoroService/internal/httpjson"
+	"time"
+)
+
+func NewSettingsHandler(settingsRepo auth.UserSettingsRepository, clockRunner *clock.ClockRunner, strictDurations bool) *SettingsHandler {
+	return &SettingsHandler{settingsRepo: settingsRepo, clockRunner: clockRunner, strictDurations: strictDurations}
+}
+
+type SettingsHandler struct {
+	settingsRepo auth.UserSettingsRepository
+	// clockRunner supplies the global settings for users who saved none
+	clockRunner *clock.ClockRunner
+	// strictDurations rejects inverted durations, such as a long break shorter
+	// than a short break, instead of saving them with a warning
+	strictDurations bool
+}
+
+// UserSettingsRequest is the body of PUT /user/settings; durations are in minutes
+type UserSettingsRequest struct {
+	WorkTimeDuration   int    `json:"workTimeDuration"`
+	ShortBreakDuration int    `json:"shortBreakDuration"`
+	LongBreakDuration  int    `json:"longBreakDuration"`
+	Scheduling         string `json:"scheduling"`
+}
+
+// UserSettingsResponse reports a user's settings; IsDefault is set when the user
+// has saved none and the global settings are returned instead
+type UserSettingsResponse struct {
+	UserSettingsRequest
+	IsDefault bool   `json:"isDefault"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
+	// Warnings lists likely mistakes in settings that were saved anyway
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+func newUserSettingsResponse(settings *auth.UserSettings) UserSettingsResponse {
+	return UserSettingsResponse{
+		UserSettingsRequest: UserSettingsRequest{
+			WorkTimeDuration:   settings.WorkTimeDuration,
+			ShortBreakDuration: settings.ShortBreakDuration,
+			LongBreakDuration:  settings.LongBreakDuration,
+			Scheduling:         settings.Scheduling,
+		},
+		UpdatedAt: settings.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// GetUserSettings returns the caller's saved settings, or the global settings
+// when they have not saved any
+func (h *SettingsHandler) GetUserSettings(w http.ResponseWriter, r *http.Request) {
+	userID, _, _, ok := auth.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var response UserSettingsResponse
+	settings, err := h.settingsRepo.GetUserSettings(userID)
+	switch {
+	case err == nil:
+		response = newUserSettingsResponse(settings)
+	case errors.Is(err, auth.ErrUserSettingsNotFound):
+		workDuration, shortBreakDuration, longBreakDuration := h.clockRunner.GetDurations()
+		response = UserSettingsResponse{
+			UserSettingsRequest: UserSettingsRequest{
+				WorkTimeDuration:   workDuration,
+				ShortBreakDuration: shortBreakDuration,
+				LongBreakDuration:  longBreakDuration,
+				Scheduling:         clock.FormatSchedulingSpecs(h.clockRunner.GetScheduleSpecs()),
+			},
+			IsDefault: true,
+		}
+	default:
+		log.Printf("Failed to load user settings: %v", err)
+		http.Error(w, "failed to load settings", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// SaveUserSettings validates and stores the caller's settings. Durations must be
+// between 1 minute and 4 hours and the scheduling string uses the SCHEDULING format.
+// Work shorter than a short break, or a long break shorter than a short break,
+// is rejected in strict mode and otherwise saved with a warning.
+func (h *SettingsHandler) SaveUserSettings(w http.ResponseWriter, r *http.Request) {
+	userID, _, _, ok := auth.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req UserSettingsRequest
+	if err := httpjson.Decode(w, r, &req, httpjson.DefaultMaxBodyBytes); err != nil {
+		http.Error(w, err.Error(), httpjson.StatusCode(err))
+		return
+	}
+
+	utils := clock.NewClockUtils()
+	for _, minutes := range []int{req.WorkTimeDuration, req.ShortBreakDuration, req.LongBreakDuration} {
+		if !utils.IsValidDuration(time.Duration(minutes) * time.Minute) {
+			http.Error(w, "durations must be between 1 and 240 minutes", http.StatusBadRequest)
+			return
+		}
+	}
+	var warnings []string
+	err := utils.ValidateDurations(
+		time.Duration(req.WorkTimeDuration)*time.Minute,
+		time.Duration(req.ShortBreakDuration)*time.Minute,
+		time.Duration(req.LongBreakDuration)*time.Minute)
+	if err != nil {
+		if h.strictDurations {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		warnings = append(warnings, err.Error())
+	}
+	specs, err := clock.ParseScheduling(req.Scheduling)
+	if err == nil {
+		err = utils.ValidateSchedule(clock.ScheduleStates(specs))
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	settings := &auth.UserSettings{
+		WorkTimeDuration:   req.WorkTimeDuration,
+		ShortBreakDuration: req.ShortBreakDuration,
+		LongBreakDuration:  req.LongBreakDuration,
+		// Store the normalised form, without whitespace
+		Scheduling: clock.FormatSchedulingSpecs(specs),
+	}
+	if err := h.settingsRepo.SaveUserSettings(userID, settings); err != nil {
+		if errors.Is(err, auth.ErrUserNotFound) {
+			http.Error(w, "user not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("Failed to save user settings: %v", err)
+		http.Error(w, "failed to save settings", http.StatusInternalServerError)
+		return
+	}
+
+	response := newUserSettingsResponse(settings)
+	response.Warnings = warnings
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
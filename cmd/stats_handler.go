@@ -0,0 +1,375 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"pomodoroService/internal/clock"
+	"pomodoroService/internal/httpjson"
+	"strconv"
+	"time"
+)
+
+func NewStatsHandler(clockRunner *clock.ClockRunner) *StatsHandler {
+	return &StatsHandler{clockRunner: clockRunner}
+}
+
+type StatsHandler struct {
+	clockRunner *clock.ClockRunner
+}
+
+// StatisticsResponse represents the session statistics response format
+type StatisticsResponse struct {
+	WorkSessions int   `json:"workSessions"`
+	ShortBreaks  int   `json:"shortBreaks"`
+	LongBreaks   int   `json:"longBreaks"`
+	WorkTime     int64 `json:"workTime"`  // in seconds
+	BreakTime    int64 `json:"breakTime"` // in seconds
+	TotalTime    int64 `json:"totalTime"` // in seconds
+	// Custom breaks are reported separately and not included in the break totals
+	CustomBreaks    int   `json:"customBreaks"`
+	CustomBreakTime int64 `json:"customBreakTime"` // in seconds
+}
+
+func newStatisticsResponse(snapshot clock.StatisticsSnapshot) StatisticsResponse {
+	return StatisticsResponse{
+		WorkSessions:    snapshot.WorkSessions,
+		ShortBreaks:     snapshot.ShortBreaks,
+		LongBreaks:      snapshot.LongBreaks,
+		WorkTime:        int64(snapshot.WorkTime.Seconds()),
+		BreakTime:       int64(snapshot.BreakTime.Seconds()),
+		TotalTime:       int64(snapshot.TotalTime.Seconds()),
+		CustomBreaks:    snapshot.CustomBreaks,
+		CustomBreakTime: int64(snapshot.CustomBreakTime.Seconds()),
+	}
+}
+
+// RangeStatisticsResponse is the statistics of the sessions completed in [from, to)
+type RangeStatisticsResponse struct {
+	From string `json:"from"` // RFC3339 in the clock's timezone
+	To   string `json:"to"`
+	StatisticsResponse
+}
+
+// parseRangeTime parses an RFC3339 timestamp, or a date such as 2025-01-15
+// meaning the start of that day in loc
+func parseRangeTime(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation(time.DateOnly, value, loc)
+}
+
+// GetStatisticsForRange returns the statistics of the sessions completed from
+// ?from= up to, but not including, ?to=. Both are RFC3339 timestamps or dates.
+func (h *StatsHandler) GetStatisticsForRange(w http.ResponseWriter, r *http.Request) {
+	loc := h.clockRunner.GetLocation()
+	from, err := parseRangeTime(r.URL.Query().Get("from"), loc)
+	if err != nil {
+		http.Error(w, "from must be an RFC3339 timestamp or a YYYY-MM-DD date", http.StatusBadRequest)
+		return
+	}
+	to, err := parseRangeTime(r.URL.Query().Get("to"), loc)
+	if err != nil {
+		http.Error(w, "to must be an RFC3339 timestamp or a YYYY-MM-DD date", http.StatusBadRequest)
+		return
+	}
+
+	snapshot, err := h.clockRunner.GetStatsForRange(from, to)
+	if err != nil {
+		if errors.Is(err, clock.ErrInvalidStatsRange) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	response := RangeStatisticsResponse{
+		From:               from.In(loc).Format(time.RFC3339),
+		To:                 to.In(loc).Format(time.RFC3339),
+		StatisticsResponse: newStatisticsResponse(snapshot),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// StatisticsSummaryResponse is a display-oriented summary of the statistics
+type StatisticsSummaryResponse struct {
+	AverageSessionDuration      int64  `json:"averageSessionDuration"` // in seconds
+	AverageSessionDurationLabel string `json:"averageSessionDurationLabel"`
+	ProductivityScore           int    `json:"productivityScore"` // percentage of sessions that were work
+	// PausesByReason counts the pauses given each reason since the server started
+	PausesByReason map[string]int `json:"pausesByReason"`
+}
+
+// GetStatisticsSummary returns the average session duration and the
+// productivity score, both 0 until a session completes, and the pause reasons
+func (h *StatsHandler) GetStatisticsSummary(w http.ResponseWriter, r *http.Request) {
+	average := h.clockRunner.GetAverageSessionDuration()
+	response := StatisticsSummaryResponse{
+		AverageSessionDuration:      int64(average.Seconds()),
+		AverageSessionDurationLabel: clock.NewTimeFormatter().FormatDurationLong(average),
+		ProductivityScore:           int(math.Round(h.clockRunner.GetProductivityScore())),
+		PausesByReason:              h.clockRunner.GetStatisticsSnapshot().PausesByReason,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// DailyGoalResponse is the progress toward the daily work goal
+type DailyGoalResponse struct {
+	Completed int     `json:"completed"` // work sessions completed today
+	Goal      int     `json:"goal"`      // 0 when no goal is set
+	Percent   float64 `json:"percent"`
+	Reached   bool    `json:"reached"`
+}
+
+func newDailyGoalResponse(progress clock.DailyGoalProgress) DailyGoalResponse {
+	return DailyGoalResponse{
+		Completed: progress.Completed,
+		Goal:      progress.Goal,
+		Percent:   math.Round(progress.Percent*10) / 10,
+		Reached:   progress.Reached,
+	}
+}
+
+// GetTodayProgress returns the work sessions completed today against the daily
+// work goal; the day starts at the configured day boundary
+func (h *StatsHandler) GetTodayProgress(w http.ResponseWriter, r *http.Request) {
+	response := newDailyGoalResponse(h.clockRunner.GetDailyGoalProgress())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// DurationBucketResponse is the number of work sessions in one duration range
+type DurationBucketResponse struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// GetDurationDistribution returns how many completed work sessions fall in each
+// duration bucket, shortest first
+func (h *StatsHandler) GetDurationDistribution(w http.ResponseWriter, r *http.Request) {
+	buckets := h.clockRunner.GetDurationDistribution()
+	response := make([]DurationBucketResponse, len(buckets))
+	for i, bucket := range buckets {
+		response[i] = DurationBucketResponse{Label: bucket.Label, Count: bucket.Count}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// ResetStatistics clears all session statistics and returns the zeroed values.
+// With ?purge=true the persisted statistics in Redis are removed as well.
+func (h *StatsHandler) ResetStatistics(w http.ResponseWriter, r *http.Request) {
+	if err := h.clockRunner.ResetStatistics(); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	if r.URL.Query().Get("purge") == "true" {
+		redisPersistence := h.clockRunner.GetRedisPersistence()
+		if redisPersistence != nil {
+			if _, err := redisPersistence.DeleteSessionStatistics(); err != nil {
+				log.Printf("Failed to purge session statistics: %v", err)
+				http.Error(w, "failed to purge persisted statistics", http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newStatisticsResponse(h.clockRunner.GetStatisticsSnapshot()))
+}
+
+// ImportSessionRequest is one previously completed session in a POST /stats/import body
+type ImportSessionRequest struct {
+	State           string `json:"state"`
+	DurationSeconds int64  `json:"durationSeconds"`
+	CompletedAt     string `json:"completedAt"` // RFC3339
+}
+
+// ImportSessionsResponse reports how many sessions were imported and the
+// statistics that include them
+type ImportSessionsResponse struct {
+	Imported   int                `json:"imported"`
+	Statistics StatisticsResponse `json:"statistics"`
+}
+
+// importMaxBodyBytes limits POST /stats/import bodies, which may carry up to
+// clock.MaxImportSessions entries
+const importMaxBodyBytes = 4 << 20
+
+// ImportSessions seeds the statistics with sessions completed elsewhere, e.g.
+// in another app. The body is a JSON array of {state, durationSeconds,
+// completedAt}; if any entry is invalid nothing is imported.
+func (h *StatsHandler) ImportSessions(w http.ResponseWriter, r *http.Request) {
+	var req []ImportSessionRequest
+	if err := httpjson.Decode(w, r, &req, importMaxBodyBytes); err != nil {
+		http.Error(w, err.Error(), httpjson.StatusCode(err))
+		return
+	}
+
+	records := make([]clock.SessionRecord, len(req))
+	for i, entry := range req {
+		state, ok := clock.ClockStateMap[entry.State]
+		if !ok {
+			http.Error(w, fmt.Sprintf("session %d: unknown state %q", i, entry.State), http.StatusBadRequest)
+			return
+		}
+		completedAt, err := time.Parse(time.RFC3339, entry.CompletedAt)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("session %d: completedAt must be RFC3339", i), http.StatusBadRequest)
+			return
+		}
+		records[i] = clock.SessionRecord{
+			State:     state,
+			Duration:  time.Duration(entry.DurationSeconds) * time.Second,
+			Completed: completedAt,
+		}
+	}
+
+	if err := h.clockRunner.ImportSessions(records); err != nil {
+		if errors.Is(err, clock.ErrInvalidSessionRecord) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Failed to import sessions: %v", err)
+		http.Error(w, "failed to save imported sessions", http.StatusInternalServerError)
+		return
+	}
+
+	response := ImportSessionsResponse{
+		Imported:   len(records),
+		Statistics: newStatisticsResponse(h.clockRunner.GetStatisticsSnapshot()),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// SessionRecordResponse represents one completed session in an export or listing
+type SessionRecordResponse struct {
+	State       string `json:"state"`
+	Duration    int64  `json:"duration"`    // in seconds
+	CompletedAt string `json:"completedAt"` // RFC3339 in the clock's timezone
+	Tag         string `json:"tag"`
+}
+
+func newSessionRecordResponse(record clock.SessionRecord, loc *time.Location) SessionRecordResponse {
+	return SessionRecordResponse{
+		State:       string(record.State),
+		Duration:    int64(record.Duration.Seconds()),
+		CompletedAt: record.Completed.In(loc).Format(time.RFC3339),
+		Tag:         record.Tag,
+	}
+}
+
+// GetSessionsByState returns the completed sessions whose state matches the
+// ?state= token (W, SB, LB or CB), oldest first
+func (h *StatsHandler) GetSessionsByState(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("state")
+	state, ok := clock.ClockStateMap[token]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown state %q", token), http.StatusBadRequest)
+		return
+	}
+
+	loc := h.clockRunner.GetLocation()
+	sessions := h.clockRunner.GetSessionsByState(state)
+	response := make([]SessionRecordResponse, len(sessions))
+	for i, record := range sessions {
+		response[i] = newSessionRecordResponse(record, loc)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// ExportStatistics downloads the completed session history, oldest first, as
+// CSV (the default) or with ?format=json as a JSON array. Rows are written as
+// they are encoded rather than built up in memory. The history covers sessions
+// completed since the process started.
+func (h *StatsHandler) ExportStatistics(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" {
+		http.Error(w, "format must be csv or json", http.StatusBadRequest)
+		return
+	}
+
+	loc := h.clockRunner.GetLocation()
+	history := h.clockRunner.GetSessionHistory()
+	filename := fmt.Sprintf("pomodoro-sessions-%s.%s", time.Now().In(loc).Format("2006-01-02"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	var err error
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		err = writeSessionsCSV(w, history, loc)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		err = writeSessionsJSON(w, history, loc)
+	}
+	if err != nil {
+		// The status is already sent; the client sees a truncated download
+		log.Printf("Failed to export session history: %v", err)
+	}
+}
+
+func writeSessionsCSV(w io.Writer, history []clock.SessionRecord, loc *time.Location) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"state", "duration_seconds", "completed_at", "tag"}); err != nil {
+		return err
+	}
+	for _, record := range history {
+		row := newSessionRecordResponse(record, loc)
+		if err := cw.Write([]string{row.State, strconv.FormatInt(row.Duration, 10), row.CompletedAt, row.Tag}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeSessionsJSON(w io.Writer, history []clock.SessionRecord, loc *time.Location) error {
+	if _, err := w.Write([]byte("[")); err != nil {
+		return err
+	}
+	for i, record := range history {
+		if i > 0 {
+			if _, err := w.Write([]byte(",")); err != nil {
+				return err
+			}
+		}
+		data, err := json.Marshal(newSessionRecordResponse(record, loc))
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write([]byte("]\n"))
+	return err
+}
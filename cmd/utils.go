@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 	"pomodoroService/internal/clock"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -13,38 +15,73 @@ import (
 
 type PomodoroSetting struct {
 	// read from default env
-	WorkTimeDuration   int                `json:"workTimeDuration"`
-	ShortBreakDuration int                `json:"shortBreakDuration"`
-	LongBreakDuration  int                `json:"longBreakDuration"`
-	Scheduling         []clock.ClockState `json:"scheduling"`
+	WorkTimeDuration   int                 `json:"workTimeDuration"`
+	ShortBreakDuration int                 `json:"shortBreakDuration"`
+	LongBreakDuration  int                 `json:"longBreakDuration"`
+	Scheduling         []clock.SessionSpec `json:"scheduling"`
 }
 
+const (
+	// Classic pomodoro defaults used when the corresponding variables are unset
+	defaultWorkTimeDuration   = 25
+	defaultShortBreakDuration = 5
+	defaultLongBreakDuration  = 15
+	defaultScheduling         = "W-SB-W-SB-W-SB-W-LB"
+)
+
+// defaultPomodoroSetting reads the durations in minutes from WORK_TIME_DURATION,
+// SHORT_BREAK_DURATION and LONG_BREAK_DURATION and the schedule from SCHEDULING
+// (e.g. "W-SB-W-LB" or "W:50-SB:10-W:25-LB:20"), falling back to the classic
+// 25/5/15 defaults. An invalid value stops the server at startup.
 func defaultPomodoroSetting() PomodoroSetting {
-	workTimeDuration, _ := strconv.Atoi(os.Getenv("WORK_TIME_DURATION"))
-	shortBreakDuration, _ := strconv.Atoi(os.Getenv("SHORT_BREAK_DURATION"))
-	longBreakDuration, _ := strconv.Atoi(os.Getenv("LONG_BREAK_DURATION"))
 	schedulingString := os.Getenv("SCHEDULING")
-
-	// workTimeDuration := 25
-	// shortBreakDuration := 5
-	// longBreakDuration := 15
-	// workTimeDuration := 1
-	// shortBreakDuration := 1
-	// longBreakDuration := 1
-	// schedulingString := `W-SB-W-SB-W-LB`
+	if schedulingString == "" {
+		schedulingString = defaultScheduling
+	}
 	scheduling, err := clock.ParseScheduling(schedulingString)
 	if err != nil {
-		log.Fatalf("failed to parse scheduling: %v", err)
+		log.Fatalf("invalid SCHEDULING %q: %v", schedulingString, err)
 	}
 
 	return PomodoroSetting{
-		WorkTimeDuration:   workTimeDuration,
-		ShortBreakDuration: shortBreakDuration,
-		LongBreakDuration:  longBreakDuration,
+		WorkTimeDuration:   envMinutes("WORK_TIME_DURATION", defaultWorkTimeDuration),
+		ShortBreakDuration: envMinutes("SHORT_BREAK_DURATION", defaultShortBreakDuration),
+		LongBreakDuration:  envMinutes("LONG_BREAK_DURATION", defaultLongBreakDuration),
 		Scheduling:         scheduling,
 	}
 }
 
+// envMinutes reads a positive number of minutes from the named environment
+// variable, returning fallback when it is unset
+func envMinutes(name string, fallback int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	minutes, err := strconv.Atoi(value)
+	if err != nil || minutes <= 0 {
+		log.Fatalf("invalid %s %q: must be a positive number of minutes", name, value)
+	}
+	return minutes
+}
+
+// parseAllowedOrigins splits a comma-separated CORS_ALLOWED_ORIGINS value,
+// e.g. "https://app.example.com,http://localhost:3000". An empty value or "*"
+// returns nil, meaning any origin without credentials.
+func parseAllowedOrigins(value string) []string {
+	var origins []string
+	for _, origin := range strings.Split(value, ",") {
+		origin = strings.TrimSpace(origin)
+		if origin == "*" {
+			return nil
+		}
+		if origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
 func (app *Config) init() {
 	// utils.InitializeSecret()
 
@@ -53,17 +90,92 @@ func (app *Config) init() {
 	currentSession := app.ClockRunner.GetCurrentSession()
 	log.Printf("🔄 Server startup: loaded currentSession=%d from Redis", currentSession)
 
-	app.ClockRunner.SetDurations(
+	err := app.ClockRunner.SetDurations(
 		time.Duration(app.PomodoroSetting.WorkTimeDuration)*time.Minute,
 		time.Duration(app.PomodoroSetting.ShortBreakDuration)*time.Minute,
 		time.Duration(app.PomodoroSetting.LongBreakDuration)*time.Minute,
 	)
+	if err != nil {
+		log.Fatalf("failed to set durations: %v", err)
+	}
 
-	err := app.ClockRunner.SetSchedule(app.PomodoroSetting.Scheduling)
+	err = app.ClockRunner.SetScheduleSpecs(app.PomodoroSetting.Scheduling)
 	if err != nil {
 		log.Fatalf("failed to set schedule: %v", err)
 	}
 
+	// Timezone used for day boundaries and reported in the system state
+	if tz := os.Getenv("TZ"); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			log.Fatalf("failed to load timezone %q: %v", tz, err)
+		}
+		app.ClockRunner.SetLocation(loc)
+	}
+	log.Printf("Clock timezone: %s", app.ClockRunner.GetLocation())
+
+	// Hour at which a new statistics day begins (defaults to midnight)
+	if dayStartHour := os.Getenv("DAY_START_HOUR"); dayStartHour != "" {
+		hour, err := strconv.Atoi(dayStartHour)
+		if err != nil {
+			log.Fatalf("failed to parse DAY_START_HOUR: %v", err)
+		}
+		if err := app.ClockRunner.SetDayStart(time.Duration(hour) * time.Hour); err != nil {
+			log.Fatalf("failed to set day start: %v", err)
+		}
+	}
+
+	// Minutes a session may stay paused before the clock stops itself; when unset,
+	// the value persisted in Redis (disabled by default) is kept
+	if idleTimeout := os.Getenv("IDLE_TIMEOUT_DURATION"); idleTimeout != "" {
+		minutes, err := strconv.Atoi(idleTimeout)
+		if err != nil {
+			log.Fatalf("failed to parse IDLE_TIMEOUT_DURATION: %v", err)
+		}
+		if err := app.ClockRunner.SetIdleTimeout(time.Duration(minutes) * time.Minute); err != nil {
+			log.Fatalf("failed to set idle timeout: %v", err)
+		}
+	}
+
+	// Whether the next session starts as soon as one completes; when unset, the
+	// value persisted in Redis (enabled by default) is kept
+	if autoStartNext := os.Getenv("AUTO_START_NEXT"); autoStartNext != "" {
+		enabled, err := strconv.ParseBool(autoStartNext)
+		if err != nil {
+			log.Fatalf("failed to parse AUTO_START_NEXT: %v", err)
+		}
+		app.ClockRunner.SetAutoStartNext(enabled)
+	}
+
+	// How many work sessions to complete each day; when unset, the value
+	// persisted in Redis (no goal by default) is kept
+	if dailyWorkGoal := os.Getenv("DAILY_WORK_GOAL"); dailyWorkGoal != "" {
+		goal, err := strconv.Atoi(dailyWorkGoal)
+		if err != nil {
+			log.Fatalf("failed to parse DAILY_WORK_GOAL: %v", err)
+		}
+		if err := app.ClockRunner.SetDailyWorkGoal(goal); err != nil {
+			log.Fatalf("failed to set daily work goal: %v", err)
+		}
+	}
+
+	// How long after a session completes POST /system/repeat may run it again
+	if repeatWindow := os.Getenv("REPEAT_WINDOW"); repeatWindow != "" {
+		window, err := time.ParseDuration(repeatWindow)
+		if err != nil {
+			log.Fatalf("failed to parse REPEAT_WINDOW: %v", err)
+		}
+		if err := app.ClockRunner.SetRepeatWindow(window); err != nil {
+			log.Fatalf("failed to set repeat window: %v", err)
+		}
+	}
+
+	// Optional webhook notified whenever a session completes
+	if webhookURL := os.Getenv("COMPLETION_WEBHOOK_URL"); webhookURL != "" {
+		app.ClockRunner.SetCompletionWebhook(webhookURL)
+		log.Printf("Completion webhook enabled")
+	}
+
 	// After setting schedule, session gets reset to 0
 	log.Printf("📝 After SetSchedule, currentSession reset to: %d", app.ClockRunner.GetCurrentSession())
 
@@ -73,29 +185,58 @@ func (app *Config) init() {
 	log.Printf("✅ Restored session state from Redis: currentSession=%d", currentSession)
 }
 
-var counts = 0
+const (
+	// defaultDBConnectAttempts and defaultDBConnectBackoff apply when
+	// DB_CONNECT_ATTEMPTS and DB_CONNECT_BACKOFF are unset
+	defaultDBConnectAttempts = 11
+	defaultDBConnectBackoff  = 2 * time.Second
+	// maxDBConnectBackoff caps the doubling between attempts
+	maxDBConnectBackoff = 30 * time.Second
+)
 
-func connectToDB() *pgxpool.Pool {
+// dbConnectSettings reads the Postgres connection retry settings from
+// DB_CONNECT_ATTEMPTS and DB_CONNECT_BACKOFF (a duration such as "2s")
+func dbConnectSettings() (attempts int, backoff time.Duration) {
+	attempts, backoff = defaultDBConnectAttempts, defaultDBConnectBackoff
+	if value := os.Getenv("DB_CONNECT_ATTEMPTS"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			log.Fatalf("invalid DB_CONNECT_ATTEMPTS %q: must be a positive integer", value)
+		}
+		attempts = parsed
+	}
+	if value := os.Getenv("DB_CONNECT_BACKOFF"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			log.Fatalf("invalid DB_CONNECT_BACKOFF %q: must be a non-negative duration", value)
+		}
+		backoff = parsed
+	}
+	return attempts, backoff
+}
 
-	for {
-		// keep connecting to the database
-		connection, err := openDB(dsn)
-		if err != nil {
-			log.Printf("Postgres is not yet ready")
-			counts++
-		} else {
+// connectToDB keeps trying to connect to Postgres, doubling the wait after each
+// failed attempt, and returns the last error once attempts run out
+func connectToDB(attempts int, backoff time.Duration) (*pgxpool.Pool, error) {
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		var connection *pgxpool.Pool
+		connection, err = openDB(dsn)
+		if err == nil {
 			log.Printf("Connected to Postgres!")
-			return connection
+			return connection, nil
 		}
 
-		if counts > 10 {
-			log.Println(err)
-			return nil
+		log.Printf("Postgres is not yet ready (attempt %d/%d): %v", attempt, attempts, err)
+		if attempt == attempts {
+			break
 		}
 
-		log.Println("Backing off for two seconds...")
-		time.Sleep(2 * time.Second)
+		log.Printf("Backing off for %v...", backoff)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxDBConnectBackoff)
 	}
+	return nil, fmt.Errorf("giving up after %d attempts: %w", attempts, err)
 }
 
 func openDB(dsn string) (*pgxpool.Pool, error) {
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"pomodoroService/internal/auth"
+	"pomodoroService/internal/clock"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Keepalive and size limits for the clock WebSocket
+const (
+	wsWriteWait      = 10 * time.Second
+	wsPongWait       = 60 * time.Second
+	wsPingPeriod     = (wsPongWait * 9) / 10
+	wsMaxMessageSize = 512
+)
+
+func NewClockStreamHandler(clockRunner *clock.ClockRunner) *ClockStreamHandler {
+	return &ClockStreamHandler{
+		clockRunner: clockRunner,
+		upgrader: websocket.Upgrader{
+			// Cross-origin requests are already allowed by the CORS middleware
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+type ClockStreamHandler struct {
+	clockRunner *clock.ClockRunner
+	upgrader    websocket.Upgrader
+}
+
+// ClockStreamMessage is sent to WebSocket clients. Type is "state", "tick",
+// "complete" or "goal" for clock events, "action" for the result of a control
+// message, and "error" when a control message is rejected.
+type ClockStreamMessage struct {
+	Type       string                   `json:"type"`
+	Action     string                   `json:"action,omitempty"`
+	Error      string                   `json:"error,omitempty"`
+	Completion *ClockCompletionResponse `json:"completion,omitempty"`
+	Goal       *DailyGoalResponse       `json:"goal,omitempty"`
+	*ClockActionResponse
+}
+
+// ClockCompletionResponse describes a completed session in a "complete" message
+type ClockCompletionResponse struct {
+	CompletedState     string `json:"completedState"`
+	NextState          string `json:"nextState"`
+	CycleFinished      bool   `json:"cycleFinished"`
+	SessionNumber      int    `json:"sessionNumber"`
+	WorkTimeDuration   int    `json:"workTimeDuration"`
+	ShortBreakDuration int    `json:"shortBreakDuration"`
+	LongBreakDuration  int    `json:"longBreakDuration"`
+	CompletedAt        string `json:"completedAt"`
+}
+
+// clockControlMessage is received from WebSocket clients; Reason optionally
+// says why a pause was requested
+type clockControlMessage struct {
+	Action string `json:"action"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// StreamClock upgrades the request to a WebSocket that streams state changes and
+// ticks, and accepts {"action":"start|pause|stop|skip"} messages from admins. A
+// pause may carry a "reason".
+func (h *ClockStreamHandler) StreamClock(w http.ResponseWriter, r *http.Request) {
+	// The role is checked on the upgrade request; the socket keeps it for its lifetime
+	canControl := auth.IsVerifiedAdmin(r.Context())
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already replied with an HTTP error
+		log.Printf("WebSocket upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	events, unsubscribe := h.clockRunner.Subscribe(16)
+	defer unsubscribe()
+
+	// Count the socket as watching, for pausing when nobody is
+	release := h.clockRunner.Watch()
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Only the write loop writes to the connection; the read loop hands its
+	// replies over on this channel
+	replies := make(chan ClockStreamMessage, 4)
+	go func() {
+		defer cancel()
+		h.readLoop(ctx, conn, canControl, replies)
+	}()
+
+	h.writeLoop(ctx, conn, events, replies)
+}
+
+// readLoop handles control messages until the client disconnects
+func (h *ClockStreamHandler) readLoop(ctx context.Context, conn *websocket.Conn, canControl bool, replies chan<- ClockStreamMessage) {
+	conn.SetReadLimit(wsMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Printf("WebSocket read error: %v", err)
+			}
+			return
+		}
+
+		reply := h.handleControl(data, canControl)
+		select {
+		case replies <- reply:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// handleControl applies a control message and builds the reply
+func (h *ClockStreamHandler) handleControl(data []byte, canControl bool) ClockStreamMessage {
+	var msg clockControlMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return ClockStreamMessage{Type: "error", Error: "invalid message"}
+	}
+	if !canControl {
+		return ClockStreamMessage{Type: "error", Action: msg.Action, Error: "Insufficient permissions"}
+	}
+
+	result, err := h.runAction(msg)
+	if err != nil {
+		return ClockStreamMessage{Type: "error", Action: msg.Action, Error: err.Error()}
+	}
+
+	response := newClockActionResponse(result, h.clockRunner.GetLocation())
+	return ClockStreamMessage{Type: "action", Action: msg.Action, ClockActionResponse: &response}
+}
+
+func (h *ClockStreamHandler) runAction(msg clockControlMessage) (clock.StartResult, error) {
+	switch msg.Action {
+	case "start":
+		return h.clockRunner.Start()
+	case "pause":
+		return h.clockRunner.PauseWithReason(msg.Reason)
+	case "stop":
+		return h.clockRunner.Stop()
+	case "skip":
+		return h.clockRunner.Skip()
+	default:
+		return clock.StartResult{}, fmt.Errorf("unknown action %q", msg.Action)
+	}
+}
+
+// writeLoop sends the current state, then clock events, replies and pings until
+// the client disconnects or a write fails
+func (h *ClockStreamHandler) writeLoop(ctx context.Context, conn *websocket.Conn, events <-chan clock.ClockEvent, replies <-chan ClockStreamMessage) {
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+
+	write := func(msg ClockStreamMessage) error {
+		conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+		return conn.WriteJSON(msg)
+	}
+
+	if err := write(h.eventMessage(string(clock.EventStateChange), h.clockRunner.Snapshot())); err != nil {
+		return
+	}
+
+	for {
+		select {
+		case event := <-events:
+			msg := h.eventMessage(string(event.Type), event.Snapshot)
+			if event.Completion != nil {
+				msg.Completion = newClockCompletionResponse(*event.Completion, h.clockRunner.GetLocation())
+			}
+			if event.Goal != nil {
+				goal := newDailyGoalResponse(*event.Goal)
+				msg.Goal = &goal
+			}
+			if err := write(msg); err != nil {
+				return
+			}
+		case reply := <-replies:
+			if err := write(reply); err != nil {
+				return
+			}
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-ctx.Done():
+			// The client went away; say goodbye if the connection still allows it
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+				time.Now().Add(wsWriteWait))
+			return
+		}
+	}
+}
+
+func (h *ClockStreamHandler) eventMessage(eventType string, snapshot clock.StartResult) ClockStreamMessage {
+	response := newClockActionResponse(snapshot, h.clockRunner.GetLocation())
+	return ClockStreamMessage{Type: eventType, ClockActionResponse: &response}
+}
+
+func newClockCompletionResponse(completion clock.CompletionEvent, loc *time.Location) *ClockCompletionResponse {
+	return &ClockCompletionResponse{
+		CompletedState:     string(completion.CompletedState),
+		NextState:          string(completion.NextState),
+		CycleFinished:      completion.CycleFinished,
+		SessionNumber:      completion.SessionNumber,
+		WorkTimeDuration:   completion.WorkMinutes,
+		ShortBreakDuration: completion.ShortBreakMinutes,
+		LongBreakDuration:  completion.LongBreakMinutes,
+		CompletedAt:        completion.CompletedAt.In(loc).Format(timeFormatMillis),
+	}
+}
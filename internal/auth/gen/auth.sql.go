@@ -59,19 +59,37 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (CreateU
 	return i, err
 }
 
-const getPasswordHashByUsername = `-- name: GetPasswordHashByUsername :one
-SELECT password_hash FROM users WHERE username = $1
+const deleteUser = `-- name: DeleteUser :execrows
+DELETE FROM users WHERE id = $1
 `
 
-func (q *Queries) GetPasswordHashByUsername(ctx context.Context, username string) (string, error) {
-	row := q.db.QueryRow(ctx, getPasswordHashByUsername, username)
-	var password_hash string
-	err := row.Scan(&password_hash)
-	return password_hash, err
+func (q *Queries) DeleteUser(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getLoginStateByUsername = `-- name: GetLoginStateByUsername :one
+SELECT password_hash, failed_login_attempts, locked_until FROM users WHERE username = $1
+`
+
+type GetLoginStateByUsernameRow struct {
+	PasswordHash        string           `db:"password_hash"`
+	FailedLoginAttempts int32            `db:"failed_login_attempts"`
+	LockedUntil         pgtype.Timestamp `db:"locked_until"`
+}
+
+func (q *Queries) GetLoginStateByUsername(ctx context.Context, username string) (GetLoginStateByUsernameRow, error) {
+	row := q.db.QueryRow(ctx, getLoginStateByUsername, username)
+	var i GetLoginStateByUsernameRow
+	err := row.Scan(&i.PasswordHash, &i.FailedLoginAttempts, &i.LockedUntil)
+	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, username, email, role, created_at FROM users WHERE email = $1
+SELECT id, username, email, role, created_at FROM users WHERE lower(email) = lower($1)
 `
 
 type GetUserByEmailRow struct {
@@ -96,10 +114,89 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (GetUserByEm
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, username, email, role, created_at FROM users WHERE username = $1
+SELECT id, username, email, role, created_at, email_verified FROM users WHERE username = $1
 `
 
 type GetUserByUsernameRow struct {
+	ID            pgtype.UUID      `db:"id"`
+	Username      string           `db:"username"`
+	Email         string           `db:"email"`
+	Role          UserRole         `db:"role"`
+	CreatedAt     pgtype.Timestamp `db:"created_at"`
+	EmailVerified bool             `db:"email_verified"`
+}
+
+func (q *Queries) GetUserByUsername(ctx context.Context, username string) (GetUserByUsernameRow, error) {
+	row := q.db.QueryRow(ctx, getUserByUsername, username)
+	var i GetUserByUsernameRow
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.Role,
+		&i.CreatedAt,
+		&i.EmailVerified,
+	)
+	return i, err
+}
+
+const getUserIDByUsernameFold = `-- name: GetUserIDByUsernameFold :one
+SELECT id FROM users WHERE lower(username) = lower($1) LIMIT 1
+`
+
+func (q *Queries) GetUserIDByUsernameFold(ctx context.Context, username string) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, getUserIDByUsernameFold, username)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const lockUser = `-- name: LockUser :exec
+UPDATE users SET failed_login_attempts = 0, locked_until = $1 WHERE username = $2
+`
+
+type LockUserParams struct {
+	LockedUntil pgtype.Timestamp `db:"locked_until"`
+	Username    string           `db:"username"`
+}
+
+func (q *Queries) LockUser(ctx context.Context, arg LockUserParams) error {
+	_, err := q.db.Exec(ctx, lockUser, arg.LockedUntil, arg.Username)
+	return err
+}
+
+const recordFailedLogin = `-- name: RecordFailedLogin :one
+UPDATE users SET failed_login_attempts = failed_login_attempts + 1 WHERE username = $1
+RETURNING failed_login_attempts
+`
+
+func (q *Queries) RecordFailedLogin(ctx context.Context, username string) (int32, error) {
+	row := q.db.QueryRow(ctx, recordFailedLogin, username)
+	var failed_login_attempts int32
+	err := row.Scan(&failed_login_attempts)
+	return failed_login_attempts, err
+}
+
+const resetFailedLogins = `-- name: ResetFailedLogins :exec
+UPDATE users SET failed_login_attempts = 0, locked_until = NULL WHERE username = $1
+`
+
+func (q *Queries) ResetFailedLogins(ctx context.Context, username string) error {
+	_, err := q.db.Exec(ctx, resetFailedLogins, username)
+	return err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users SET role = $1 WHERE username = $2
+RETURNING id, username, email, role, created_at
+`
+
+type UpdateUserRoleParams struct {
+	Role     UserRole `db:"role"`
+	Username string   `db:"username"`
+}
+
+type UpdateUserRoleRow struct {
 	ID        pgtype.UUID      `db:"id"`
 	Username  string           `db:"username"`
 	Email     string           `db:"email"`
@@ -107,9 +204,9 @@ type GetUserByUsernameRow struct {
 	CreatedAt pgtype.Timestamp `db:"created_at"`
 }
 
-func (q *Queries) GetUserByUsername(ctx context.Context, username string) (GetUserByUsernameRow, error) {
-	row := q.db.QueryRow(ctx, getUserByUsername, username)
-	var i GetUserByUsernameRow
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (UpdateUserRoleRow, error) {
+	row := q.db.QueryRow(ctx, updateUserRole, arg.Role, arg.Username)
+	var i UpdateUserRoleRow
 	err := row.Scan(
 		&i.ID,
 		&i.Username,
@@ -119,3 +216,15 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (GetUs
 	)
 	return i, err
 }
+
+const verifyUserEmail = `-- name: VerifyUserEmail :execrows
+UPDATE users SET email_verified = true WHERE username = $1
+`
+
+func (q *Queries) VerifyUserEmail(ctx context.Context, username string) (int64, error) {
+	result, err := q.db.Exec(ctx, verifyUserEmail, username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -54,10 +54,22 @@ func (ns NullUserRole) Value() (driver.Value, error) {
 }
 
 type User struct {
-	ID           pgtype.UUID      `db:"id"`
-	Username     string           `db:"username"`
-	Email        string           `db:"email"`
-	PasswordHash string           `db:"password_hash"`
-	Role         UserRole         `db:"role"`
-	CreatedAt    pgtype.Timestamp `db:"created_at"`
+	ID                  pgtype.UUID      `db:"id"`
+	Username            string           `db:"username"`
+	Email               string           `db:"email"`
+	PasswordHash        string           `db:"password_hash"`
+	Role                UserRole         `db:"role"`
+	CreatedAt           pgtype.Timestamp `db:"created_at"`
+	EmailVerified       bool             `db:"email_verified"`
+	FailedLoginAttempts int32            `db:"failed_login_attempts"`
+	LockedUntil         pgtype.Timestamp `db:"locked_until"`
+}
+
+type UserSetting struct {
+	UserID            pgtype.UUID      `db:"user_id"`
+	WorkMinutes       int32            `db:"work_minutes"`
+	ShortBreakMinutes int32            `db:"short_break_minutes"`
+	LongBreakMinutes  int32            `db:"long_break_minutes"`
+	Scheduling        string           `db:"scheduling"`
+	UpdatedAt         pgtype.Timestamp `db:"updated_at"`
 }
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrNoSigningKey is returned by GenerateJWT when only a public key is
+// configured, so this instance can validate tokens but not issue them
+var ErrNoSigningKey = errors.New("no JWT signing key configured")
+
+// jwtKeySet is the algorithm and keys used to sign and verify tokens
+type jwtKeySet struct {
+	method jwt.SigningMethod
+	// signKey is a []byte secret for HS256 or an *rsa.PrivateKey for RS256;
+	// nil when tokens can only be validated
+	signKey any
+	// verifyKey is a []byte secret for HS256 or an *rsa.PublicKey for RS256
+	verifyKey any
+	// previousVerifyKey is the HS256 secret being rotated out, from
+	// JWT_SECRET_PREVIOUS; tokens it signed stay valid until it is removed
+	previousVerifyKey any
+}
+
+var (
+	jwtKeysMu sync.RWMutex
+	// jwtKeys defaults to HS256 with JWT_SECRET until LoadJWTKeys runs
+	jwtKeys = hs256Keys()
+)
+
+func hs256Keys() jwtKeySet {
+	keys := jwtKeySet{
+		method:    jwt.SigningMethodHS256,
+		signKey:   []byte(jwtSecretKey),
+		verifyKey: []byte(jwtSecretKey),
+	}
+	if previous := os.Getenv("JWT_SECRET_PREVIOUS"); previous != "" {
+		keys.previousVerifyKey = []byte(previous)
+	}
+	return keys
+}
+
+// verificationKey returns the key to check signatures with, trying the current
+// key before the previous one during a secret rotation
+func (k jwtKeySet) verificationKey() any {
+	if k.previousVerifyKey == nil {
+		return k.verifyKey
+	}
+	return jwt.VerificationKeySet{Keys: []jwt.VerificationKey{k.verifyKey, k.previousVerifyKey}}
+}
+
+func currentJWTKeys() jwtKeySet {
+	jwtKeysMu.RLock()
+	defer jwtKeysMu.RUnlock()
+	return jwtKeys
+}
+
+// LoadJWTKeys configures token signing from the environment. JWT_ALGORITHM is
+// HS256 (the default, using JWT_SECRET and accepting JWT_SECRET_PREVIOUS while
+// a secret is rotated) or RS256. RS256 reads PEM keys from
+// JWT_PRIVATE_KEY_PATH and JWT_PUBLIC_KEY_PATH; the public key is derived from
+// the private one when only that is given, and an instance with only the public
+// key validates tokens but cannot issue them.
+func LoadJWTKeys() error {
+	keys, err := jwtKeysFromEnv()
+	if err != nil {
+		return err
+	}
+
+	jwtKeysMu.Lock()
+	defer jwtKeysMu.Unlock()
+	jwtKeys = keys
+	return nil
+}
+
+func jwtKeysFromEnv() (jwtKeySet, error) {
+	switch algorithm := os.Getenv("JWT_ALGORITHM"); algorithm {
+	case "", jwt.SigningMethodHS256.Alg():
+		return hs256Keys(), nil
+	case jwt.SigningMethodRS256.Alg():
+		return rs256KeysFromFiles(os.Getenv("JWT_PRIVATE_KEY_PATH"), os.Getenv("JWT_PUBLIC_KEY_PATH"))
+	default:
+		return jwtKeySet{}, fmt.Errorf("unsupported JWT_ALGORITHM %q: must be HS256 or RS256", algorithm)
+	}
+}
+
+func rs256KeysFromFiles(privatePath, publicPath string) (jwtKeySet, error) {
+	keys := jwtKeySet{method: jwt.SigningMethodRS256}
+	if privatePath == "" && publicPath == "" {
+		return keys, errors.New("RS256 needs JWT_PRIVATE_KEY_PATH or JWT_PUBLIC_KEY_PATH")
+	}
+
+	var privateKey *rsa.PrivateKey
+	if privatePath != "" {
+		data, err := os.ReadFile(privatePath)
+		if err != nil {
+			return keys, fmt.Errorf("failed to read JWT private key: %w", err)
+		}
+		privateKey, err = jwt.ParseRSAPrivateKeyFromPEM(data)
+		if err != nil {
+			return keys, fmt.Errorf("failed to parse JWT private key: %w", err)
+		}
+		keys.signKey = privateKey
+		keys.verifyKey = &privateKey.PublicKey
+	}
+
+	if publicPath != "" {
+		data, err := os.ReadFile(publicPath)
+		if err != nil {
+			return keys, fmt.Errorf("failed to read JWT public key: %w", err)
+		}
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM(data)
+		if err != nil {
+			return keys, fmt.Errorf("failed to parse JWT public key: %w", err)
+		}
+		if privateKey != nil && !privateKey.PublicKey.Equal(publicKey) {
+			return keys, errors.New("JWT public key does not match the private key")
+		}
+		keys.verifyKey = publicKey
+	}
+	return keys, nil
+}
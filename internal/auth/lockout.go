@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrAccountLocked is returned by AuthenticateUser while an account is locked
+// after too many failed logins
+var ErrAccountLocked = errors.New("account locked after too many failed logins")
+
+// LoginLockout locks an account for Duration once MaxAttempts consecutive
+// logins have failed. The failure count restarts when the lock is set, so the
+// account gets MaxAttempts fresh tries once the lock expires.
+type LoginLockout struct {
+	MaxAttempts int
+	Duration    time.Duration
+}
+
+// DefaultLoginLockout is the policy used by the Postgres repository
+var DefaultLoginLockout = LoginLockout{
+	MaxAttempts: 5,
+	Duration:    15 * time.Minute,
+}
+
+// IsLocked reports whether an account locked until lockedUntil is still locked at now
+func (l LoginLockout) IsLocked(lockedUntil *time.Time, now time.Time) bool {
+	return lockedUntil != nil && now.Before(*lockedUntil)
+}
+
+// LockUntil returns when an account with the given number of consecutive
+// failed logins should unlock, or nil while it is under the limit
+func (l LoginLockout) LockUntil(failedAttempts int, now time.Time) *time.Time {
+	if l.MaxAttempts <= 0 || failedAttempts < l.MaxAttempts {
+		return nil
+	}
+	until := now.Add(l.Duration)
+	return &until
+}
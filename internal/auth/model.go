@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"os"
 	"strings"
@@ -13,6 +14,7 @@ import (
 
 const (
 	newUserRole = "USER"
+	adminRole   = "ADMIN"
 	// JWT expiration time - 24 hours
 	jwtExpirationHours = 24
 	// JWT secret key (in production, this should be from environment variable) from .env file
@@ -20,6 +22,24 @@ const (
 
 var jwtSecretKey = os.Getenv("JWT_SECRET")
 
+// defaultJWTIssuer is the issuer used when JWT_ISSUER is unset
+const defaultJWTIssuer = "pomodoro-service"
+
+// jwtIssuer is set on generated tokens and required on validated ones; give
+// each deployment sharing a secret its own so they reject each other's tokens
+var jwtIssuer = envOrDefault("JWT_ISSUER", defaultJWTIssuer)
+
+// jwtAudience, when set, is added to generated tokens and required on validated ones
+var jwtAudience = os.Getenv("JWT_AUDIENCE")
+
+// envOrDefault returns the named environment variable, or fallback when unset
+func envOrDefault(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
 // Context keys for storing user information
 type contextKey string
 
@@ -27,6 +47,12 @@ const (
 	userIDKey   contextKey = "user_id"
 	usernameKey contextKey = "username"
 	roleKey     contextKey = "role"
+	// emailVerifiedKey holds whether the user had verified their email at request time
+	emailVerifiedKey contextKey = "email_verified"
+	// authenticatedUserKey holds a *string the role middlewares fill in with the user ID
+	authenticatedUserKey contextKey = "authenticated_user"
+	// claimsKey holds the *JWTClaims of the validated token
+	claimsKey contextKey = "claims"
 )
 
 type JWTClaims struct {
@@ -44,13 +70,23 @@ func NewAuthRepository(conn *pgxpool.Pool) AuthRepository {
 }
 
 type User struct {
-	ID           *string    `json:"id,omitempty"`
-	Username     *string    `json:"username,omitempty"`
-	Password     *string    `json:"password,omitempty"`
-	PasswordHash *string    `json:"password_hash,omitempty"`
-	Email        *string    `json:"email,omitempty"`
-	Role         *string    `json:"role,omitempty"`
-	CreatedAt    *time.Time `json:"created_at,omitempty"`
+	ID            *string    `json:"id,omitempty"`
+	Username      *string    `json:"username,omitempty"`
+	Password      *string    `json:"password,omitempty"`
+	PasswordHash  *string    `json:"password_hash,omitempty"`
+	Email         *string    `json:"email,omitempty"`
+	Role          *string    `json:"role,omitempty"`
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+	EmailVerified *bool      `json:"email_verified,omitempty"`
+}
+
+// UserSettings are a user's own pomodoro durations, in minutes, and schedule
+type UserSettings struct {
+	WorkTimeDuration   int       `json:"workTimeDuration"`
+	ShortBreakDuration int       `json:"shortBreakDuration"`
+	LongBreakDuration  int       `json:"longBreakDuration"`
+	Scheduling         string    `json:"scheduling"`
+	UpdatedAt          time.Time `json:"updatedAt"`
 }
 
 type NewUserRequest struct {
@@ -59,6 +95,24 @@ type NewUserRequest struct {
 	Email    string `json:"email"`
 }
 
+// CreateUserRequest is the body of an admin request to provision an account with a given role
+type CreateUserRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+}
+
+// UpdateRoleRequest is the body of an admin request to change a user's role
+type UpdateRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// DeleteAccountRequest re-confirms the caller's password before self-deletion
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
 type UserLoginCredentials struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
@@ -75,6 +129,18 @@ type UserRegistrationResponse struct {
 	Role     string `json:"role"`
 }
 
+// WhoAmIResponse describes the caller's validated token and current role
+type WhoAmIResponse struct {
+	UserID    string   `json:"user_id"`
+	Username  string   `json:"username"`
+	Role      string   `json:"role"`
+	Issuer    string   `json:"issuer"`
+	Audience  []string `json:"audience,omitempty"`
+	IssuedAt  string   `json:"issued_at,omitempty"`
+	ExpiresAt string   `json:"expires_at"`
+	ExpiresIn int64    `json:"expires_in"` // seconds until the token expires
+}
+
 type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message,omitempty"`
@@ -85,23 +151,48 @@ type SuccessResponse struct {
 	Message string `json:"message"`
 }
 
+// NewUserFromRequest creates a new user with the configured default role, see SetDefaultRole
 func NewUserFromRequest(req *NewUserRequest) *User {
-	role := newUserRole
-	return &User{
+	role := GetDefaultRole()
+	user := &User{
 		Username: &req.Username,
 		Password: &req.Password,
+		Email:    &req.Email,
 		Role:     &role,
 	}
+	normalizeUser(user)
+	return user
 }
 
 // NewAdminUserFromRequest creates a new user with ADMIN role
 func NewAdminUserFromRequest(req *NewUserRequest) *User {
-	role := "ADMIN"
-	return &User{
+	role := adminRole
+	user := &User{
 		Username: &req.Username,
 		Password: &req.Password,
+		Email:    &req.Email,
 		Role:     &role,
 	}
+	normalizeUser(user)
+	return user
+}
+
+// NewUserWithRoleFromRequest creates a new user with the role given in the request
+func NewUserWithRoleFromRequest(req *CreateUserRequest) *User {
+	role := req.Role
+	user := &User{
+		Username: &req.Username,
+		Password: &req.Password,
+		Email:    &req.Email,
+		Role:     &role,
+	}
+	normalizeUser(user)
+	return user
+}
+
+// IsValidRole reports whether role is one of the known user roles
+func IsValidRole(role string) bool {
+	return role == newUserRole || role == adminRole
 }
 
 // GenerateJWT generates a JWT token for the given user
@@ -119,13 +210,21 @@ func GenerateJWT(user *User) (string, error) {
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    "pomodoro-service",
+			Issuer:    jwtIssuer,
 			Subject:   *user.ID,
 		},
 	}
+	if jwtAudience != "" {
+		claims.Audience = jwt.ClaimStrings{jwtAudience}
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(jwtSecretKey))
+	keys := currentJWTKeys()
+	if keys.signKey == nil {
+		return "", ErrNoSigningKey
+	}
+
+	token := jwt.NewWithClaims(keys.method, claims)
+	tokenString, err := token.SignedString(keys.signKey)
 	if err != nil {
 		return "", err
 	}
@@ -133,16 +232,30 @@ func GenerateJWT(user *User) (string, error) {
 	return tokenString, nil
 }
 
-// ValidateJWT validates and parses a JWT token
+// ValidateJWT validates and parses a JWT token. Besides the signature and
+// expiry it requires the configured issuer and, when set, audience. Only the
+// configured algorithm is accepted, so an RS256 public key is never used as an
+// HS256 secret.
 func ValidateJWT(tokenString string) (*JWTClaims, error) {
 	claims := &JWTClaims{}
+	keys := currentJWTKeys()
+
+	parserOptions := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{keys.method.Alg()}),
+		jwt.WithIssuer(jwtIssuer),
+	}
+	if jwtAudience != "" {
+		parserOptions = append(parserOptions, jwt.WithAudience(jwtAudience))
+	}
 
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		// WithValidMethods already checks this; the key is only handed out
+		// for the algorithm it belongs to
+		if token.Method.Alg() != keys.method.Alg() {
 			return nil, jwt.ErrSignatureInvalid
 		}
-		return []byte(jwtSecretKey), nil
-	})
+		return keys.verificationKey(), nil
+	}, parserOptions...)
 
 	if err != nil {
 		return nil, err
@@ -176,10 +289,68 @@ func GetUserFromContext(ctx context.Context) (userID, username, role string, ok
 	return userID, username, role, true
 }
 
+// GetClaimsFromContext returns the claims of the token validated by the role middlewares
+func GetClaimsFromContext(ctx context.Context) (*JWTClaims, bool) {
+	claims, ok := ctx.Value(claimsKey).(*JWTClaims)
+	return claims, ok
+}
+
+// WithAuthenticatedUser returns a context in which the role middlewares record
+// the ID of the user they authenticate. Middleware that runs before them, such
+// as access logging, reads the returned pointer once the request is served; it
+// stays empty if the request was not authenticated.
+func WithAuthenticatedUser(ctx context.Context) (context.Context, *string) {
+	userID := new(string)
+	return context.WithValue(ctx, authenticatedUserKey, userID), userID
+}
+
+// recordAuthenticatedUser stores userID for WithAuthenticatedUser, if requested
+func recordAuthenticatedUser(ctx context.Context, userID string) {
+	if slot, ok := ctx.Value(authenticatedUserKey).(*string); ok {
+		*slot = userID
+	}
+}
+
+// IsVerifiedAdmin reports whether the authenticated user in ctx is an admin with a
+// verified email address, i.e. would be let through by RequireAdminRole
+func IsVerifiedAdmin(ctx context.Context) bool {
+	role, _ := ctx.Value(roleKey).(string)
+	verified, _ := ctx.Value(emailVerifiedKey).(bool)
+	return role == adminRole && verified
+}
+
 // Role-based middleware functions
 
+// RoleOption customizes the role-based middleware
+type RoleOption func(*roleOptions)
+
+type roleOptions struct {
+	requireVerifiedEmail bool
+}
+
+// RequireVerifiedEmail rejects users who have not verified their email address
+func RequireVerifiedEmail() RoleOption {
+	return func(o *roleOptions) {
+		o.requireVerifiedEmail = true
+	}
+}
+
+func newRoleOptions(opts []RoleOption) roleOptions {
+	var o roleOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// isEmailVerified reports whether the user has verified their email address
+func isEmailVerified(user *User) bool {
+	return user.EmailVerified != nil && *user.EmailVerified
+}
+
 // RequireUserRole creates middleware that requires a specific user role
-func RequireUserRole(repo AuthRepository, requiredRole string) func(http.Handler) http.Handler {
+func RequireUserRole(repo AuthRepository, requiredRole string, opts ...RoleOption) func(http.Handler) http.Handler {
+	options := newRoleOptions(opts)
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// First check if user is authenticated
@@ -206,6 +377,10 @@ func RequireUserRole(repo AuthRepository, requiredRole string) func(http.Handler
 			// Get current user info from database to get the most up-to-date role
 			user, err := repo.GetUserInfo(claims.Username)
 			if err != nil {
+				if errors.Is(err, ErrUserNotFound) {
+					http.Error(w, "User no longer exists", http.StatusUnauthorized)
+					return
+				}
 				http.Error(w, "Failed to get user information", http.StatusInternalServerError)
 				return
 			}
@@ -216,23 +391,32 @@ func RequireUserRole(repo AuthRepository, requiredRole string) func(http.Handler
 				return
 			}
 
+			if options.requireVerifiedEmail && !isEmailVerified(user) {
+				http.Error(w, "Email address not verified", http.StatusForbidden)
+				return
+			}
+
 			// Add user information to request context
+			recordAuthenticatedUser(r.Context(), claims.UserID)
 			ctx := context.WithValue(r.Context(), userIDKey, claims.UserID)
 			ctx = context.WithValue(ctx, usernameKey, claims.Username)
 			ctx = context.WithValue(ctx, roleKey, *user.Role)
+			ctx = context.WithValue(ctx, emailVerifiedKey, isEmailVerified(user))
+			ctx = context.WithValue(ctx, claimsKey, claims)
 
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
-// RequireAdminRole creates middleware that requires ADMIN role
+// RequireAdminRole creates middleware that requires ADMIN role and a verified email address
 func RequireAdminRole(repo AuthRepository) func(http.Handler) http.Handler {
-	return RequireUserRole(repo, "ADMIN")
+	return RequireUserRole(repo, adminRole, RequireVerifiedEmail())
 }
 
 // RequireAnyUserRole creates middleware that allows USER or ADMIN roles
-func RequireAnyUserRole(repo AuthRepository) func(http.Handler) http.Handler {
+func RequireAnyUserRole(repo AuthRepository, opts ...RoleOption) func(http.Handler) http.Handler {
+	options := newRoleOptions(opts)
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// First check if user is authenticated
@@ -259,6 +443,10 @@ func RequireAnyUserRole(repo AuthRepository) func(http.Handler) http.Handler {
 			// Get current user info from database to get the most up-to-date role
 			user, err := repo.GetUserInfo(claims.Username)
 			if err != nil {
+				if errors.Is(err, ErrUserNotFound) {
+					http.Error(w, "User no longer exists", http.StatusUnauthorized)
+					return
+				}
 				http.Error(w, "Failed to get user information", http.StatusInternalServerError)
 				return
 			}
@@ -269,10 +457,18 @@ func RequireAnyUserRole(repo AuthRepository) func(http.Handler) http.Handler {
 				return
 			}
 
+			if options.requireVerifiedEmail && !isEmailVerified(user) {
+				http.Error(w, "Email address not verified", http.StatusForbidden)
+				return
+			}
+
 			// Add user information to request context
+			recordAuthenticatedUser(r.Context(), claims.UserID)
 			ctx := context.WithValue(r.Context(), userIDKey, claims.UserID)
 			ctx = context.WithValue(ctx, usernameKey, claims.Username)
 			ctx = context.WithValue(ctx, roleKey, *user.Role)
+			ctx = context.WithValue(ctx, emailVerifiedKey, isEmailVerified(user))
+			ctx = context.WithValue(ctx, claimsKey, claims)
 
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// caseInsensitiveUsernames is off until LoadUsernameNormalization or
+// SetCaseInsensitiveUsernames turns it on
+var caseInsensitiveUsernames atomic.Bool
+
+// LoadUsernameNormalization configures whether usernames are lowercased from
+// CASE_INSENSITIVE_USERNAMES, a boolean such as "true" or "false"
+func LoadUsernameNormalization() error {
+	value := os.Getenv("CASE_INSENSITIVE_USERNAMES")
+	if value == "" {
+		SetCaseInsensitiveUsernames(false)
+		return nil
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid CASE_INSENSITIVE_USERNAMES %q: %w", value, err)
+	}
+	SetCaseInsensitiveUsernames(enabled)
+	return nil
+}
+
+// SetCaseInsensitiveUsernames sets whether usernames are lowercased, so that
+// "Alice" and "alice" are the same account. Existing usernames are not
+// rewritten; users stored with capitals must be lowercased in the database
+// before they can log in with it on.
+func SetCaseInsensitiveUsernames(enabled bool) {
+	caseInsensitiveUsernames.Store(enabled)
+}
+
+// CaseInsensitiveUsernames reports whether usernames are lowercased
+func CaseInsensitiveUsernames() bool {
+	return caseInsensitiveUsernames.Load()
+}
+
+// NormalizeUsername trims surrounding whitespace and, with case-insensitive
+// usernames, lowercases the username
+func NormalizeUsername(username string) string {
+	username = strings.TrimSpace(username)
+	if CaseInsensitiveUsernames() {
+		username = strings.ToLower(username)
+	}
+	return username
+}
+
+// NormalizeEmail trims surrounding whitespace and lowercases the email.
+// Emails are always compared without case, so "A@x.com" and "a@x.com" are
+// the same address.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// normalizeUser normalizes the username and email of a new user in place
+func normalizeUser(user *User) {
+	if user.Username != nil {
+		user.Username = stringPtr(NormalizeUsername(*user.Username))
+	}
+	if user.Email != nil {
+		user.Email = stringPtr(NormalizeEmail(*user.Email))
+	}
+}
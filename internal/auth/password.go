@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync/atomic"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultBcryptCost is the bcrypt cost factor used when BCRYPT_COST is unset
+const DefaultBcryptCost = 12
+
+// bcryptCost defaults to DefaultBcryptCost until LoadBcryptCost runs
+var bcryptCost atomic.Int64
+
+func init() {
+	bcryptCost.Store(DefaultBcryptCost)
+}
+
+// LoadBcryptCost configures the password hashing cost from BCRYPT_COST, which
+// must be between bcrypt.MinCost and bcrypt.MaxCost. Existing hashes keep the
+// cost they were created with, so changing it only affects new passwords.
+func LoadBcryptCost() error {
+	value := os.Getenv("BCRYPT_COST")
+	if value == "" {
+		return SetBcryptCost(DefaultBcryptCost)
+	}
+
+	cost, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid BCRYPT_COST %q: %w", value, err)
+	}
+	return SetBcryptCost(cost)
+}
+
+// SetBcryptCost sets the cost factor used by HashPassword
+func SetBcryptCost(cost int) error {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return fmt.Errorf("bcrypt cost must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, cost)
+	}
+	bcryptCost.Store(int64(cost))
+	return nil
+}
+
+// GetBcryptCost returns the cost factor used by HashPassword
+func GetBcryptCost() int {
+	return int(bcryptCost.Load())
+}
+
+// HashPassword hashes a password with bcrypt at the configured cost
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), GetBcryptCost())
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
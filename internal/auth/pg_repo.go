@@ -8,8 +8,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/lib/pq"
 	"golang.org/x/crypto/bcrypt"
 
 	authdb "pomodoroService/internal/auth/gen"
@@ -24,7 +26,19 @@ var (
 	emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 )
 
-var ErrNoContent error
+// ErrUserNotFound is returned when no user matches the given username
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrInvalidCredentials is returned by AuthenticateUser for an unknown
+// username or a wrong password
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// ErrUserExists is wrapped by CreateUser when the username or email is taken,
+// whether found by the duplicate checks or by the unique constraints
+var ErrUserExists = errors.New("already exists")
+
+// ErrUserSettingsNotFound is returned when a user has not saved any settings
+var ErrUserSettingsNotFound = errors.New("user settings not found")
 
 type PostgresRepository struct {
 	Conn    *pgxpool.Pool
@@ -32,7 +46,17 @@ type PostgresRepository struct {
 }
 
 func NewPostgresRepository(conn *pgxpool.Pool) AuthRepository {
-	ErrNoContent = errors.New("no content error")
+	return &PostgresRepository{
+		Conn:    conn,
+		Queries: authdb.New(conn),
+	}
+}
+
+// NewUserSettingsRepository returns the Postgres store for per-user settings
+func NewUserSettingsRepository(conn *pgxpool.Pool) UserSettingsRepository {
+	if conn == nil {
+		return nil
+	}
 	return &PostgresRepository{
 		Conn:    conn,
 		Queries: authdb.New(conn),
@@ -49,14 +73,15 @@ func convertUserRoleToString(role authdb.UserRole) string {
 	return string(role)
 }
 
-func convertStringToUserRole(role string) authdb.UserRole {
+func convertStringToUserRole(role string) (authdb.UserRole, error) {
 	switch role {
-	case "ADMIN":
-		return authdb.UserRoleADMIN
-	case "USER":
-		return authdb.UserRoleUSER
+	case adminRole:
+		return authdb.UserRoleADMIN, nil
+	case newUserRole:
+		return authdb.UserRoleUSER, nil
 	default:
-		return authdb.UserRoleUSER
+		_, err := ParseRole(role)
+		return "", err
 	}
 }
 
@@ -69,12 +94,16 @@ func convertUserToCreateUserParams(user *User) (authdb.CreateUserParams, error)
 	if !emailRegex.MatchString(*user.Email) {
 		return authdb.CreateUserParams{}, fmt.Errorf("invalid email format")
 	}
+	role, err := convertStringToUserRole(*user.Role)
+	if err != nil {
+		return authdb.CreateUserParams{}, err
+	}
 
 	return authdb.CreateUserParams{
 		Username:     *user.Username,
 		Email:        *user.Email,
 		PasswordHash: *user.PasswordHash,
-		Role:         convertStringToUserRole(*user.Role),
+		Role:         role,
 	}, nil
 }
 
@@ -100,18 +129,32 @@ func convertGetUserByUsernameRowToUser(row authdb.GetUserByUsernameRow) *User {
 	email := row.Email
 	role := convertUserRoleToString(row.Role)
 	createdAt := row.CreatedAt.Time.UTC()
+	emailVerified := row.EmailVerified
 
 	return &User{
-		ID:        &id,
-		Username:  &username,
-		Email:     &email,
-		Role:      &role,
-		CreatedAt: &createdAt,
+		ID:            &id,
+		Username:      &username,
+		Email:         &email,
+		Role:          &role,
+		CreatedAt:     &createdAt,
+		EmailVerified: &emailVerified,
 	}
 }
 
 // ValidateNewUser validates a new user before creation
 func (p *PostgresRepository) ValidateNewUser(user *User) error {
+	normalizeUser(user)
+	if err := validateUserFields(user); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+	return checkUserIsNew(ctx, p.Queries, user)
+}
+
+// validateUserFields checks the fields of a new user without touching the database
+func validateUserFields(user *User) error {
 	if user.Username == nil || strings.TrimSpace(*user.Username) == "" {
 		return fmt.Errorf("username is required")
 	}
@@ -125,47 +168,76 @@ func (p *PostgresRepository) ValidateNewUser(user *User) error {
 		return fmt.Errorf("invalid email format")
 	}
 
-	// Check if username already exists
-	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
-	defer cancel()
+	// Reject unknown roles instead of storing them as USER
+	if user.Role != nil {
+		if _, err := ParseRole(*user.Role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	_, err := p.Queries.GetUserByUsername(ctx, *user.Username)
+// checkUserIsNew reports a clear error when the username or email is taken.
+// Emails are compared without case, and so are usernames when they are
+// case-insensitive.
+func checkUserIsNew(ctx context.Context, q *authdb.Queries, user *User) error {
+	// Check if username already exists
+	var err error
+	if CaseInsensitiveUsernames() {
+		_, err = q.GetUserIDByUsernameFold(ctx, *user.Username)
+	} else {
+		_, err = q.GetUserByUsername(ctx, *user.Username)
+	}
 	if err == nil {
-		return fmt.Errorf("username already exists")
+		return fmt.Errorf("username %w", ErrUserExists)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("database error during validation: %w", err)
 	}
 
 	// Check if email already exists
-	_, err = p.Queries.GetUserByEmail(ctx, *user.Email)
+	_, err = q.GetUserByEmail(ctx, *user.Email)
 	if err == nil {
-		return fmt.Errorf("email already exists")
+		return fmt.Errorf("email %w", ErrUserExists)
 	}
 
 	// If the error is not "no rows found", it's a different database error
-	if !strings.Contains(err.Error(), "no rows in result set") {
+	if !errors.Is(err, pgx.ErrNoRows) {
 		return fmt.Errorf("database error during validation: %w", err)
 	}
 
 	return nil
 }
 
+// isUniqueViolation reports whether err is Postgres refusing a duplicate key
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// CreateUser hashes the password and inserts the user. The duplicate checks
+// and the insert share a transaction; two registrations racing for the same
+// username or email are settled by the unique constraints, and the loser gets
+// the same "already exists" error as a sequential duplicate.
 func (p *PostgresRepository) CreateUser(user *User) error {
 	if user.Password == nil {
 		return fmt.Errorf("password is required")
 	}
 
-	// Validate user data before proceeding
-	if err := p.ValidateNewUser(user); err != nil {
+	// Validate user data before hashing, which is deliberately slow
+	normalizeUser(user)
+	if err := validateUserFields(user); err != nil {
 		return err
 	}
 
 	// Hash the password
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte(*user.Password), 12)
+	passwordHash, err := HashPassword(*user.Password)
 	if err != nil {
 		return err
 	}
 
 	// Set the password hash
-	user.PasswordHash = stringPtr(string(passwordHash))
+	user.PasswordHash = stringPtr(passwordHash)
 
 	// Convert user to sqlc params
 	params, err := convertUserToCreateUserParams(user)
@@ -176,16 +248,29 @@ func (p *PostgresRepository) CreateUser(user *User) error {
 	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
 	defer cancel()
 
-	// Use sqlc generated function
-	result, err := p.Queries.CreateUser(ctx, params)
+	tx, err := p.Conn.Begin(ctx)
 	if err != nil {
-		if pgErr, ok := err.(*pq.Error); ok {
-			if pgErr.Code == "23505" {
-				return fmt.Errorf("username or email already exists")
-			}
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	q := p.Queries.WithTx(tx)
+
+	if err := checkUserIsNew(ctx, q, user); err != nil {
+		return err
+	}
+	result, err := q.CreateUser(ctx, params)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("username or email %w", ErrUserExists)
 		}
 		return err
 	}
+	if err := tx.Commit(ctx); err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("username or email %w", ErrUserExists)
+		}
+		return fmt.Errorf("failed to commit user: %w", err)
+	}
 
 	// Convert result back to our User model
 	convertedUser := convertCreateUserRowToUser(result)
@@ -199,21 +284,56 @@ func (p *PostgresRepository) AuthenticateUser(cred *UserLoginCredentials) (bool,
 	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
 	defer cancel()
 
-	// Get password hash using sqlc generated function
-	passwordHash, err := p.Queries.GetPasswordHashByUsername(ctx, cred.Username)
+	// Get password hash and lockout state using sqlc generated function
+	state, err := p.Queries.GetLoginStateByUsername(ctx, cred.Username)
 	if err != nil {
-		return false, errors.New("invalid credentials")
+		return false, ErrInvalidCredentials
+	}
+
+	// Refuse locked accounts without checking the password
+	now := time.Now().UTC()
+	if state.LockedUntil.Valid {
+		lockedUntil := state.LockedUntil.Time.UTC()
+		if DefaultLoginLockout.IsLocked(&lockedUntil, now) {
+			return false, ErrAccountLocked
+		}
 	}
 
 	// Compare password hash
-	if err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(cred.Password)); err != nil {
-		return false, errors.New("invalid credentials")
+	if err := bcrypt.CompareHashAndPassword([]byte(state.PasswordHash), []byte(cred.Password)); err != nil {
+		if lockErr := p.recordFailedLogin(ctx, cred.Username, now); lockErr != nil {
+			return false, lockErr
+		}
+		return false, ErrInvalidCredentials
 	}
 
-	// Successful authentication
+	// Successful authentication clears any failures
+	if state.FailedLoginAttempts > 0 || state.LockedUntil.Valid {
+		if err := p.Queries.ResetFailedLogins(ctx, cred.Username); err != nil {
+			return false, err
+		}
+	}
 	return true, nil
 }
 
+// recordFailedLogin counts a bad password and locks the account once the
+// failures reach DefaultLoginLockout.MaxAttempts
+func (p *PostgresRepository) recordFailedLogin(ctx context.Context, username string, now time.Time) error {
+	attempts, err := p.Queries.RecordFailedLogin(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	lockedUntil := DefaultLoginLockout.LockUntil(int(attempts), now)
+	if lockedUntil == nil {
+		return nil
+	}
+	return p.Queries.LockUser(ctx, authdb.LockUserParams{
+		LockedUntil: pgtype.Timestamp{Time: *lockedUntil, Valid: true},
+		Username:    username,
+	})
+}
+
 func (p *PostgresRepository) GetUserInfo(username string) (*User, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
 	defer cancel()
@@ -221,6 +341,9 @@ func (p *PostgresRepository) GetUserInfo(username string) (*User, error) {
 	// Use sqlc generated function
 	result, err := p.Queries.GetUserByUsername(ctx, username)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
 		return nil, err
 	}
 
@@ -228,3 +351,137 @@ func (p *PostgresRepository) GetUserInfo(username string) (*User, error) {
 	user := convertGetUserByUsernameRowToUser(result)
 	return user, nil
 }
+
+func (p *PostgresRepository) UpdateUserRole(username, role string) error {
+	dbRole, err := convertStringToUserRole(role)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	// Use sqlc generated function
+	_, err = p.Queries.UpdateUserRole(ctx, authdb.UpdateUserRoleParams{
+		Role:     dbRole,
+		Username: username,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (p *PostgresRepository) VerifyEmail(username string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	// Use sqlc generated function
+	rows, err := p.Queries.VerifyUserEmail(ctx, username)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// DeleteUser removes a user; their settings are deleted with them by the
+// user_settings foreign key
+func (p *PostgresRepository) DeleteUser(userID string) error {
+	id, err := parseUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	// Use sqlc generated function
+	rows, err := p.Queries.DeleteUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func parseUserID(userID string) (pgtype.UUID, error) {
+	var id pgtype.UUID
+	if err := id.Scan(userID); err != nil {
+		return pgtype.UUID{}, fmt.Errorf("invalid user id %q: %w", userID, err)
+	}
+	return id, nil
+}
+
+func convertUserSettingToUserSettings(row authdb.UserSetting) *UserSettings {
+	return &UserSettings{
+		WorkTimeDuration:   int(row.WorkMinutes),
+		ShortBreakDuration: int(row.ShortBreakMinutes),
+		LongBreakDuration:  int(row.LongBreakMinutes),
+		Scheduling:         row.Scheduling,
+		UpdatedAt:          row.UpdatedAt.Time.UTC(),
+	}
+}
+
+func (p *PostgresRepository) GetUserSettings(userID string) (*UserSettings, error) {
+	id, err := parseUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	// Use sqlc generated function
+	result, err := p.Queries.GetUserSettings(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserSettingsNotFound
+		}
+		return nil, err
+	}
+
+	return convertUserSettingToUserSettings(result), nil
+}
+
+// SaveUserSettings creates or replaces the settings of a user and sets
+// settings.UpdatedAt to the stored time
+func (p *PostgresRepository) SaveUserSettings(userID string, settings *UserSettings) error {
+	id, err := parseUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	// Use sqlc generated function
+	result, err := p.Queries.UpsertUserSettings(ctx, authdb.UpsertUserSettingsParams{
+		UserID:            id,
+		WorkMinutes:       int32(settings.WorkTimeDuration),
+		ShortBreakMinutes: int32(settings.ShortBreakDuration),
+		LongBreakMinutes:  int32(settings.LongBreakDuration),
+		Scheduling:        settings.Scheduling,
+	})
+	if err != nil {
+		// A foreign key violation means the user no longer exists
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	settings.UpdatedAt = convertUserSettingToUserSettings(result).UpdatedAt
+	return nil
+}
@@ -13,10 +13,33 @@ INSERT INTO users (
 RETURNING id, username, email, role, created_at;
 
 -- name: GetUserByUsername :one
-SELECT id, username, email, role, created_at FROM users WHERE username = sqlc.arg(username);
+SELECT id, username, email, role, created_at, email_verified FROM users WHERE username = sqlc.arg(username);
+
+-- name: GetUserIDByUsernameFold :one
+SELECT id FROM users WHERE lower(username) = lower(sqlc.arg(username)) LIMIT 1;
 
 -- name: GetUserByEmail :one
-SELECT id, username, email, role, created_at FROM users WHERE email = sqlc.arg(email);
+SELECT id, username, email, role, created_at FROM users WHERE lower(email) = lower(sqlc.arg(email));
+
+-- name: GetLoginStateByUsername :one
+SELECT password_hash, failed_login_attempts, locked_until FROM users WHERE username = sqlc.arg(username);
+
+-- name: RecordFailedLogin :one
+UPDATE users SET failed_login_attempts = failed_login_attempts + 1 WHERE username = sqlc.arg(username)
+RETURNING failed_login_attempts;
+
+-- name: LockUser :exec
+UPDATE users SET failed_login_attempts = 0, locked_until = sqlc.arg(locked_until) WHERE username = sqlc.arg(username);
+
+-- name: ResetFailedLogins :exec
+UPDATE users SET failed_login_attempts = 0, locked_until = NULL WHERE username = sqlc.arg(username);
+
+-- name: UpdateUserRole :one
+UPDATE users SET role = sqlc.arg(role) WHERE username = sqlc.arg(username)
+RETURNING id, username, email, role, created_at;
+
+-- name: DeleteUser :execrows
+DELETE FROM users WHERE id = sqlc.arg(id);
 
--- name: GetPasswordHashByUsername :one
-SELECT password_hash FROM users WHERE username = sqlc.arg(username);
+-- name: VerifyUserEmail :execrows
+UPDATE users SET email_verified = true WHERE username = sqlc.arg(username);
@@ -0,0 +1,25 @@
+-- name: GetUserSettings :one
+SELECT user_id, work_minutes, short_break_minutes, long_break_minutes, scheduling, updated_at
+FROM user_settings WHERE user_id = sqlc.arg(user_id);
+
+-- name: UpsertUserSettings :one
+INSERT INTO user_settings (
+    user_id,
+    work_minutes,
+    short_break_minutes,
+    long_break_minutes,
+    scheduling
+) VALUES (
+    sqlc.arg(user_id),
+    sqlc.arg(work_minutes),
+    sqlc.arg(short_break_minutes),
+    sqlc.arg(long_break_minutes),
+    sqlc.arg(scheduling)
+)
+ON CONFLICT (user_id) DO UPDATE SET
+    work_minutes = EXCLUDED.work_minutes,
+    short_break_minutes = EXCLUDED.short_break_minutes,
+    long_break_minutes = EXCLUDED.long_break_minutes,
+    scheduling = EXCLUDED.scheduling,
+    updated_at = now()
+RETURNING user_id, work_minutes, short_break_minutes, long_break_minutes, scheduling, updated_at;
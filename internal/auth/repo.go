@@ -4,4 +4,13 @@ type AuthRepository interface {
 	CreateUser(user *User) error
 	AuthenticateUser(credentials *UserLoginCredentials) (bool, error)
 	GetUserInfo(username string) (*User, error)
+	UpdateUserRole(username, role string) error
+	VerifyEmail(username string) error
+	DeleteUser(userID string) error
+}
+
+// UserSettingsRepository stores the pomodoro settings of each user, keyed by user ID
+type UserSettingsRepository interface {
+	GetUserSettings(userID string) (*UserSettings, error)
+	SaveUserSettings(userID string, settings *UserSettings) error
 }
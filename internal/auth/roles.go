@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+)
+
+// ErrInvalidRole is returned for a role other than USER or ADMIN
+var ErrInvalidRole = errors.New("invalid role")
+
+// DefaultUserRole is the role of self-registered users when DEFAULT_USER_ROLE is unset
+const DefaultUserRole = newUserRole
+
+// defaultRole defaults to DefaultUserRole until LoadDefaultRole runs
+var defaultRole atomic.Pointer[string]
+
+func init() {
+	role := DefaultUserRole
+	defaultRole.Store(&role)
+}
+
+// RolesResponse lists the roles a user can have, for clients building role pickers
+type RolesResponse struct {
+	Roles       []string `json:"roles"`
+	DefaultRole string   `json:"defaultRole"`
+}
+
+// ValidRoles returns the known roles, lowest privilege first
+func ValidRoles() []string {
+	return []string{newUserRole, adminRole}
+}
+
+// ParseRole returns role unchanged if it is one of ValidRoles; roles are
+// case-sensitive, and anything else is ErrInvalidRole rather than a fallback
+func ParseRole(role string) (string, error) {
+	if !IsValidRole(role) {
+		return "", fmt.Errorf("%w %q: must be one of %v", ErrInvalidRole, role, ValidRoles())
+	}
+	return role, nil
+}
+
+// LoadDefaultRole configures the role of self-registered users from
+// DEFAULT_USER_ROLE, which must be one of ValidRoles
+func LoadDefaultRole() error {
+	value := os.Getenv("DEFAULT_USER_ROLE")
+	if value == "" {
+		return SetDefaultRole(DefaultUserRole)
+	}
+	if err := SetDefaultRole(value); err != nil {
+		return fmt.Errorf("invalid DEFAULT_USER_ROLE: %w", err)
+	}
+	return nil
+}
+
+// SetDefaultRole sets the role NewUserFromRequest gives new users
+func SetDefaultRole(role string) error {
+	role, err := ParseRole(role)
+	if err != nil {
+		return err
+	}
+	defaultRole.Store(&role)
+	return nil
+}
+
+// GetDefaultRole returns the role NewUserFromRequest gives new users
+func GetDefaultRole() string {
+	return *defaultRole.Load()
+}
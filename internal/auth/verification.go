@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Verification links stay valid for 24 hours
+	verificationTokenTTL   = 24 * time.Hour
+	verificationKeyPrefix  = "email_verification:"
+	verificationTokenBytes = 32
+)
+
+// ErrInvalidVerificationToken is returned for unknown, expired, or already used tokens
+var ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+
+// VerificationStore keeps single-use email verification tokens in Redis
+type VerificationStore struct {
+	client *redis.Client
+	ctx    context.Context
+}
+
+// NewVerificationStore connects to Redis and returns a verification token store
+func NewVerificationStore(addr string) (*VerificationStore, error) {
+	return NewVerificationStoreWithOptions(&redis.Options{Addr: addr})
+}
+
+// NewVerificationStoreWithOptions connects to Redis using full client options
+// and returns a verification token store
+func NewVerificationStoreWithOptions(opts *redis.Options) (*VerificationStore, error) {
+	client := redis.NewClient(opts)
+
+	ctx := context.Background()
+	if _, err := client.Ping(ctx).Result(); err != nil {
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	return &VerificationStore{
+		client: client,
+		ctx:    ctx,
+	}, nil
+}
+
+// CreateToken generates a verification token for the given username
+func (s *VerificationStore) CreateToken(username string) (string, error) {
+	buf := make([]byte, verificationTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate verification token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	if err := s.client.Set(s.ctx, verificationKeyPrefix+token, username, verificationTokenTTL).Err(); err != nil {
+		return "", fmt.Errorf("failed to store verification token: %w", err)
+	}
+
+	return token, nil
+}
+
+// ConsumeToken returns the username for a token and deletes it so it cannot be reused
+func (s *VerificationStore) ConsumeToken(token string) (string, error) {
+	username, err := s.client.GetDel(s.ctx, verificationKeyPrefix+token).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrInvalidVerificationToken
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to load verification token: %w", err)
+	}
+
+	return username, nil
+}
+
+// Close closes the Redis connection
+func (s *VerificationStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,114 @@
+package clock
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ErrInvalidBehavior is returned for behavior settings outside their limits
+var ErrInvalidBehavior = errors.New("invalid behavior settings")
+
+// BehaviorSettings gathers the flags that change how the clock behaves, as
+// opposed to its durations and schedule, so they can be read and written
+// together
+type BehaviorSettings struct {
+	// AutoStartNext starts the next session as soon as one completes
+	AutoStartNext bool
+	// StrictResume comes up idle instead of recording sessions that elapsed
+	// while the server was down
+	StrictResume bool
+	// IdleTimeout is how long a session may stay paused before the clock
+	// stops; zero disables it
+	IdleTimeout time.Duration
+	// RepeatWindow is how long after a session completes it may be repeated;
+	// zero disables repeating
+	RepeatWindow time.Duration
+	// PauseWhenUnwatched pauses a running session UnwatchedGrace after the
+	// last watching client leaves; see Watch
+	PauseWhenUnwatched bool
+	UnwatchedGrace     time.Duration
+}
+
+// Validate checks every field against its limits
+func (b BehaviorSettings) Validate() error {
+	if b.IdleTimeout < 0 || b.IdleTimeout > MaxIdleTimeout {
+		return fmt.Errorf("%w: idle timeout must be between 0 and %v, got %v", ErrInvalidBehavior, MaxIdleTimeout, b.IdleTimeout)
+	}
+	if b.RepeatWindow < 0 || b.RepeatWindow > MaxRepeatWindow {
+		return fmt.Errorf("%w: repeat window must be between 0 and %v, got %v", ErrInvalidBehavior, MaxRepeatWindow, b.RepeatWindow)
+	}
+	if b.UnwatchedGrace < 0 || b.UnwatchedGrace > MaxUnwatchedGrace {
+		return fmt.Errorf("%w: unwatched grace must be between 0 and %v, got %v", ErrInvalidBehavior, MaxUnwatchedGrace, b.UnwatchedGrace)
+	}
+	return nil
+}
+
+// GetBehavior returns the current behavior settings
+func (cr *ClockRunner) GetBehavior() BehaviorSettings {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	return cr.behaviorLocked()
+}
+
+func (cr *ClockRunner) behaviorLocked() BehaviorSettings {
+	return BehaviorSettings{
+		AutoStartNext: cr.autoStartNext,
+		StrictResume:  cr.strictResume,
+		IdleTimeout:   cr.idleTimeout,
+		RepeatWindow:  cr.repeatWindow,
+
+		PauseWhenUnwatched: cr.pauseWhenUnwatched,
+		UnwatchedGrace:     cr.unwatchedGrace,
+	}
+}
+
+// SetBehavior validates and applies all behavior settings at once and, with
+// Redis configured, saves them. Nothing is applied if any field is invalid.
+// Like SetIdleTimeout, a new idle timeout applies from the next pause.
+func (cr *ClockRunner) SetBehavior(behavior BehaviorSettings) (BehaviorSettings, error) {
+	if err := behavior.Validate(); err != nil {
+		return BehaviorSettings{}, err
+	}
+
+	cr.mu.Lock()
+	cr.applyBehaviorLocked(behavior)
+	effective := cr.behaviorLocked()
+	cr.mu.Unlock()
+
+	// Save settings to Redis
+	if cr.redisPersistence != nil {
+		if err := cr.persistenceManager.SaveSettingsToRedis(); err != nil {
+			slog.Error("failed to save settings to Redis", "error", err)
+		}
+	}
+	return effective, nil
+}
+
+// storedBehavior returns the behavior settings to save, which differ from
+// GetBehavior only when WithStrictResume fixed the strict resume
+func (cr *ClockRunner) storedBehavior() BehaviorSettings {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	behavior := cr.behaviorLocked()
+	if cr.strictResumeFixed {
+		behavior.StrictResume = cr.storedStrictResume
+	}
+	return behavior
+}
+
+// applyBehaviorLocked sets the behavior fields without saving them; the
+// caller must hold cr.mu. A strict resume fixed by WithStrictResume stays in
+// effect.
+func (cr *ClockRunner) applyBehaviorLocked(behavior BehaviorSettings) {
+	cr.autoStartNext = behavior.AutoStartNext
+	cr.idleTimeout = behavior.IdleTimeout
+	cr.repeatWindow = behavior.RepeatWindow
+	cr.pauseWhenUnwatched = behavior.PauseWhenUnwatched
+	cr.unwatchedGrace = behavior.UnwatchedGrace
+	cr.storedStrictResume = behavior.StrictResume
+	if !cr.strictResumeFixed {
+		cr.strictResume = behavior.StrictResume
+	}
+}
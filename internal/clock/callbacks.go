@@ -1,47 +1,201 @@
 package clock
 
 import (
-	"log"
+	"log/slog"
 	"time"
 )
 
 func onComplete(cr *ClockRunner, completedState ClockState, duration time.Duration) {
-	log.Printf("▶️ onComplete - 1")
 	// Record the completed session
-	cr.statsManager.RecordSession(completedState, duration)
+	recordCompletedSession(cr, completedState, duration)
 
 	if cr.onComplete != nil {
 		cr.onComplete(completedState)
 	}
 
-	log.Printf("Completed %s session %d/%d - 2",
-		completedState, cr.sessionManager.GetCurrentSession(), cr.sessionManager.GetTotalSessions())
+	slog.Info("session completed",
+		"state", completedState,
+		"session", cr.sessionManager.GetCurrentSession(),
+		"total_sessions", cr.sessionManager.GetTotalSessions())
 
 	// Move to next session
-	log.Printf("Moving to next session from %d - 3", cr.sessionManager.GetCurrentSession())
+	completedSession := cr.sessionManager.GetCurrentSession()
 	hasNextSession := cr.sessionManager.NextSession()
-	log.Printf("Next session available: %v, current session now: %d - 4", hasNextSession, cr.sessionManager.GetCurrentSession())
+	completion := newCompletionEvent(cr, completedState, completedSession, hasNextSession)
+	notifyCompletionWebhook(cr, completion)
+	saveCompletionEvent(cr, completion)
 	if !hasNextSession {
 		// Completed all sessions - set to idle and save state
 		cr.stateManager.SetState(StateIdle)
-		if cr.onStateChange != nil {
-			cr.onStateChange(StateIdle)
-		}
+		notifyStateChange(cr, StateIdle)
+		cr.publishCompletion(completion)
 		// Save idle state to Redis immediately
 		cr.saveStateToRedis()
-		log.Println("Completed all pomodoro sessions!")
+		slog.Info("completed all pomodoro sessions")
 		return
 	}
 
 	// Start next session - this will save state to Redis
-	cr.startNewSession()
+	beginNextSession(cr)
+	cr.publishCompletion(completion)
 
 	// Ensure the new session state is saved to Redis immediately
 	cr.saveStateToRedis()
 }
 
+// newCompletionEvent describes the completed session once the schedule has
+// moved past it
+func newCompletionEvent(cr *ClockRunner, completedState ClockState, completedSession int, hasNextSession bool) CompletionEvent {
+	nextState := StateIdle
+	if hasNextSession {
+		nextState = cr.sessionManager.GetCurrentSessionState()
+	}
+	workMinutes, shortBreakMinutes, longBreakMinutes := cr.sessionManager.GetDurations()
+
+	return CompletionEvent{
+		CompletedState:    completedState,
+		NextState:         nextState,
+		CycleFinished:     !hasNextSession || completedSession == cr.sessionManager.GetTotalSessions()-1,
+		SessionNumber:     completedSession,
+		WorkMinutes:       workMinutes,
+		ShortBreakMinutes: shortBreakMinutes,
+		LongBreakMinutes:  longBreakMinutes,
+		CompletedAt:       time.Now(),
+	}
+}
+
+// beginNextSession starts the session the schedule has just moved to or, with
+// auto-start off, waits for an explicit Start to begin it
+func beginNextSession(cr *ClockRunner) {
+	if cr.GetAutoStartNext() {
+		cr.startNewSession()
+		return
+	}
+	waitForSession(cr)
+}
+
+// waitForSession puts the clock in StateWaiting with the current session's
+// timer prepared but paused, so Start begins it with its full duration
+func waitForSession(cr *ClockRunner) {
+	state := cr.sessionManager.GetCurrentSessionState()
+	duration := cr.sessionManager.GetCurrentSessionDuration()
+	cr.stateManager.SetState(StateWaiting)
+	cr.startSessionTimer(state, duration)
+	cr.timerManager.PauseTimer()
+
+	slog.Info("waiting to start next session",
+		"state", state,
+		"session", cr.sessionManager.GetCurrentSession(),
+		"total_sessions", cr.sessionManager.GetTotalSessions())
+
+	notifyStateChange(cr, StateWaiting)
+	cr.saveStateToRedis()
+}
+
+// recordCompletedSession records a completed session in memory and, when Redis
+// is configured, in the daily session statistics keys
+func recordCompletedSession(cr *ClockRunner, completedState ClockState, duration time.Duration) {
+	recordSession(cr, currentSessionRecord(cr, completedState, duration))
+}
+
+// recordSession records the current session as completed in memory and, when
+// Redis is configured, in the daily session statistics keys
+func recordSession(cr *ClockRunner, record SessionRecord) {
+	session := cr.sessionManager.GetCurrentSession()
+	if isRecordedBeforeRestart(cr, session, record) {
+		slog.Info("session already recorded before the restart, not recording it again",
+			"session", session,
+			"state", record.State,
+			"completed", record.Completed)
+		return
+	}
+
+	cr.setLastCompleted(session, record.State, record.Completed)
+	saveSessionStats(cr, session, record)
+}
+
+// saveSessionStats adds record to the statistics, checks the daily work goal
+// and, when Redis is configured, saves it to the daily session statistics
+// keys. Unlike recordSession it takes no lock, so Skip can call it while
+// holding cr.mu.
+func saveSessionStats(cr *ClockRunner, session int, record SessionRecord) {
+	cr.statsManager.Record(record)
+	if record.State == StateWorking {
+		if progress, reached := cr.statsManager.MarkDailyGoalReached(); reached {
+			slog.Info("daily work goal reached", "completed", progress.Completed, "goal", progress.Goal)
+			cr.publishGoalReached(progress)
+		}
+	}
+
+	if cr.redisPersistence == nil {
+		return
+	}
+	if err := cr.persistenceManager.SaveSessionRecord(session, record); err != nil {
+		slog.Error("failed to save session statistics to Redis", "state", record.State, "error", err)
+	}
+}
+
+// isRecordedBeforeRestart reports whether record is the session the server
+// recorded just before it restarted. Only the first record after a restart can
+// repeat it, so the marker is consulted once and then dropped.
+func isRecordedBeforeRestart(cr *ClockRunner, session int, record SessionRecord) bool {
+	marker := cr.lastRecordedBeforeRestart.Swap(nil)
+	if marker == nil || cr.duplicateRecordWindow <= 0 {
+		return false
+	}
+	if marker.Session != session || marker.State != record.State {
+		return false
+	}
+	gap := record.Completed.Sub(marker.Completed)
+	return gap <= cr.duplicateRecordWindow && gap >= -cr.duplicateRecordWindow
+}
+
+// currentSessionRecord builds the record of the current session ending now,
+// carrying its tag
+func currentSessionRecord(cr *ClockRunner, state ClockState, duration time.Duration) SessionRecord {
+	return SessionRecord{
+		State:     state,
+		Duration:  duration,
+		Completed: time.Now(),
+		Tag:       cr.sessionManager.GetCurrentTag(),
+	}
+}
+
+// notifyCompletionWebhook posts the completion event to the configured webhook, if any
+func notifyCompletionWebhook(cr *ClockRunner, completion CompletionEvent) {
+	notifier := cr.getWebhookNotifier()
+	if notifier == nil {
+		return
+	}
+
+	notifier.NotifyAsync(CompletionWebhookPayload{
+		CompletedState: completion.CompletedState,
+		NextState:      completion.NextState,
+		SessionNumber:  completion.SessionNumber,
+		CompletedAt:    completion.CompletedAt,
+	})
+}
+
 func onTick(cr *ClockRunner, remaining time.Duration) {
 	if cr.onTick != nil {
 		cr.onTick(remaining)
 	}
+	cr.publish(EventTick)
+}
+
+// logPersistenceHealth reports Redis persistence degrading and recovering
+func logPersistenceHealth(healthy bool, err error) {
+	if healthy {
+		slog.Info("Redis persistence recovered")
+		return
+	}
+	slog.Error("Redis persistence degraded; state changes are not being saved", "error", err)
+}
+
+// notifyStateChange runs the state change callback and notifies subscribers
+func notifyStateChange(cr *ClockRunner, state ClockState) {
+	if cr.onStateChange != nil {
+		cr.onStateChange(state)
+	}
+	cr.publish(EventStateChange)
 }
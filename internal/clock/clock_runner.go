@@ -1,10 +1,16 @@
 package clock
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
+
+	"github.com/redis/go-redis/v9"
 )
 
 // ClockState represents the current state of the pomodoro clock
@@ -15,7 +21,13 @@ const (
 	StateWorking    ClockState = "W"
 	StateShortBreak ClockState = "SB"
 	StateLongBreak  ClockState = "LB"
-	StatePaused     ClockState = "P"
+	// StateCustomBreak is a break such as a meeting that is scheduled like any
+	// other session but kept out of the break statistics
+	StateCustomBreak ClockState = "CB"
+	StatePaused      ClockState = "P"
+	// StateWaiting means a session completed with auto-start off and the next
+	// session is selected, waiting for someone to begin it
+	StateWaiting ClockState = "WT"
 )
 
 var ClockStateMap = map[string]ClockState{
@@ -23,7 +35,9 @@ var ClockStateMap = map[string]ClockState{
 	"W":  StateWorking,
 	"SB": StateShortBreak,
 	"LB": StateLongBreak,
+	"CB": StateCustomBreak,
 	"P":  StatePaused,
+	"WT": StateWaiting,
 }
 
 // ClockRunner manages the pomodoro timer and state using modular components
@@ -39,15 +53,67 @@ type ClockRunner struct {
 	utils          *ClockUtils
 
 	// Redis persistence
-	redisPersistence *RedisPersistence
+	redisPersistence         *RedisPersistence
+	redisHealthCheckInterval time.Duration
+	// resumePolicy decides how a session saved in Redis is resumed at startup;
+	// resumeGracePeriod how far past its end time it may be and still complete
+	resumePolicy      ResumePolicy
+	resumeGracePeriod time.Duration
+	// strictResume comes up idle instead of recording sessions that elapsed
+	// while the server was down. strictResumeFixed is set when WithStrictResume
+	// chose it, so the behavior settings do not override it; they keep their
+	// own value in storedStrictResume, which is what gets saved.
+	strictResume       bool
+	strictResumeFixed  bool
+	storedStrictResume bool
 
 	// Manager components
 	persistenceManager *PersistenceManager
 	resumeManager      *ResumeManager
 
-	// Redis save control
+	// Completion webhook (nil when not configured)
+	webhookNotifier *WebhookNotifier
+
+	// Redis save control; state saves within stateSaveWindow of the last one
+	// are coalesced into a single write
+	redisSaveMu     sync.Mutex // guards redisSaveTicker and redisSaveStop
 	redisSaveTicker *time.Ticker
 	redisSaveStop   chan struct{}
+	stateSaveWindow time.Duration
+
+	// closeOnce makes Close idempotent; closeErr is what the first call returned
+	closeOnce sync.Once
+	closeErr  error
+
+	// Idle timeout for paused sessions; zero disables it. idleGeneration
+	// invalidates a timer that fires after it was disarmed.
+	idleTimeout    time.Duration
+	idleTimer      *time.Timer
+	idleGeneration int
+
+	// autoStartNext starts the next session as soon as one completes; when
+	// false the next session waits paused for an explicit Start
+	autoStartNext bool
+
+	// lastCompleted is the session that last ran to completion, which
+	// RepeatLastSession may run again until repeatWindow has passed
+	lastCompleted *completedSession
+	repeatWindow  time.Duration
+
+	// lastRecordedBeforeRestart is the last session recorded before the server
+	// restarted; a resume completing the same session within
+	// duplicateRecordWindow of it does not record it again
+	lastRecordedBeforeRestart atomic.Pointer[RecordedSession]
+	duplicateRecordWindow     time.Duration
+
+	// Subscribers notified of state changes and ticks
+	listeners *listenerRegistry
+
+	// presence counts watching clients; with pauseWhenUnwatched a running
+	// session pauses unwatchedGrace after the last one leaves
+	presence           presenceTracker
+	pauseWhenUnwatched bool
+	unwatchedGrace     time.Duration
 
 	// Callbacks
 	onStateChange func(ClockState)
@@ -56,20 +122,32 @@ type ClockRunner struct {
 }
 
 // NewClockRunner creates a new clock runner with default settings
-func NewClockRunner() *ClockRunner {
-	return &ClockRunner{
+func NewClockRunner(opts ...ClockRunnerOption) *ClockRunner {
+	cr := &ClockRunner{
 		stateManager:   NewStateManager(),
 		sessionManager: NewSessionManager(),
 		timerManager:   NewTimerManager(),
 		statsManager:   NewStatisticsManager(),
 		timeFormatter:  NewTimeFormatter(),
 		utils:          NewClockUtils(),
+		listeners:      newListenerRegistry(),
+		repeatWindow:   DefaultRepeatWindow,
+		autoStartNext:  true,
+		unwatchedGrace: DefaultUnwatchedGrace,
 	}
+	cr.applyOptions(opts)
+	return cr
 }
 
 // NewClockRunnerWithRedis creates a new clock runner with Redis persistence
-func NewClockRunnerWithRedis(redisAddr string) (*ClockRunner, error) {
-	redisPersistence, err := NewRedisPersistence(redisAddr)
+func NewClockRunnerWithRedis(redisAddr string, opts ...ClockRunnerOption) (*ClockRunner, error) {
+	return NewClockRunnerWithRedisOptions(&redis.Options{Addr: redisAddr}, opts...)
+}
+
+// NewClockRunnerWithRedisOptions creates a new clock runner with Redis
+// persistence configured from full client options
+func NewClockRunnerWithRedisOptions(redisOpts *redis.Options, opts ...ClockRunnerOption) (*ClockRunner, error) {
+	redisPersistence, err := NewRedisPersistenceWithOptions(redisOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize Redis persistence: %w", err)
 	}
@@ -81,7 +159,24 @@ func NewClockRunnerWithRedis(redisAddr string) (*ClockRunner, error) {
 		statsManager:     NewStatisticsManager(),
 		timeFormatter:    NewTimeFormatter(),
 		utils:            NewClockUtils(),
+		listeners:        newListenerRegistry(),
 		redisPersistence: redisPersistence,
+		repeatWindow:     DefaultRepeatWindow,
+		autoStartNext:    true,
+		unwatchedGrace:   DefaultUnwatchedGrace,
+
+		redisHealthCheckInterval: DefaultRedisHealthCheckInterval,
+		resumePolicy:             ResumeAuto,
+		resumeGracePeriod:        DefaultResumeGracePeriod,
+		duplicateRecordWindow:    DefaultDuplicateRecordWindow,
+	}
+	cr.applyOptions(opts)
+
+	// Keep persistence alive across Redis hiccups and report when it is lost
+	redisPersistence.SetHealthCallback(logPersistenceHealth)
+	if err := redisPersistence.StartHealthCheck(cr.redisHealthCheckInterval); err != nil {
+		slog.Warn("invalid Redis health check interval, using default", "error", err)
+		redisPersistence.StartHealthCheck(DefaultRedisHealthCheckInterval)
 	}
 
 	// Initialize manager components
@@ -90,27 +185,199 @@ func NewClockRunnerWithRedis(redisAddr string) (*ClockRunner, error) {
 
 	// Load settings from Redis
 	if err := cr.persistenceManager.LoadSettingsFromRedis(); err != nil {
-		log.Printf("Warning: failed to load settings from Redis: %v", err)
+		slog.Warn("failed to load settings from Redis", "error", err)
+	}
+
+	// Load statistics from Redis
+	if err := cr.persistenceManager.LoadStatisticsFromRedis(); err != nil {
+		slog.Warn("failed to load statistics from Redis", "error", err)
+	}
+
+	// Load the last recorded session so resuming does not record it twice
+	if err := cr.persistenceManager.LoadLastRecordedSessionFromRedis(); err != nil {
+		slog.Warn("failed to load last recorded session from Redis", "error", err)
 	}
 
 	// Load and resume system state from Redis
 	if err := cr.resumeManager.ResumeFromRedis(); err != nil {
-		log.Printf("Warning: failed to resume state from Redis: %v", err)
+		slog.Warn("failed to resume state from Redis", "error", err)
 	}
 
 	return cr, nil
 }
 
 // SetDurations configures the clock runner with custom durations
-func (cr *ClockRunner) SetDurations(work, shortBreak, longBreak time.Duration) {
-	cr.sessionManager.SetDurations(work, shortBreak, longBreak)
+func (cr *ClockRunner) SetDurations(work, shortBreak, longBreak time.Duration) error {
+	if err := cr.sessionManager.SetDurations(work, shortBreak, longBreak); err != nil {
+		return err
+	}
+
+	// Save settings to Redis
+	if cr.redisPersistence != nil {
+		if err := cr.persistenceManager.SaveSettingsToRedis(); err != nil {
+			slog.Error("failed to save settings to Redis", "error", err)
+		}
+	}
+	return nil
+}
+
+// SetCustomBreakDuration sets the default duration of custom break (CB) sessions
+func (cr *ClockRunner) SetCustomBreakDuration(d time.Duration) error {
+	if err := cr.sessionManager.SetCustomBreakDuration(d); err != nil {
+		return err
+	}
 
 	// Save settings to Redis
 	if cr.redisPersistence != nil {
 		if err := cr.persistenceManager.SaveSettingsToRedis(); err != nil {
-			log.Printf("Failed to save settings to Redis: %v", err)
+			slog.Error("failed to save settings to Redis", "error", err)
 		}
 	}
+	return nil
+}
+
+// GetCustomBreakDuration returns the default duration of custom break sessions
+func (cr *ClockRunner) GetCustomBreakDuration() time.Duration {
+	return cr.sessionManager.GetCustomBreakDuration()
+}
+
+// MaxIdleTimeout is the longest idle timeout accepted by SetIdleTimeout
+const MaxIdleTimeout = 24 * time.Hour
+
+// SetIdleTimeout sets how long a session may stay paused before the clock stops
+// itself and returns to idle. Zero disables the timeout. The new value applies
+// from the next pause.
+func (cr *ClockRunner) SetIdleTimeout(d time.Duration) error {
+	if d < 0 || d > MaxIdleTimeout {
+		return fmt.Errorf("idle timeout must be between 0 and %v, got %v", MaxIdleTimeout, d)
+	}
+
+	cr.mu.Lock()
+	cr.idleTimeout = d
+	cr.mu.Unlock()
+
+	// Save settings to Redis
+	if cr.redisPersistence != nil {
+		if err := cr.persistenceManager.SaveSettingsToRedis(); err != nil {
+			slog.Error("failed to save settings to Redis", "error", err)
+		}
+	}
+	return nil
+}
+
+// GetIdleTimeout returns how long a session may stay paused before the clock stops
+func (cr *ClockRunner) GetIdleTimeout() time.Duration {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	return cr.idleTimeout
+}
+
+// SetAutoStartNext sets whether the next session starts as soon as one
+// completes. When off, the clock moves to the next session and waits paused
+// with its full duration until Start is called. Enabled by default.
+func (cr *ClockRunner) SetAutoStartNext(enabled bool) {
+	cr.mu.Lock()
+	cr.autoStartNext = enabled
+	cr.mu.Unlock()
+
+	// Save settings to Redis
+	if cr.redisPersistence != nil {
+		if err := cr.persistenceManager.SaveSettingsToRedis(); err != nil {
+			slog.Error("failed to save settings to Redis", "error", err)
+		}
+	}
+}
+
+// GetAutoStartNext reports whether the next session starts as soon as one completes
+func (cr *ClockRunner) GetAutoStartNext() bool {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	return cr.autoStartNext
+}
+
+const (
+	// DefaultRepeatWindow is how long after a session completes it may be repeated
+	DefaultRepeatWindow = 2 * time.Minute
+	// MaxRepeatWindow is the longest repeat window accepted by SetRepeatWindow
+	MaxRepeatWindow = time.Hour
+)
+
+// SetRepeatWindow sets how long after a session completes RepeatLastSession
+// may run it again. Zero disables repeating.
+func (cr *ClockRunner) SetRepeatWindow(d time.Duration) error {
+	if d < 0 || d > MaxRepeatWindow {
+		return fmt.Errorf("repeat window must be between 0 and %v, got %v", MaxRepeatWindow, d)
+	}
+
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	cr.repeatWindow = d
+	return nil
+}
+
+// GetRepeatWindow returns how long after a session completes it may be repeated
+func (cr *ClockRunner) GetRepeatWindow() time.Duration {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	return cr.repeatWindow
+}
+
+// armIdleTimerLocked starts the idle timeout for a paused session; the caller must hold cr.mu
+func (cr *ClockRunner) armIdleTimerLocked() {
+	cr.disarmIdleTimerLocked()
+	if cr.idleTimeout <= 0 {
+		return
+	}
+
+	generation := cr.idleGeneration
+	cr.idleTimer = time.AfterFunc(cr.idleTimeout, func() {
+		cr.expireIdlePause(generation)
+	})
+}
+
+// disarmIdleTimerLocked cancels a pending idle timeout; the caller must hold cr.mu
+func (cr *ClockRunner) disarmIdleTimerLocked() {
+	if cr.idleTimer != nil {
+		cr.idleTimer.Stop()
+		cr.idleTimer = nil
+	}
+	cr.idleGeneration++
+}
+
+// expireIdlePause stops a session that stayed paused past the idle timeout.
+// Nothing is recorded in the statistics.
+func (cr *ClockRunner) expireIdlePause(generation int) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	// The timer was disarmed or re-armed after it fired
+	if generation != cr.idleGeneration || !cr.stateManager.IsPaused() {
+		return
+	}
+	cr.idleTimer = nil
+
+	slog.Info("paused session timed out", "idle_timeout", cr.idleTimeout, "session", cr.sessionManager.GetCurrentSession())
+	cr.resetToIdleLocked()
+}
+
+// SetCompletionWebhook configures a URL that receives a POST whenever a session completes.
+// An empty URL disables the webhook.
+func (cr *ClockRunner) SetCompletionWebhook(url string) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	if url == "" {
+		cr.webhookNotifier = nil
+		return
+	}
+	cr.webhookNotifier = NewWebhookNotifier(url)
+}
+
+// getWebhookNotifier returns the configured webhook notifier, if any
+func (cr *ClockRunner) getWebhookNotifier() *WebhookNotifier {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	return cr.webhookNotifier
 }
 
 // SetCallbacks sets the callback functions for state changes and ticks
@@ -128,71 +395,200 @@ func (cr *ClockRunner) SetCallbacks(
 	cr.onComplete = onComplete
 }
 
-// Start begins the pomodoro session
-func (cr *ClockRunner) Start() error {
+// StartResult is a snapshot of the clock captured under the same lock as the
+// Start, Pause, Stop, or Skip transition that produced it
+type StartResult struct {
+	State         ClockState
+	Session       int
+	TimeRemaining time.Duration
+	EndTime       time.Time // zero unless a session is counting down
+}
+
+// Snapshot returns the current state of the clock
+func (cr *ClockRunner) Snapshot() StartResult {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	return cr.snapshot()
+}
+
+// snapshot captures the current clock state from the component managers. Callers
+// holding cr.mu get a snapshot consistent with the transition they just made.
+func (cr *ClockRunner) snapshot() StartResult {
+	result := StartResult{
+		State:         cr.stateManager.GetState(),
+		Session:       cr.sessionManager.GetCurrentSession(),
+		TimeRemaining: cr.timerManager.GetTimeRemaining(),
+	}
+	if cr.stateManager.IsRunning() {
+		result.EndTime = time.Now().Add(result.TimeRemaining)
+	}
+	return result
+}
+
+var (
+	// ErrNotStartable is returned by Start when the clock is already running
+	// or a session is completing, and by StartAt when the clock is not idle
+	ErrNotStartable = errors.New("clock is not in a startable state")
+	// ErrNotRunning is returned by Pause and Skip when no session is running
+	ErrNotRunning = errors.New("clock is not running")
+	// ErrAlreadyIdle is returned by Stop when the clock is idle
+	ErrAlreadyIdle = errors.New("clock is already idle")
+)
+
+// Start begins the pomodoro session and returns the resulting state
+func (cr *ClockRunner) Start() (StartResult, error) {
 	cr.mu.Lock()
 	defer cr.mu.Unlock()
 
-	log.Printf("🚀 Start() called - Current state: %s, CanStart: %v", cr.GetState(), cr.stateManager.CanStart())
+	if err := cr.startLocked(); err != nil {
+		return StartResult{}, err
+	}
+	return cr.snapshot(), nil
+}
+
+// StartAt begins a new cycle at the given 0-based index into the schedule
+// instead of the first session, e.g. to pick up the day at the third work block.
+// The clock must be idle; use Start to resume a paused session.
+func (cr *ClockRunner) StartAt(session int) (StartResult, error) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	if total := cr.sessionManager.GetTotalSessions(); session < 0 || session >= total {
+		return StartResult{}, fmt.Errorf("cannot start at session %d: schedule has %d sessions", session, total)
+	}
+	if !cr.stateManager.IsIdle() {
+		return StartResult{}, fmt.Errorf("cannot start at session %d: %w", session, ErrNotStartable)
+	}
+	cr.disarmIdleTimerLocked()
+
+	cr.startCycleLocked(session)
+	cr.saveStateToRedis()
+
+	slog.Info("clock started", "state", cr.GetState(), "session", cr.GetCurrentSession())
+	return cr.snapshot(), nil
+}
+
+// StartIdempotent starts the pomodoro session, treating a clock that is already
+// running as success rather than an error. It reports whether the clock was
+// already running. Invalid transitions, such as starting while a session is
+// completing, still return an error.
+func (cr *ClockRunner) StartIdempotent() (StartResult, bool, error) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	if cr.stateManager.IsRunning() && cr.timerManager.IsRunning() && !cr.timerManager.IsCompleting() {
+		slog.Info("start ignored, clock already running", "state", cr.GetState())
+		return cr.snapshot(), true, nil
+	}
+
+	if err := cr.startLocked(); err != nil {
+		return StartResult{}, false, err
+	}
+	return cr.snapshot(), false, nil
+}
+
+// startLocked performs the start transition; the caller must hold cr.mu
+func (cr *ClockRunner) startLocked() error {
+	slog.Debug("start requested", "state", cr.GetState(), "can_start", cr.stateManager.CanStart())
 
 	if !cr.stateManager.CanStart() {
-		return fmt.Errorf("cannot start: clock is not in a startable state")
+		return fmt.Errorf("cannot start: %w", ErrNotStartable)
 	}
+	cr.disarmIdleTimerLocked()
 
 	if cr.stateManager.IsIdle() {
-		log.Printf("Starting new session from idle state")
-		cr.sessionManager.ResetSessions()
-		cr.startNewSession()
-		// Start periodic Redis saves when starting a new session
-		cr.runSaveStateToRedis()
-	} else if cr.stateManager.IsPaused() {
-		log.Printf("Resuming from paused state")
-		// Resume from pause
+		cr.startCycleLocked(0)
+	} else {
+		// Resume from pause, or begin the session prepared while waiting
+		if cr.stateManager.IsWaiting() {
+			slog.Info("beginning waiting session", "session", cr.sessionManager.GetCurrentSession())
+		} else {
+			slog.Info("resuming from pause", "session", cr.sessionManager.GetCurrentSession())
+		}
 		state := cr.sessionManager.GetCurrentSessionState()
 		cr.stateManager.SetState(state)
 		cr.timerManager.ResumeTimer()
 		// Start periodic Redis saves when resuming
 		cr.runSaveStateToRedis()
+		cr.publish(EventStateChange)
 	}
 
 	// Save state to Redis
 	cr.saveStateToRedis()
 
-	log.Printf("✅ Start() completed - Final state: %s, Session: %d", cr.GetState(), cr.GetCurrentSession())
+	slog.Info("clock started", "state", cr.GetState(), "session", cr.GetCurrentSession())
 	return nil
 }
 
-// Pause pauses the current session
-func (cr *ClockRunner) Pause() error {
+// startCycleLocked starts a new cycle from idle at the given session; the
+// caller must hold cr.mu and have validated the index
+func (cr *ClockRunner) startCycleLocked(session int) {
+	slog.Info("starting new cycle from idle", "session", session)
+	cr.sessionManager.ResetSessions()
+	cr.sessionManager.SetCurrentSession(session)
+	cr.startNewSession()
+	// Start periodic Redis saves when starting a new session
+	cr.runSaveStateToRedis()
+}
+
+// MaxPauseReasonLength is the longest pause reason accepted, in characters
+const MaxPauseReasonLength = 100
+
+// ErrPauseReasonTooLong is returned for a pause reason longer than MaxPauseReasonLength
+var ErrPauseReasonTooLong = errors.New("pause reason too long")
+
+// Pause pauses the current session and returns the resulting state
+func (cr *ClockRunner) Pause() (StartResult, error) {
+	return cr.PauseWithReason("")
+}
+
+// PauseWithReason pauses the current session, recording why, e.g. "lunch" or
+// "interruption". The reason is reported while the session stays paused and
+// counted in the pause statistics; an empty reason records none.
+func (cr *ClockRunner) PauseWithReason(reason string) (StartResult, error) {
+	reason = strings.TrimSpace(reason)
+	if utf8.RuneCountInString(reason) > MaxPauseReasonLength {
+		return StartResult{}, fmt.Errorf("%w: at most %d characters", ErrPauseReasonTooLong, MaxPauseReasonLength)
+	}
+
 	cr.mu.Lock()
 	defer cr.mu.Unlock()
 
 	if !cr.stateManager.CanPause() {
-		return fmt.Errorf("cannot pause: clock is not running")
+		return StartResult{}, fmt.Errorf("cannot pause: %w", ErrNotRunning)
 	}
 
+	cr.stateManager.SetPauseReason(reason)
 	cr.stateManager.SetState(StatePaused)
 	cr.timerManager.PauseTimer()
+	cr.armIdleTimerLocked()
+	cr.statsManager.RecordPause(reason)
 
 	// Save state to Redis
 	cr.saveStateToRedis()
 
-	if cr.onStateChange != nil {
-		cr.onStateChange(StatePaused)
-	}
+	notifyStateChange(cr, StatePaused)
 
-	return nil
+	return cr.snapshot(), nil
 }
 
-// Stop stops the current session and resets to idle
-func (cr *ClockRunner) Stop() error {
+// Stop stops the current session, resets to idle, and returns the resulting state
+func (cr *ClockRunner) Stop() (StartResult, error) {
 	cr.mu.Lock()
 	defer cr.mu.Unlock()
 
 	if !cr.stateManager.CanStop() {
-		return fmt.Errorf("cannot stop: clock is already idle")
+		return StartResult{}, fmt.Errorf("cannot stop: %w", ErrAlreadyIdle)
 	}
 
+	cr.resetToIdleLocked()
+	return cr.snapshot(), nil
+}
+
+// resetToIdleLocked stops the current session and returns to idle without
+// recording it; the caller must hold cr.mu
+func (cr *ClockRunner) resetToIdleLocked() {
+	cr.disarmIdleTimerLocked()
 	cr.stateManager.SetState(StateIdle)
 	cr.sessionManager.ResetSessions()
 	cr.timerManager.StopTimer()
@@ -200,41 +596,45 @@ func (cr *ClockRunner) Stop() error {
 	// Stop periodic Redis saves
 	cr.stopSaveStateToRedis()
 
-	// Save state to Redis
-	if cr.redisPersistence != nil {
-		cr.saveStateToRedis()
-	}
-
-	if cr.onStateChange != nil {
-		cr.onStateChange(StateIdle)
-	}
+	// Save state to Redis right away rather than coalescing it
+	cr.flushStateToRedis()
 
-	return nil
+	notifyStateChange(cr, StateIdle)
 }
 
-// Skip skips the current session and moves to the next one
-func (cr *ClockRunner) Skip() error {
+// Skip skips the current session, moves to the next one, and returns the resulting state
+func (cr *ClockRunner) Skip() (StartResult, error) {
 	cr.mu.Lock()
 	defer cr.mu.Unlock()
 
 	if !cr.stateManager.CanSkip() {
-		return fmt.Errorf("cannot skip: clock is not running")
+		return StartResult{}, fmt.Errorf("cannot skip: %w", ErrNotRunning)
 	}
+	cr.disarmIdleTimerLocked()
 
 	// Stop the current timer
 	cr.timerManager.StopTimer()
 
+	// A waiting session was never begun, so it is skipped as its scheduled state
+	skippedState := cr.stateManager.GetState()
+	if skippedState == StateWaiting {
+		skippedState = cr.sessionManager.GetCurrentSessionState()
+	}
+
 	// Call completion callback
 	if cr.onComplete != nil {
-		cr.onComplete(cr.stateManager.GetState())
+		cr.onComplete(skippedState)
 	}
 
-	// Record the skipped session
+	// Record the skipped session like a completed one, except that it cannot
+	// be repeated
 	duration := cr.sessionManager.GetCurrentSessionDuration()
-	cr.statsManager.RecordSession(cr.stateManager.GetState(), duration)
+	saveSessionStats(cr, cr.sessionManager.GetCurrentSession(), currentSessionRecord(cr, skippedState, duration))
 
-	log.Printf("Skipped %s session %d/%d",
-		cr.stateManager.GetState(), cr.sessionManager.GetCurrentSession(), cr.sessionManager.GetTotalSessions())
+	slog.Info("session skipped",
+		"state", skippedState,
+		"session", cr.sessionManager.GetCurrentSession(),
+		"total_sessions", cr.sessionManager.GetTotalSessions())
 
 	// Move to next session
 	if !cr.sessionManager.NextSession() {
@@ -244,15 +644,100 @@ func (cr *ClockRunner) Skip() error {
 		// Save state to Redis
 		cr.saveStateToRedis()
 
-		if cr.onStateChange != nil {
-			cr.onStateChange(StateIdle)
-		}
-		log.Println("Completed all pomodoro sessions!")
-		return nil
+		notifyStateChange(cr, StateIdle)
+		slog.Info("completed all pomodoro sessions")
+		return cr.snapshot(), nil
 	}
 
 	cr.startNewSession()
-	return nil
+	return cr.snapshot(), nil
+}
+
+// completedSession identifies a session that ran to completion
+type completedSession struct {
+	session   int
+	state     ClockState
+	completed time.Time
+}
+
+// ErrNothingToRepeat is returned by RepeatLastSession when no session completed
+// within the repeat window
+var ErrNothingToRepeat = errors.New("no recently completed session to repeat")
+
+// setLastCompleted remembers a session that ran to completion for RepeatLastSession
+func (cr *ClockRunner) setLastCompleted(session int, state ClockState, completed time.Time) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	cr.lastCompleted = &completedSession{session: session, state: state, completed: completed}
+}
+
+// RepeatLastSession runs the session that just completed once more, e.g. one
+// more work block after a productive one. The session that followed it is
+// abandoned without being recorded, and the schedule picks up there again once
+// the repeat completes. Only a session that ran to completion within the repeat
+// window can be repeated, and only once; skipped sessions cannot.
+func (cr *ClockRunner) RepeatLastSession() (StartResult, error) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	last := cr.lastCompleted
+	if last == nil || cr.repeatWindow == 0 {
+		return StartResult{}, ErrNothingToRepeat
+	}
+	if since := time.Since(last.completed); since > cr.repeatWindow {
+		return StartResult{}, fmt.Errorf("%w: the last session completed %v ago, past the %v window",
+			ErrNothingToRepeat, since.Round(time.Second), cr.repeatWindow)
+	}
+	// The schedule may have been replaced since
+	if state, _, _, _ := cr.sessionManager.GetSessionInfoAt(last.session); state != last.state {
+		return StartResult{}, fmt.Errorf("%w: the schedule has changed", ErrNothingToRepeat)
+	}
+	if cr.timerManager.IsCompleting() {
+		return StartResult{}, fmt.Errorf("cannot repeat: %w", ErrTimerCompleting)
+	}
+
+	cr.lastCompleted = nil
+	cr.disarmIdleTimerLocked()
+	slog.Info("repeating last session", "state", last.state, "session", last.session)
+
+	cr.sessionManager.SetCurrentSession(last.session)
+	cr.startNewSession()
+	cr.runSaveStateToRedis()
+	return cr.snapshot(), nil
+}
+
+// ErrNoPreviousSession is returned by Back on the first session of the cycle
+var ErrNoPreviousSession = errors.New("already at the first session")
+
+// Back abandons the current session and starts the previous one from the
+// beginning, running even if the clock was paused. The abandoned session is
+// not recorded, unlike with Skip, and the previous one is recorded again only
+// once it completes.
+func (cr *ClockRunner) Back() (StartResult, error) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	if cr.stateManager.IsIdle() {
+		return StartResult{}, fmt.Errorf("cannot go back: %w", ErrNoActiveSession)
+	}
+	if cr.timerManager.IsCompleting() {
+		return StartResult{}, fmt.Errorf("cannot go back: %w", ErrTimerCompleting)
+	}
+	if cr.sessionManager.GetCurrentSession() == 0 {
+		return StartResult{}, ErrNoPreviousSession
+	}
+	cr.disarmIdleTimerLocked()
+
+	cr.timerManager.StopTimer()
+	cr.sessionManager.PreviousSession()
+	slog.Info("went back to previous session",
+		"session", cr.sessionManager.GetCurrentSession(),
+		"state", cr.sessionManager.GetCurrentSessionState())
+
+	cr.startNewSession()
+	// Periodic saves stop while paused
+	cr.runSaveStateToRedis()
+	return cr.snapshot(), nil
 }
 
 // startNewSession starts a new session
@@ -264,6 +749,25 @@ func (cr *ClockRunner) startNewSession() {
 
 	// Reset tick counter for new session (no longer needed with new approach)
 
+	cr.startSessionTimer(state, duration)
+
+	// Log session start
+	state, sessionNum, totalSessions, _ := cr.sessionManager.GetSessionInfo()
+	slog.Info("session started",
+		"state", state,
+		"session", sessionNum,
+		"total_sessions", totalSessions,
+		"remaining_ms", duration.Milliseconds())
+
+	notifyStateChange(cr, state)
+
+	// Save state to Redis
+	cr.saveStateToRedis()
+}
+
+// startSessionTimer starts the timer for the current session with the
+// completion and tick callbacks
+func (cr *ClockRunner) startSessionTimer(state ClockState, duration time.Duration) {
 	// Set up timer callbacks
 	onTick := func(remaining time.Duration) {
 		onTick(cr, remaining)
@@ -275,26 +779,25 @@ func (cr *ClockRunner) startNewSession() {
 
 	// Start the timer
 	cr.timerManager.StartTimer(duration, state, onTick, onComplete)
-
-	// Log session start
-	state, sessionNum, totalSessions, _ := cr.sessionManager.GetSessionInfo()
-	log.Printf("Started %s session %d/%d (duration: %v)",
-		state, sessionNum, totalSessions, duration)
-
-	if cr.onStateChange != nil {
-		cr.onStateChange(state)
-	}
-
-	// Save state to Redis
-	cr.saveStateToRedis()
 }
 
 // Close closes the Redis connection
 func (cr *ClockRunner) Close() error {
+	cr.closeOnce.Do(func() {
+		cr.closeErr = cr.close()
+	})
+	return cr.closeErr
+}
+
+func (cr *ClockRunner) close() error {
 	// Stop periodic Redis saves
 	cr.stopSaveStateToRedis()
 
 	if cr.persistenceManager != nil {
+		// Let a coalesced save land before the connection goes
+		if cr.persistenceManager.hasPendingSave() {
+			cr.flushStateToRedis()
+		}
 		return cr.persistenceManager.Close()
 	}
 	return nil
@@ -332,7 +835,7 @@ func (cr *ClockRunner) IsRunning() bool {
 	stateRunning := cr.stateManager.IsRunning()
 	timerRunning := cr.timerManager.IsRunning()
 	if stateRunning != timerRunning {
-		log.Print("Inconsistency detected: stateRunning != timerRunning, stateRunning: ", stateRunning, " timerRunning: ", timerRunning)
+		slog.Debug("state and timer disagree", "state_running", stateRunning, "timer_running", timerRunning)
 	}
 	return stateRunning && timerRunning
 }
@@ -347,19 +850,26 @@ func (cr *ClockRunner) IsIdle() bool {
 	return cr.stateManager.IsIdle()
 }
 
+// GetStatisticsSnapshot returns a consistent snapshot of all session statistics
+func (cr *ClockRunner) GetStatisticsSnapshot() StatisticsSnapshot {
+	return cr.statsManager.Snapshot()
+}
+
 // GetStatistics returns the session statistics
 func (cr *ClockRunner) GetStatistics() (int, int, int) {
-	return cr.statsManager.GetStatistics()
+	snapshot := cr.statsManager.Snapshot()
+	return snapshot.WorkSessions, snapshot.ShortBreaks, snapshot.LongBreaks
 }
 
 // GetTimingStatistics returns timing statistics
 func (cr *ClockRunner) GetTimingStatistics() (time.Duration, time.Duration, time.Duration) {
-	return cr.statsManager.GetTimingStatistics()
+	snapshot := cr.statsManager.Snapshot()
+	return snapshot.WorkTime, snapshot.BreakTime, snapshot.TotalTime
 }
 
 // GetSessionHistory returns the session history
 func (cr *ClockRunner) GetSessionHistory() []SessionRecord {
-	return cr.statsManager.GetSessionHistory()
+	return cr.statsManager.Snapshot().History
 }
 
 // GetRecentSessions returns the most recent N sessions
@@ -367,16 +877,37 @@ func (cr *ClockRunner) GetRecentSessions(count int) []SessionRecord {
 	return cr.statsManager.GetRecentSessions(count)
 }
 
+// GetSessionsByState returns the completed sessions of one state, oldest first
+func (cr *ClockRunner) GetSessionsByState(state ClockState) []SessionRecord {
+	return cr.statsManager.GetSessionsByState(state)
+}
+
 // GetTodaySessions returns sessions completed today
 func (cr *ClockRunner) GetTodaySessions() []SessionRecord {
 	return cr.statsManager.GetTodaySessions()
 }
 
+// GetTodayFocusTime returns the total duration of work sessions completed today
+func (cr *ClockRunner) GetTodayFocusTime() time.Duration {
+	return cr.statsManager.GetTodayFocusTime()
+}
+
 // GetWeeklyStats returns statistics for the current week
 func (cr *ClockRunner) GetWeeklyStats() (int, int, int, time.Duration, time.Duration) {
 	return cr.statsManager.GetWeeklyStats()
 }
 
+// GetStatsForRange aggregates the sessions completed in [start, end); see
+// StatisticsManager.GetStatsForRange
+func (cr *ClockRunner) GetStatsForRange(start, end time.Time) (StatisticsSnapshot, error) {
+	return cr.statsManager.GetStatsForRange(start, end)
+}
+
+// GetDurationDistribution returns completed work sessions bucketed by duration
+func (cr *ClockRunner) GetDurationDistribution() []DurationBucket {
+	return cr.statsManager.GetDurationDistribution()
+}
+
 // GetAverageSessionDuration returns the average duration of completed sessions
 func (cr *ClockRunner) GetAverageSessionDuration() time.Duration {
 	return cr.statsManager.GetAverageSessionDuration()
@@ -387,31 +918,300 @@ func (cr *ClockRunner) GetProductivityScore() float64 {
 	return cr.statsManager.GetProductivityScore()
 }
 
-// ResetStatistics resets all statistics
-func (cr *ClockRunner) ResetStatistics() {
+// SetLocation sets the time zone the clock runs in. It determines "today" and
+// "this week" for statistics and the zone reported alongside the system state.
+func (cr *ClockRunner) SetLocation(loc *time.Location) {
+	cr.statsManager.SetLocation(loc)
+}
+
+// GetLocation returns the time zone the clock runs in
+func (cr *ClockRunner) GetLocation() *time.Location {
+	return cr.statsManager.GetLocation()
+}
+
+// MaxSessionTagLength is the longest session tag accepted, in characters
+const MaxSessionTagLength = 100
+
+var (
+	// ErrNoActiveSession is returned when tagging or adjusting the time while the clock is idle
+	ErrNoActiveSession = errors.New("no active session")
+	// ErrTagTooLong is returned for a tag longer than MaxSessionTagLength
+	ErrTagTooLong = errors.New("tag too long")
+)
+
+// SetSessionTag labels the current session, e.g. with the task being worked on.
+// The tag is recorded with the session when it completes or is skipped and is
+// cleared when the next session begins; an empty tag clears it.
+func (cr *ClockRunner) SetSessionTag(tag string) error {
+	tag = strings.TrimSpace(tag)
+	if utf8.RuneCountInString(tag) > MaxSessionTagLength {
+		return fmt.Errorf("%w: at most %d characters", ErrTagTooLong, MaxSessionTagLength)
+	}
+
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	if cr.stateManager.IsIdle() {
+		return fmt.Errorf("cannot tag session: %w", ErrNoActiveSession)
+	}
+
+	cr.sessionManager.SetCurrentTag(tag)
+	cr.saveStateToRedis()
+	return nil
+}
+
+// AdjustTime adds delta, which may be negative, to the time remaining in the
+// current session, running or paused, and saves the new end time. The remaining
+// time is clamped to [0, MaxSessionDuration]; removing all of it from a running
+// session completes the session.
+func (cr *ClockRunner) AdjustTime(delta time.Duration) (StartResult, error) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	if cr.stateManager.IsIdle() {
+		return StartResult{}, fmt.Errorf("cannot adjust time: %w", ErrNoActiveSession)
+	}
+
+	remaining, err := cr.timerManager.AdjustRemaining(delta)
+	if err != nil {
+		return StartResult{}, fmt.Errorf("cannot adjust time: %w", err)
+	}
+	slog.Info("session time adjusted",
+		"session", cr.sessionManager.GetCurrentSession(),
+		"delta", delta,
+		"remaining_ms", remaining.Milliseconds())
+
+	cr.saveStateToRedis()
+	cr.publish(EventTick)
+	return cr.snapshot(), nil
+}
+
+// GetPauseReason returns why the clock was paused, or "" when it is not paused
+func (cr *ClockRunner) GetPauseReason() string {
+	return cr.stateManager.GetPauseReason()
+}
+
+// GetSessionTag returns the tag of the current session, if any
+func (cr *ClockRunner) GetSessionTag() string {
+	return cr.sessionManager.GetCurrentTag()
+}
+
+// SetTickInterval sets how often the tick callback fires (1ms to 1s, default 100ms).
+// A running session keeps its current ticker; the new interval applies from the
+// next start or resume.
+func (cr *ClockRunner) SetTickInterval(d time.Duration) error {
+	return cr.timerManager.SetTickInterval(d)
+}
+
+// GetTickInterval returns how often the tick callback fires
+func (cr *ClockRunner) GetTickInterval() time.Duration {
+	return cr.timerManager.GetTickInterval()
+}
+
+// SetDayStart sets the offset from midnight at which a new statistics day begins
+func (cr *ClockRunner) SetDayStart(offset time.Duration) error {
+	return cr.statsManager.SetDayStart(offset)
+}
+
+// SetDailyWorkGoal sets how many work sessions to complete each day, up to
+// MaxDailyWorkGoal; 0 removes the goal. Subscribers receive an EventGoalReached
+// the first time each day the goal is met.
+func (cr *ClockRunner) SetDailyWorkGoal(goal int) error {
+	if err := cr.statsManager.SetDailyWorkGoal(goal); err != nil {
+		return err
+	}
+
+	// Save settings to Redis
+	if cr.redisPersistence != nil {
+		if err := cr.persistenceManager.SaveSettingsToRedis(); err != nil {
+			slog.Error("failed to save settings to Redis", "error", err)
+		}
+	}
+	return nil
+}
+
+// GetDailyWorkGoal returns how many work sessions to complete each day, 0 when unset
+func (cr *ClockRunner) GetDailyWorkGoal() int {
+	return cr.statsManager.GetDailyWorkGoal()
+}
+
+// GetDailyGoalProgress returns the work sessions completed today against the daily goal
+func (cr *ClockRunner) GetDailyGoalProgress() DailyGoalProgress {
+	return cr.statsManager.GetDailyGoalProgress()
+}
+
+// ImportSessions adds previously completed sessions to the statistics; see
+// StatisticsManager.ImportSessions. With Redis configured they are also saved
+// to the daily session statistics keys, so they survive a restart.
+func (cr *ClockRunner) ImportSessions(records []SessionRecord) error {
+	if err := cr.statsManager.ImportSessions(records); err != nil {
+		return err
+	}
+
+	if cr.redisPersistence == nil {
+		return nil
+	}
+	for _, record := range records {
+		if err := cr.redisPersistence.SaveSessionRecord(record); err != nil {
+			return fmt.Errorf("imported sessions were not all saved to Redis: %w", err)
+		}
+	}
+	return nil
+}
+
+// ResetStatistics resets all statistics. It refuses to reset while a session is
+// completing so the in-flight record is not lost.
+func (cr *ClockRunner) ResetStatistics() error {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	if cr.timerManager.IsCompleting() {
+		return fmt.Errorf("cannot reset statistics: a session is completing")
+	}
+
 	cr.statsManager.ResetStatistics()
+	return nil
 }
 
 // SetSchedule sets a custom session schedule
 func (cr *ClockRunner) SetSchedule(schedule []ClockState) error {
-	return cr.sessionManager.SetSchedule(schedule)
+	specs := make([]SessionSpec, len(schedule))
+	for i, state := range schedule {
+		specs[i] = SessionSpec{State: state}
+	}
+	return cr.SetScheduleSpecs(specs)
 }
 
-// GetSchedule returns the current schedule
+// SetScheduleSpecs sets a custom session schedule with optional per-session durations
+func (cr *ClockRunner) SetScheduleSpecs(specs []SessionSpec) error {
+	if err := cr.sessionManager.SetScheduleSpecs(specs); err != nil {
+		return err
+	}
+
+	// Save settings to Redis, so the per-session durations survive a restart
+	if cr.redisPersistence != nil {
+		if err := cr.persistenceManager.SaveSettingsToRedis(); err != nil {
+			slog.Error("failed to save settings to Redis", "error", err)
+		}
+	}
+	return nil
+}
+
+// GetSchedule returns the current schedule; the slice is shared and must not be modified
 func (cr *ClockRunner) GetSchedule() []ClockState {
 	return cr.sessionManager.GetSchedule()
 }
 
+// GetScheduleSpecs returns the current schedule including per-session durations
+func (cr *ClockRunner) GetScheduleSpecs() []SessionSpec {
+	return cr.sessionManager.GetScheduleSpecs()
+}
+
+// GetSessionInfoAt returns the state and duration of the session at the given index
+func (cr *ClockRunner) GetSessionInfoAt(session int) (state ClockState, sessionNum int, totalSessions int, duration time.Duration) {
+	return cr.sessionManager.GetSessionInfoAt(session)
+}
+
+// GetSessionInfo returns the state, 0-based index and duration of the current
+// session in the schedule, along with the schedule length
+func (cr *ClockRunner) GetSessionInfo() (state ClockState, sessionNum int, totalSessions int, duration time.Duration) {
+	return cr.sessionManager.GetSessionInfo()
+}
+
+// FormatSessionInfo returns a display label for the current session such as
+// "Work Session 2/8 (25:00)", numbering sessions from 1. While idle, paused or
+// waiting the label describes the clock instead, e.g. "Session Paused".
+func (cr *ClockRunner) FormatSessionInfo() string {
+	state, sessionNum, totalSessions, duration := cr.sessionManager.GetSessionInfo()
+	if clockState := cr.stateManager.GetState(); clockState == StateIdle || clockState == StatePaused || clockState == StateWaiting {
+		state = clockState
+	}
+	return cr.utils.FormatSessionInfo(state, sessionNum+1, totalSessions, duration)
+}
+
+// CycleProgress describes how far the clock is through the whole schedule
+type CycleProgress struct {
+	Total   time.Duration
+	Elapsed time.Duration
+	Percent float64
+}
+
+// GetCycleProgress returns the total schedule duration and the time elapsed in
+// the current cycle: completed sessions plus the elapsed part of the current one.
+// While paused the elapsed time stays frozen; while idle it is zero.
+func (cr *ClockRunner) GetCycleProgress() CycleProgress {
+	total, completed := cr.sessionManager.GetCycleDurations()
+
+	progress := CycleProgress{Total: total}
+	if cr.stateManager.IsIdle() {
+		return progress
+	}
+
+	current := cr.sessionManager.GetCurrentSessionDuration() - cr.timerManager.GetTimeRemaining()
+	if current < 0 {
+		current = 0
+	}
+
+	progress.Elapsed = completed + current
+	progress.Percent = cr.utils.CalculateSessionProgress(progress.Elapsed, total)
+	return progress
+}
+
+// SessionProgress is how far the current session has run
+type SessionProgress struct {
+	Total   time.Duration
+	Elapsed time.Duration
+	Percent float64
+}
+
+// GetSessionProgress returns the elapsed part of the current session and its
+// full length, adjustments included. While paused the elapsed time stays
+// frozen; while idle everything is zero.
+func (cr *ClockRunner) GetSessionProgress() SessionProgress {
+	if cr.stateManager.IsIdle() {
+		return SessionProgress{}
+	}
+
+	elapsed, remaining := cr.timerManager.GetSessionTiming()
+	total := elapsed + remaining
+	return SessionProgress{
+		Total:   total,
+		Elapsed: elapsed,
+		Percent: cr.utils.CalculateSessionProgress(elapsed, total),
+	}
+}
+
 // GetDurations returns the current durations in minutes
 func (cr *ClockRunner) GetDurations() (workMinutes, shortBreakMinutes, longBreakMinutes int) {
 	return cr.sessionManager.GetDurations()
 }
 
+// GetRecommendedDurations returns the named duration presets
+func (cr *ClockRunner) GetRecommendedDurations() map[string]time.Duration {
+	return cr.utils.GetRecommendedDurations()
+}
+
+// ApplyPreset sets the work and break durations from a named preset; see
+// ClockUtils.GetDurationPreset for the accepted names
+func (cr *ClockRunner) ApplyPreset(name string) error {
+	work, shortBreak, longBreak, err := cr.utils.GetDurationPreset(name)
+	if err != nil {
+		return err
+	}
+	return cr.SetDurations(work, shortBreak, longBreak)
+}
+
 // GetScheduleSummary returns a summary of the schedule
 func (cr *ClockRunner) GetScheduleSummary() map[ClockState]int {
 	return cr.utils.GetScheduleSummary(cr.sessionManager.GetSchedule())
 }
 
+// GetRemainingSessions counts the sessions of each state left in the cycle,
+// including the current one
+func (cr *ClockRunner) GetRemainingSessions() map[ClockState]int {
+	return cr.sessionManager.GetRemainingSessions()
+}
+
 // GetRedisPersistence returns the Redis persistence instance
 func (cr *ClockRunner) GetRedisPersistence() *RedisPersistence {
 	return cr.redisPersistence
@@ -432,16 +1232,14 @@ func (cr *ClockRunner) synchronizeStateTimer() {
 
 	// If state says running but timer is not running, fix the state
 	if stateRunning && !timerRunning && cr.stateManager.GetState() != StatePaused {
-		log.Printf("🔄 Fixing state/timer inconsistency: timer stopped, updating state to idle")
+		slog.Warn("fixing state/timer inconsistency: timer stopped, setting state to idle")
 		cr.stateManager.SetState(StateIdle)
-		if cr.onStateChange != nil {
-			cr.onStateChange(StateIdle)
-		}
+		notifyStateChange(cr, StateIdle)
 	}
 
 	// If timer is running but state says idle/paused, fix the timer
 	if !stateRunning && timerRunning {
-		log.Printf("🔄 Fixing state/timer inconsistency: stopping orphaned timer")
+		slog.Warn("fixing state/timer inconsistency: stopping orphaned timer")
 		cr.timerManager.StopTimer()
 	}
 }
@@ -449,52 +1247,70 @@ func (cr *ClockRunner) synchronizeStateTimer() {
 // saveStateToRedis saves the current state to Redis immediately
 func (cr *ClockRunner) saveStateToRedis() {
 	if cr.redisPersistence == nil {
-		log.Printf("⚠️ Cannot save to Redis: redisPersistence is nil")
+		slog.Debug("skipping Redis save: persistence not configured")
 		return
 	}
 
-	log.Printf("💾 Immediate Redis save - State: %s", cr.GetState())
-	// Always save immediately for state changes
-	if err := cr.persistenceManager.SaveSystemStateToRedis(); err != nil {
-		log.Printf("Failed to save state to Redis: %v", err)
-	} else {
-		log.Printf("✅ Immediate Redis save completed")
+	// Save now, or coalesce with the save that just went out
+	if err := cr.persistenceManager.RequestSystemStateSave(); err != nil {
+		slog.Error("failed to save state to Redis", "state", cr.GetState(), "error", err)
+	}
+}
+
+// flushStateToRedis saves the state immediately, replacing any coalesced save
+// still waiting
+func (cr *ClockRunner) flushStateToRedis() {
+	if cr.redisPersistence == nil {
+		return
+	}
+
+	if err := cr.persistenceManager.FlushSystemState(); err != nil {
+		slog.Error("failed to save state to Redis", "state", cr.GetState(), "error", err)
 	}
 }
 
 // runSaveStateToRedis starts a goroutine that periodically saves state to Redis
 func (cr *ClockRunner) runSaveStateToRedis() {
+	cr.redisSaveMu.Lock()
+	defer cr.redisSaveMu.Unlock()
+
 	// Stop any existing goroutine first
-	cr.stopSaveStateToRedis()
+	cr.stopSaveStateToRedisLocked()
 
-	cr.redisSaveTicker = time.NewTicker(3 * time.Second) // Save every 3 seconds
-	cr.redisSaveStop = make(chan struct{})
+	// The goroutine keeps its own references; stopSaveStateToRedis clears the fields
+	ticker := time.NewTicker(3 * time.Second) // Save every 3 seconds
+	stop := make(chan struct{})
+	cr.redisSaveTicker = ticker
+	cr.redisSaveStop = stop
 
 	go func() {
-		log.Printf("🚀 Starting periodic Redis save goroutine")
+		slog.Debug("starting periodic Redis save")
 		for {
 			select {
-			case <-cr.redisSaveTicker.C:
+			case <-ticker.C:
 				if cr.redisPersistence != nil && !cr.IsIdle() {
-					log.Printf("🔄 Periodic Redis save - State: %s, IsIdle: %v", cr.GetState(), cr.IsIdle())
 					if err := cr.persistenceManager.SaveSystemStateToRedis(); err != nil {
-						log.Printf("Failed to save state to Redis: %v", err)
-					} else {
-						log.Printf("✅ Periodic Redis save completed")
+						slog.Error("periodic Redis save failed", "state", cr.GetState(), "error", err)
 					}
-				} else {
-					log.Printf("⏸️ Skipping periodic Redis save - Redis: %v, IsIdle: %v", cr.redisPersistence != nil, cr.IsIdle())
 				}
-			case <-cr.redisSaveStop:
-				log.Printf("🛑 Stopping periodic Redis save goroutine")
+			case <-stop:
+				slog.Debug("stopping periodic Redis save")
 				return
 			}
 		}
 	}()
 }
 
-// stopSaveStateToRedis stops the periodic Redis save goroutine
+// stopSaveStateToRedis stops the periodic Redis save goroutine. It is safe to
+// call more than once and from several goroutines.
 func (cr *ClockRunner) stopSaveStateToRedis() {
+	cr.redisSaveMu.Lock()
+	defer cr.redisSaveMu.Unlock()
+	cr.stopSaveStateToRedisLocked()
+}
+
+// stopSaveStateToRedisLocked is stopSaveStateToRedis with redisSaveMu held
+func (cr *ClockRunner) stopSaveStateToRedisLocked() {
 	if cr.redisSaveTicker != nil {
 		cr.redisSaveTicker.Stop()
 		cr.redisSaveTicker = nil
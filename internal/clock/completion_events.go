@@ -0,0 +1,28 @@
+package clock
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// GetCompletionEvents returns the sessions completed after since, oldest
+// first, so a client that was away can show what it missed. The clock is
+// shared, so every user sees the same events. See
+// RedisPersistence.LoadCompletionEvents for how since is compared.
+func (cr *ClockRunner) GetCompletionEvents(since time.Time) ([]CompletionEvent, error) {
+	if cr.redisPersistence == nil {
+		return nil, fmt.Errorf("redis persistence not initialized")
+	}
+	return cr.redisPersistence.LoadCompletionEvents(since)
+}
+
+// saveCompletionEvent adds the completion to the event buffer in Redis, if configured
+func saveCompletionEvent(cr *ClockRunner, completion CompletionEvent) {
+	if cr.redisPersistence == nil {
+		return
+	}
+	if err := cr.redisPersistence.SaveCompletionEvent(completion); err != nil {
+		slog.Error("failed to save completion event to Redis", "state", completion.CompletedState, "error", err)
+	}
+}
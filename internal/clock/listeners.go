@@ -0,0 +1,196 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// ClockEventType identifies what triggered a ClockEvent
+type ClockEventType string
+
+const (
+	EventStateChange ClockEventType = "state"
+	EventTick        ClockEventType = "tick"
+	EventComplete    ClockEventType = "complete"
+	// EventGoalReached is sent once a day, when the daily work goal is reached
+	EventGoalReached ClockEventType = "goal"
+)
+
+// ClockEvent is delivered to subscribers on every state change, tick and
+// session completion
+type ClockEvent struct {
+	Type     ClockEventType
+	Snapshot StartResult
+	// Completion describes the completed session; set only for EventComplete
+	Completion *CompletionEvent
+	// Goal is the progress toward the daily work goal; set only for EventGoalReached
+	Goal *DailyGoalProgress
+}
+
+// CompletionEvent describes a session that ran to completion, so clients can
+// notify without fetching the state again
+type CompletionEvent struct {
+	CompletedState ClockState
+	// NextState is the state of the session that follows, or StateIdle when
+	// the clock stopped
+	NextState ClockState
+	// CycleFinished is true when the completed session was the last of the schedule
+	CycleFinished bool
+	// SessionNumber is the 0-based index of the completed session
+	SessionNumber int
+	// Configured durations in minutes
+	WorkMinutes       int
+	ShortBreakMinutes int
+	LongBreakMinutes  int
+	CompletedAt       time.Time
+}
+
+// listenerRegistry fans clock events out to any number of subscribers. Unlike
+// the single callbacks set with SetCallbacks, subscribers can come and go at
+// runtime, e.g. one per connected client.
+type listenerRegistry struct {
+	mu     sync.Mutex
+	nextID int
+	subs   map[int]*subscriber
+}
+
+func newListenerRegistry() *listenerRegistry {
+	return &listenerRegistry{subs: make(map[int]*subscriber)}
+}
+
+// subscriber queues events for one listener. push never blocks: events the
+// reader has not taken yet wait in pending, where a new tick replaces any
+// older one still queued. A forwarding goroutine moves pending events to out.
+type subscriber struct {
+	out    chan ClockEvent
+	notify chan struct{}
+	done   chan struct{}
+
+	mu      sync.Mutex
+	pending []ClockEvent
+}
+
+func newSubscriber(buffer int) *subscriber {
+	s := &subscriber{
+		out:    make(chan ClockEvent, buffer),
+		notify: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	go s.forward()
+	return s
+}
+
+// push queues event without blocking. Ticks are coalesced so only the latest
+// one waits; every other event is kept.
+func (s *subscriber) push(event ClockEvent) {
+	s.mu.Lock()
+	if event.Type == EventTick {
+		for i, queued := range s.pending {
+			if queued.Type == EventTick {
+				s.pending = append(s.pending[:i], s.pending[i+1:]...)
+				break
+			}
+		}
+	}
+	s.pending = append(s.pending, event)
+	s.mu.Unlock()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// next pops the oldest pending event
+func (s *subscriber) next() (ClockEvent, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) == 0 {
+		return ClockEvent{}, false
+	}
+	event := s.pending[0]
+	s.pending = s.pending[1:]
+	return event, true
+}
+
+// forward delivers pending events to out until the subscriber is closed, then
+// closes out
+func (s *subscriber) forward() {
+	defer close(s.out)
+	for {
+		select {
+		case <-s.notify:
+		case <-s.done:
+			return
+		}
+		for {
+			event, ok := s.next()
+			if !ok {
+				break
+			}
+			select {
+			case s.out <- event:
+			case <-s.done:
+				return
+			}
+		}
+	}
+}
+
+// Subscribe registers a new listener and returns its event channel together
+// with a function that unsubscribes and closes the channel. buffer is how many
+// events the channel holds; once a slow reader falls further behind, only the
+// latest tick is kept while state changes, completions and goal events are
+// always delivered. A slow reader never blocks the clock.
+func (cr *ClockRunner) Subscribe(buffer int) (<-chan ClockEvent, func()) {
+	l := cr.listeners
+	s := newSubscriber(buffer)
+
+	l.mu.Lock()
+	id := l.nextID
+	l.nextID++
+	l.subs[id] = s
+	l.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			l.mu.Lock()
+			delete(l.subs, id)
+			l.mu.Unlock()
+			close(s.done)
+		})
+	}
+	return s.out, unsubscribe
+}
+
+// publish sends an event with the current snapshot to every subscriber
+func (cr *ClockRunner) publish(eventType ClockEventType) {
+	cr.publishEvent(ClockEvent{Type: eventType})
+}
+
+// publishCompletion sends an EventComplete with the current snapshot to every subscriber
+func (cr *ClockRunner) publishCompletion(completion CompletionEvent) {
+	cr.publishEvent(ClockEvent{Type: EventComplete, Completion: &completion})
+}
+
+// publishGoalReached sends an EventGoalReached with the current snapshot to every subscriber
+func (cr *ClockRunner) publishGoalReached(progress DailyGoalProgress) {
+	cr.publishEvent(ClockEvent{Type: EventGoalReached, Goal: &progress})
+}
+
+// publishEvent fills in the current snapshot and sends event to every subscriber
+func (cr *ClockRunner) publishEvent(event ClockEvent) {
+	l := cr.listeners
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.subs) == 0 {
+		return
+	}
+
+	event.Snapshot = cr.snapshot()
+	for _, s := range l.subs {
+		s.push(event)
+	}
+}
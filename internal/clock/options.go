@@ -0,0 +1,125 @@
+package clock
+
+import (
+	"log/slog"
+	"time"
+)
+
+// ClockRunnerOption configures a ClockRunner at construction time
+type ClockRunnerOption func(*ClockRunner)
+
+// WithMinDuration overrides the minimum session duration accepted by SetDurations.
+// The default is MinSessionDuration (1 minute); tests lower it to run sub-second sessions.
+func WithMinDuration(min time.Duration) ClockRunnerOption {
+	return func(cr *ClockRunner) {
+		cr.sessionManager.SetMinDuration(min)
+	}
+}
+
+// WithRedisRetry sets how Redis writes are retried: attempts counts the first
+// try and backoff is the wait before the first retry, doubling after each one.
+// It has no effect without Redis persistence.
+func WithRedisRetry(attempts int, backoff time.Duration) ClockRunnerOption {
+	return func(cr *ClockRunner) {
+		if cr.redisPersistence == nil {
+			return
+		}
+		if err := cr.redisPersistence.SetRetryPolicy(attempts, backoff); err != nil {
+			slog.Warn("ignoring invalid Redis retry policy", "error", err)
+		}
+	}
+}
+
+// WithStatsRetention sets how long the daily session statistics keys are kept
+// in Redis. The default is DefaultStatsRetention. It has no effect without
+// Redis persistence.
+func WithStatsRetention(retention time.Duration) ClockRunnerOption {
+	return func(cr *ClockRunner) {
+		if cr.redisPersistence == nil {
+			return
+		}
+		if err := cr.redisPersistence.SetStatsRetention(retention); err != nil {
+			slog.Warn("ignoring invalid statistics retention", "error", err)
+		}
+	}
+}
+
+// WithCompletionEventBuffer sets how many completion events are kept in Redis
+// for clients catching up and how long each is kept. The defaults are
+// DefaultCompletionEventLimit and DefaultCompletionEventTTL. It has no effect
+// without Redis persistence.
+func WithCompletionEventBuffer(limit int, ttl time.Duration) ClockRunnerOption {
+	return func(cr *ClockRunner) {
+		if cr.redisPersistence == nil {
+			return
+		}
+		if err := cr.redisPersistence.SetCompletionEventBuffer(limit, ttl); err != nil {
+			slog.Warn("ignoring invalid completion event buffer", "error", err)
+		}
+	}
+}
+
+// WithRedisHealthCheckInterval sets how often the Redis connection is checked
+// and reconnected. The default is DefaultRedisHealthCheckInterval.
+func WithRedisHealthCheckInterval(interval time.Duration) ClockRunnerOption {
+	return func(cr *ClockRunner) {
+		cr.redisHealthCheckInterval = interval
+	}
+}
+
+// WithResumePolicy sets how a session that was active when the server stopped
+// is resumed from Redis. The default is ResumeAuto.
+func WithResumePolicy(policy ResumePolicy) ClockRunnerOption {
+	return func(cr *ClockRunner) {
+		cr.resumePolicy = policy
+	}
+}
+
+// WithResumeGracePeriod sets how much longer than the rest of its cycle a
+// session saved in Redis may be overdue and still be completed at startup,
+// catching up through any later sessions that also elapsed. Sessions overdue by
+// more are reset to idle. The default is DefaultResumeGracePeriod, no extra time.
+func WithResumeGracePeriod(grace time.Duration) ClockRunnerOption {
+	return func(cr *ClockRunner) {
+		cr.resumeGracePeriod = grace
+	}
+}
+
+// WithStrictResume makes a resume that finds sessions completed while the
+// server was down come up idle and log how many were skipped, instead of
+// recording them and catching up. Statistics are left untouched. Off by
+// default; when given, it takes precedence over the behavior settings stored
+// in Redis.
+func WithStrictResume(strict bool) ClockRunnerOption {
+	return func(cr *ClockRunner) {
+		cr.strictResume = strict
+		cr.strictResumeFixed = true
+	}
+}
+
+// WithDuplicateRecordWindow sets how close to the last session recorded before
+// a restart a resumed completion of the same session must be to count as a
+// duplicate and be skipped. Zero records every completion. The default is
+// DefaultDuplicateRecordWindow.
+func WithDuplicateRecordWindow(window time.Duration) ClockRunnerOption {
+	return func(cr *ClockRunner) {
+		cr.duplicateRecordWindow = window
+	}
+}
+
+// WithStateSaveWindow coalesces system state saves: a change within window of
+// the last save is written once the window has passed, together with any other
+// changes made meanwhile. Stop and Close still save immediately. Zero, the
+// default, saves every change as it happens.
+func WithStateSaveWindow(window time.Duration) ClockRunnerOption {
+	return func(cr *ClockRunner) {
+		cr.stateSaveWindow = window
+	}
+}
+
+// applyOptions applies construction options in order
+func (cr *ClockRunner) applyOptions(opts []ClockRunnerOption) {
+	for _, opt := range opts {
+		opt(cr)
+	}
+}
@@ -1,14 +1,28 @@
 package clock
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // PersistenceManager handles Redis persistence operations for the clock runner
 type PersistenceManager struct {
 	clockRunner *ClockRunner
+
+	// stateVersion is the version of the last system state snapshot
+	stateVersion atomic.Int64
+
+	// Coalescing of system state saves within the clock runner's
+	// stateSaveWindow. pendingSave is the timer of a deferred save;
+	// saveGeneration invalidates a timer that fires after it was replaced.
+	saveMu         sync.Mutex
+	lastSave       time.Time
+	pendingSave    *time.Timer
+	saveGeneration int
 }
 
 // NewPersistenceManager creates a new persistence manager
@@ -28,15 +42,57 @@ func (pm *PersistenceManager) LoadSettingsFromRedis() error {
 	if err != nil {
 		return err
 	}
+	behavior, err := pm.clockRunner.redisPersistence.LoadBehaviorSettings()
+	if err != nil {
+		return err
+	}
+
+	// Apply the behavior settings first, so the saves below write them back
+	// unchanged. They take precedence over the flags kept in the settings hash.
+	if behavior != nil {
+		pm.clockRunner.mu.Lock()
+		pm.clockRunner.applyBehaviorLocked(*behavior)
+		pm.clockRunner.mu.Unlock()
+	}
+
+	// Apply the schedule before the durations, whose save writes it back.
+	// Settings saved before the schedule was persisted hold "default".
+	if settings.Scheduling != "" && settings.Scheduling != "default" {
+		specs, err := ParseScheduling(settings.Scheduling)
+		if err == nil {
+			err = pm.clockRunner.sessionManager.SetScheduleSpecs(specs)
+		}
+		if err != nil {
+			slog.Warn("ignoring invalid schedule saved in Redis, keeping the default",
+				"scheduling", settings.Scheduling,
+				"error", err)
+		}
+	}
 
 	// Apply the loaded settings
-	pm.clockRunner.SetDurations(
+	if err := pm.clockRunner.SetDurations(
 		time.Duration(settings.WorkTime)*time.Minute,
 		time.Duration(settings.ShortBreakTime)*time.Minute,
 		time.Duration(settings.LongBreakTime)*time.Minute,
-	)
+	); err != nil {
+		return err
+	}
 
-	return nil
+	// Settings saved before custom breaks existed keep the default
+	if settings.CustomBreakTime > 0 {
+		if err := pm.clockRunner.SetCustomBreakDuration(time.Duration(settings.CustomBreakTime) * time.Minute); err != nil {
+			return err
+		}
+	}
+
+	if err := pm.clockRunner.SetDailyWorkGoal(settings.DailyWorkGoal); err != nil {
+		return err
+	}
+	if behavior != nil {
+		return nil
+	}
+	pm.clockRunner.SetAutoStartNext(!settings.WaitForStart)
+	return pm.clockRunner.SetIdleTimeout(time.Duration(settings.IdleTimeout) * time.Minute)
 }
 
 // SaveSettingsToRedis saves current settings to Redis
@@ -47,21 +103,48 @@ func (pm *PersistenceManager) SaveSettingsToRedis() error {
 
 	workMinutes, shortBreakMinutes, longBreakMinutes := pm.clockRunner.GetDurations()
 	settings := &PomodoroSettings{
-		WorkTime:       int(workMinutes),
-		ShortBreakTime: int(shortBreakMinutes),
-		LongBreakTime:  int(longBreakMinutes),
-		Scheduling:     "default", // TODO: get from session manager
+		WorkTime:        int(workMinutes),
+		ShortBreakTime:  int(shortBreakMinutes),
+		LongBreakTime:   int(longBreakMinutes),
+		CustomBreakTime: int(pm.clockRunner.GetCustomBreakDuration().Minutes()),
+		IdleTimeout:     int(pm.clockRunner.GetIdleTimeout().Minutes()),
+		DailyWorkGoal:   pm.clockRunner.GetDailyWorkGoal(),
+		WaitForStart:    !pm.clockRunner.GetAutoStartNext(),
+		Scheduling:      FormatSchedulingSpecs(pm.clockRunner.GetScheduleSpecs()),
 	}
 
-	return pm.clockRunner.redisPersistence.SaveSettings(settings)
+	if err := pm.clockRunner.redisPersistence.SaveSettings(settings); err != nil {
+		return err
+	}
+	return pm.clockRunner.redisPersistence.SaveBehaviorSettings(pm.clockRunner.storedBehavior())
+}
+
+// nextStateVersion returns a version for a new state snapshot. It follows the
+// wall clock so versions keep increasing across restarts, and is bumped past
+// the previous version if the clock has not moved or went backwards. Versions
+// are in microseconds so they compare exactly as Lua numbers in Redis scripts.
+func (pm *PersistenceManager) nextStateVersion() int64 {
+	for {
+		last := pm.stateVersion.Load()
+		version := max(time.Now().UnixMicro(), last+1)
+		if pm.stateVersion.CompareAndSwap(last, version) {
+			return version
+		}
+	}
 }
 
-// SaveSystemStateToRedis saves the current system state to Redis
+// SaveSystemStateToRedis saves the current system state to Redis. A snapshot
+// that lost the race to a newer one, such as a periodic save overtaken by an
+// immediate save, is skipped.
 func (pm *PersistenceManager) SaveSystemStateToRedis() error {
 	if pm.clockRunner.redisPersistence == nil {
 		return fmt.Errorf("redis persistence not initialized")
 	}
 
+	// Take the version before reading the state, so the data is never older
+	// than its version
+	version := pm.nextStateVersion()
+
 	// Get current state
 	currentState := pm.clockRunner.GetState()
 	timeRemaining := pm.clockRunner.GetTimeRemaining()
@@ -86,8 +169,8 @@ func (pm *PersistenceManager) SaveSystemStateToRedis() error {
 		}
 	}
 
-	// Get system timezone
-	timezone := time.Now().Location().String()
+	// Get the timezone the clock is running in
+	timezone := pm.clockRunner.GetLocation().String()
 
 	// Get running/paused status
 	isRunning := pm.clockRunner.IsRunning()
@@ -101,24 +184,137 @@ func (pm *PersistenceManager) SaveSystemStateToRedis() error {
 		TimeRemaining:  timeRemainingSeconds,
 		IsRunning:      isRunning,
 		IsPaused:       isPaused,
+		Tag:            pm.clockRunner.sessionManager.GetCurrentTag(),
+		PauseReason:    pm.clockRunner.stateManager.GetPauseReason(),
+		Version:        version,
+	}
+
+	slog.Debug("saving system state to Redis",
+		"session", state.CurrentSession,
+		"state", state.State,
+		"remaining_ms", state.TimeRemaining,
+		"running", state.IsRunning,
+		"paused", state.IsPaused,
+		"end_time", state.EndTime)
+	err := pm.clockRunner.redisPersistence.SaveSystemState(state)
+	if errors.Is(err, ErrStaleSystemState) {
+		slog.Debug("skipped stale system state save", "version", state.Version)
+		return nil
+	}
+	return err
+}
+
+// RequestSystemStateSave saves the system state now or, when a save went out
+// less than the state save window ago, once the window has passed. Changes in
+// the meantime are coalesced into that one write, which saves the state as it
+// is when the write happens, so the last state always lands.
+func (pm *PersistenceManager) RequestSystemStateSave() error {
+	pm.saveMu.Lock()
+	if pm.pendingSave != nil {
+		pm.saveMu.Unlock()
+		return nil
+	}
+	if wait := pm.clockRunner.stateSaveWindow - time.Since(pm.lastSave); wait > 0 {
+		pm.saveGeneration++
+		generation := pm.saveGeneration
+		pm.pendingSave = time.AfterFunc(wait, func() { pm.savePending(generation) })
+		pm.saveMu.Unlock()
+		return nil
+	}
+	pm.lastSave = time.Now()
+	pm.saveMu.Unlock()
+
+	return pm.SaveSystemStateToRedis()
+}
+
+// savePending performs a deferred save unless it was flushed or replaced
+func (pm *PersistenceManager) savePending(generation int) {
+	pm.saveMu.Lock()
+	if generation != pm.saveGeneration || pm.pendingSave == nil {
+		pm.saveMu.Unlock()
+		return
+	}
+	pm.pendingSave = nil
+	pm.lastSave = time.Now()
+	pm.saveMu.Unlock()
+
+	if err := pm.SaveSystemStateToRedis(); err != nil {
+		slog.Error("failed to save coalesced state to Redis", "error", err)
 	}
+}
+
+// FlushSystemState cancels any deferred save and saves the system state now
+func (pm *PersistenceManager) FlushSystemState() error {
+	pm.saveMu.Lock()
+	if pm.pendingSave != nil {
+		pm.pendingSave.Stop()
+		pm.pendingSave = nil
+	}
+	pm.saveGeneration++
+	pm.lastSave = time.Now()
+	pm.saveMu.Unlock()
+
+	return pm.SaveSystemStateToRedis()
+}
+
+// hasPendingSave reports whether a deferred save is waiting
+func (pm *PersistenceManager) hasPendingSave() bool {
+	pm.saveMu.Lock()
+	defer pm.saveMu.Unlock()
+	return pm.pendingSave != nil
+}
+
+// SaveSessionRecord saves a completed session to the Redis statistics and marks
+// it as the last recorded session
+func (pm *PersistenceManager) SaveSessionRecord(session int, record SessionRecord) error {
+	if pm.clockRunner.redisPersistence == nil {
+		return fmt.Errorf("redis persistence not initialized")
+	}
+
+	marker := RecordedSession{Session: session, State: record.State, Completed: record.Completed}
+	return pm.clockRunner.redisPersistence.SaveRecordedSession(marker, record)
+}
 
-	log.Printf("💾 Saving to Redis - Session: %d, State: %s, Remaining: %dms, Running: %v, Paused: %v, EndTime: %s",
-		state.CurrentSession, state.State, state.TimeRemaining, state.IsRunning, state.IsPaused,
-		state.EndTime.Format("15:04:05"))
+// LoadLastRecordedSessionFromRedis loads the last session recorded before the
+// server restarted, which the first record after it is checked against
+func (pm *PersistenceManager) LoadLastRecordedSessionFromRedis() error {
+	if pm.clockRunner.redisPersistence == nil {
+		return fmt.Errorf("redis persistence not initialized")
+	}
 
-	log.Printf("Checking state manager is running: %v", pm.clockRunner.stateManager.IsRunning())
-	log.Printf("Checking if timer is running: %v", pm.clockRunner.timerManager.IsRunning())
-	return pm.clockRunner.redisPersistence.SaveSystemState(state)
+	marker, err := pm.clockRunner.redisPersistence.LoadLastRecordedSession()
+	if err != nil {
+		return err
+	}
+	pm.clockRunner.lastRecordedBeforeRestart.Store(marker)
+	return nil
 }
 
-// SaveSessionStatistics saves session statistics to Redis
-func (pm *PersistenceManager) SaveSessionStatistics(sessionType string, duration time.Duration) error {
+// LoadStatisticsFromRedis restores the statistics counters and the session
+// history from Redis
+func (pm *PersistenceManager) LoadStatisticsFromRedis() error {
 	if pm.clockRunner.redisPersistence == nil {
 		return fmt.Errorf("redis persistence not initialized")
 	}
 
-	return pm.clockRunner.redisPersistence.SaveSessionStatistics(sessionType, duration, time.Now())
+	stats, err := pm.clockRunner.redisPersistence.LoadSessionStatistics()
+	if err != nil {
+		return err
+	}
+
+	history, err := pm.clockRunner.redisPersistence.LoadSessionHistory()
+	if err != nil {
+		return err
+	}
+
+	pm.clockRunner.statsManager.Hydrate(stats.WorkSessions, stats.ShortBreaks, stats.LongBreaks, stats.WorkTime, stats.BreakTime, stats.CustomBreaks, stats.CustomBreakTime)
+	pm.clockRunner.statsManager.HydrateHistory(history)
+	slog.Info("loaded statistics from Redis",
+		"work_sessions", stats.WorkSessions,
+		"short_breaks", stats.ShortBreaks,
+		"long_breaks", stats.LongBreaks,
+		"history", len(history))
+	return nil
 }
 
 // Close closes the Redis connection
@@ -0,0 +1,122 @@
+package clock
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// UnwatchedPauseReason is the pause reason of a session paused because no
+// client was watching
+const UnwatchedPauseReason = "unwatched"
+
+const (
+	// DefaultUnwatchedGrace is how long the clock keeps running after the last
+	// watcher leaves before pausing, when pausing unwatched is enabled
+	DefaultUnwatchedGrace = 30 * time.Second
+	// MaxUnwatchedGrace is the longest unwatched grace accepted
+	MaxUnwatchedGrace = time.Hour
+)
+
+// presenceTracker counts the clients watching the clock, e.g. open WebSocket
+// streams. generation invalidates a pause timer that fires after a watcher
+// came back. Its mutex is taken before cr.mu, never after.
+type presenceTracker struct {
+	mu         sync.Mutex
+	watchers   int
+	timer      *time.Timer
+	generation int
+}
+
+// Watch registers a client watching the clock and returns a function that
+// unregisters it; calling it more than once has no effect. When the last
+// watcher leaves and pausing unwatched is enabled, a running session is paused
+// with UnwatchedPauseReason after the unwatched grace. The first watcher to
+// come back resumes a session paused that way.
+func (cr *ClockRunner) Watch() (release func()) {
+	p := &cr.presence
+
+	p.mu.Lock()
+	p.watchers++
+	p.cancelLocked()
+	if p.watchers == 1 {
+		cr.resumeUnwatched()
+	}
+	p.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(cr.unwatch)
+	}
+}
+
+// GetWatcherCount returns how many clients are watching the clock
+func (cr *ClockRunner) GetWatcherCount() int {
+	cr.presence.mu.Lock()
+	defer cr.presence.mu.Unlock()
+	return cr.presence.watchers
+}
+
+// cancelLocked stops a pending pause; the caller must hold p.mu
+func (p *presenceTracker) cancelLocked() {
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+	p.generation++
+}
+
+// unwatch unregisters a watcher and, if it was the last, arms the pause
+func (cr *ClockRunner) unwatch() {
+	behavior := cr.GetBehavior()
+	p := &cr.presence
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.watchers--
+	if p.watchers > 0 || !behavior.PauseWhenUnwatched {
+		return
+	}
+
+	generation := p.generation
+	p.timer = time.AfterFunc(behavior.UnwatchedGrace, func() {
+		cr.pauseUnwatched(generation)
+	})
+}
+
+// pauseUnwatched pauses a running session if nobody started watching since
+// the timer was armed and pausing unwatched is still enabled
+func (cr *ClockRunner) pauseUnwatched(generation int) {
+	p := &cr.presence
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if generation != p.generation || p.watchers > 0 || !cr.GetBehavior().PauseWhenUnwatched {
+		return
+	}
+	p.timer = nil
+
+	if _, err := cr.PauseWithReason(UnwatchedPauseReason); err != nil {
+		// Nothing was running
+		return
+	}
+	slog.Info("paused session, nobody is watching", "session", cr.GetCurrentSession())
+}
+
+// resumeUnwatched resumes a session paused by pauseUnwatched; the caller must
+// hold cr.presence.mu. A session paused for any other reason stays paused.
+func (cr *ClockRunner) resumeUnwatched() {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	if !cr.stateManager.IsPaused() || cr.stateManager.GetPauseReason() != UnwatchedPauseReason {
+		return
+	}
+	if err := cr.startLocked(); err != nil {
+		slog.Warn("failed to resume unwatched session", "error", err)
+		return
+	}
+	slog.Info("resumed session, a client is watching again", "session", cr.GetCurrentSession())
+}
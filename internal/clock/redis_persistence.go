@@ -2,8 +2,14 @@ package clock
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -11,16 +17,46 @@ import (
 
 // RedisPersistence handles Redis operations for clock state and settings
 type RedisPersistence struct {
+	mu     sync.RWMutex
 	client *redis.Client
+	opts   *redis.Options
 	ctx    context.Context
+
+	// Write retry policy, see SetRetryPolicy
+	retryAttempts int
+	retryBackoff  time.Duration
+
+	// statsRetention is the TTL of the daily session statistics keys
+	statsRetention time.Duration
+	// Size and expiry of the completion event buffer, see SetCompletionEventBuffer
+	completionEventLimit int
+	completionEventTTL   time.Duration
+
+	// Connection health, updated by writes and the health check
+	healthy        bool
+	onHealthChange func(healthy bool, err error)
+	healthStop     chan struct{}
+	closed         bool
+
+	// stateSaves counts system state writes sent to Redis
+	stateSaves atomic.Int64
 }
 
 // PomodoroSettings represents the settings stored in Redis
 type PomodoroSettings struct {
-	WorkTime       int    `json:"workTime"`
-	ShortBreakTime int    `json:"shortBreakTime"`
-	LongBreakTime  int    `json:"longBreakTime"`
-	Scheduling     string `json:"scheduling"`
+	WorkTime       int `json:"workTime"`
+	ShortBreakTime int `json:"shortBreakTime"`
+	LongBreakTime  int `json:"longBreakTime"`
+	// CustomBreakTime is zero in settings saved before custom breaks existed
+	CustomBreakTime int `json:"customBreakTime"`
+	// IdleTimeout is in minutes; zero disables it
+	IdleTimeout int `json:"idleTimeout"`
+	// DailyWorkGoal is the number of work sessions to complete each day; zero means none
+	DailyWorkGoal int `json:"dailyWorkGoal"`
+	// WaitForStart is set when auto-starting the next session is turned off, so
+	// that the zero value and settings saved before the toggle keep the default
+	WaitForStart bool   `json:"waitForStart"`
+	Scheduling   string `json:"scheduling"`
 }
 
 // SystemState represents the current system state stored in Redis
@@ -32,15 +68,105 @@ type SystemState struct {
 	TimeRemaining  int64     `json:"timeRemaining"` // in milliseconds for better precision
 	IsRunning      bool      `json:"isRunning"`
 	IsPaused       bool      `json:"isPaused"`
+	// Tag labels the current session; empty when none was set
+	Tag string `json:"tag"`
+	// PauseReason is why a paused session was paused; empty when none was given
+	PauseReason string `json:"pauseReason,omitempty"`
+	// Version orders saves of the state; a save older than the stored state is
+	// refused. Zero marks an unversioned state, which is always written.
+	Version int64 `json:"version"`
+}
+
+// ErrStaleSystemState is returned when a system state save is refused because
+// Redis already holds a newer state
+var ErrStaleSystemState = errors.New("stale system state")
+
+const (
+	// systemStateKey holds the system state as a JSON document
+	systemStateKey = "systemState:json"
+	// legacySystemStateKey is the hash the state was stored in before the JSON
+	// document; it is still read when the document is missing and removed on save
+	legacySystemStateKey = "systemState"
+	// systemStateSchemaVersion is the layout of the JSON document; bump it when
+	// fields change meaning so older servers refuse to load newer documents.
+	// Version 1 stored endTime as an RFC3339 string, version 2 as Unix milliseconds.
+	systemStateSchemaVersion = 2
+	// lastRecordedSessionKey holds the RecordedSession written with the
+	// statistics of the last completed session
+	lastRecordedSessionKey = "lastRecordedSession"
+	// scheduleTemplatesKey is a hash of template name to ScheduleTemplate JSON
+	scheduleTemplatesKey = "scheduleTemplates"
+	// behaviorSettingsKey holds the BehaviorSettings as JSON
+	behaviorSettingsKey = "behaviorSettings"
+	// completionEventsKey is a sorted set of completionEventDocument JSON
+	// scored by completion time in Unix milliseconds
+	completionEventsKey = "completionEvents"
+	// sessionHistoryKey is a sorted set of sessionRecordDocument JSON scored by
+	// completion time in Unix milliseconds, kept as long as the statistics keys
+	sessionHistoryKey = "sessionHistory"
+)
+
+// sessionRecordDocument is the stored form of a SessionRecord
+type sessionRecordDocument struct {
+	State     ClockState    `json:"state"`
+	Duration  time.Duration `json:"duration"`
+	Completed time.Time     `json:"completed"`
+	Tag       string        `json:"tag,omitempty"`
+	// ID keeps otherwise identical records apart, which the sorted set would
+	// merge into one
+	ID int64 `json:"id"`
+}
+
+// RecordedSession marks the last session recorded in the statistics, so a
+// resume does not record it a second time
+type RecordedSession struct {
+	Session   int        `json:"session"` // 0-based index in the schedule
+	State     ClockState `json:"state"`
+	Completed time.Time  `json:"completed"`
+}
+
+// systemStateDocument is the JSON document stored under systemStateKey
+type systemStateDocument struct {
+	SchemaVersion int `json:"schemaVersion"`
+	SystemState
+	// EndTime replaces SystemState.EndTime in the document with Unix
+	// milliseconds, matching the precision of TimeRemaining
+	EndTime int64 `json:"endTime"`
+}
+
+// RemainingAt returns the time left in the saved session at now, to the
+// millisecond; it is negative once the session has ended
+func (s *SystemState) RemainingAt(now time.Time) time.Duration {
+	return s.EndTime.Sub(now)
 }
 
-// NewRedisPersistence creates a new Redis persistence instance
+// saveSystemStateScript writes the system state document unless the stored
+// document has a newer version, and removes the legacy hash. ARGV[1] is the
+// version and ARGV[2] the document; it returns 1 when written and 0 when refused.
+var saveSystemStateScript = redis.NewScript(`
+local version = tonumber(ARGV[1])
+local doc = redis.call('GET', KEYS[1])
+if version > 0 and doc then
+	local current = tonumber(cjson.decode(doc)['version']) or 0
+	if current > version then
+		return 0
+	end
+end
+redis.call('SET', KEYS[1], ARGV[2])
+redis.call('DEL', KEYS[2])
+return 1
+`)
+
+// NewRedisPersistence creates a new Redis persistence instance for an
+// unauthenticated Redis, using the default DB
 func NewRedisPersistence(addr string) (*RedisPersistence, error) {
-	client := redis.NewClient(&redis.Options{
-		Addr:     addr,
-		Password: "", // no password set
-		DB:       0,  // use default DB
-	})
+	return NewRedisPersistenceWithOptions(&redis.Options{Addr: addr})
+}
+
+// NewRedisPersistenceWithOptions creates a new Redis persistence instance from
+// full client options, e.g. to set a password or DB index
+func NewRedisPersistenceWithOptions(opts *redis.Options) (*RedisPersistence, error) {
+	client := redis.NewClient(opts)
 
 	ctx := context.Background()
 
@@ -50,38 +176,63 @@ func NewRedisPersistence(addr string) (*RedisPersistence, error) {
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
-	log.Printf("✅ Connected to Redis at %s", addr)
+	slog.Info("connected to Redis", "addr", opts.Addr, "db", opts.DB)
 	return &RedisPersistence{
-		client: client,
-		ctx:    ctx,
+		client:        client,
+		opts:          opts,
+		ctx:           ctx,
+		retryAttempts: DefaultRedisRetryAttempts,
+		retryBackoff:  DefaultRedisRetryBackoff,
+		healthy:       true,
+
+		statsRetention:       DefaultStatsRetention,
+		completionEventLimit: DefaultCompletionEventLimit,
+		completionEventTTL:   DefaultCompletionEventTTL,
 	}, nil
 }
 
-// Close closes the Redis connection
+// Close stops the health check and closes the Redis connection
 func (rp *RedisPersistence) Close() error {
-	return rp.client.Close()
+	rp.mu.Lock()
+	rp.stopHealthCheckLocked()
+	rp.closed = true
+	client := rp.client
+	rp.mu.Unlock()
+
+	return client.Close()
+}
+
+// Ping checks that Redis is reachable
+func (rp *RedisPersistence) Ping(ctx context.Context) error {
+	return rp.getClient().Ping(ctx).Err()
 }
 
 // SaveSettings saves pomodoro settings to Redis
 func (rp *RedisPersistence) SaveSettings(settings *PomodoroSettings) error {
-	err := rp.client.HSet(rp.ctx, "pomodoroSettings", map[string]interface{}{
-		"workTime":       settings.WorkTime,
-		"shortBreakTime": settings.ShortBreakTime,
-		"longBreakTime":  settings.LongBreakTime,
-		"scheduling":     settings.Scheduling,
-	}).Err()
+	err := rp.withRetry("settings save", func(client *redis.Client) error {
+		return client.HSet(rp.ctx, "pomodoroSettings", map[string]interface{}{
+			"workTime":        settings.WorkTime,
+			"shortBreakTime":  settings.ShortBreakTime,
+			"longBreakTime":   settings.LongBreakTime,
+			"customBreakTime": settings.CustomBreakTime,
+			"idleTimeout":     settings.IdleTimeout,
+			"dailyWorkGoal":   settings.DailyWorkGoal,
+			"waitForStart":    strconv.FormatBool(settings.WaitForStart),
+			"scheduling":      settings.Scheduling,
+		}).Err()
+	})
 
 	if err != nil {
 		return fmt.Errorf("failed to save settings to Redis: %w", err)
 	}
 
-	log.Printf("Saved pomodoro settings to Redis")
+	slog.Info("saved pomodoro settings to Redis")
 	return nil
 }
 
 // LoadSettings loads pomodoro settings from Redis
 func (rp *RedisPersistence) LoadSettings() (*PomodoroSettings, error) {
-	result, err := rp.client.HGetAll(rp.ctx, "pomodoroSettings").Result()
+	result, err := rp.getClient().HGetAll(rp.ctx, "pomodoroSettings").Result()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load settings from Redis: %w", err)
 	}
@@ -89,10 +240,11 @@ func (rp *RedisPersistence) LoadSettings() (*PomodoroSettings, error) {
 	if len(result) == 0 {
 		// Return default settings if none exist
 		return &PomodoroSettings{
-			WorkTime:       25,
-			ShortBreakTime: 5,
-			LongBreakTime:  15,
-			Scheduling:     "default",
+			WorkTime:        25,
+			ShortBreakTime:  5,
+			LongBreakTime:   15,
+			CustomBreakTime: 10,
+			Scheduling:      "default",
 		}, nil
 	}
 
@@ -117,6 +269,30 @@ func (rp *RedisPersistence) LoadSettings() (*PomodoroSettings, error) {
 		}
 	}
 
+	if customBreakTime, ok := result["customBreakTime"]; ok {
+		if _, err := fmt.Sscanf(customBreakTime, "%d", &settings.CustomBreakTime); err != nil {
+			settings.CustomBreakTime = 10 // default
+		}
+	}
+
+	if idleTimeout, ok := result["idleTimeout"]; ok {
+		if _, err := fmt.Sscanf(idleTimeout, "%d", &settings.IdleTimeout); err != nil {
+			settings.IdleTimeout = 0 // disabled
+		}
+	}
+
+	if dailyWorkGoal, ok := result["dailyWorkGoal"]; ok {
+		if _, err := fmt.Sscanf(dailyWorkGoal, "%d", &settings.DailyWorkGoal); err != nil {
+			settings.DailyWorkGoal = 0 // no goal
+		}
+	}
+
+	if waitForStart, ok := result["waitForStart"]; ok {
+		if wait, err := strconv.ParseBool(waitForStart); err == nil {
+			settings.WaitForStart = wait
+		}
+	}
+
 	if scheduling, ok := result["scheduling"]; ok {
 		settings.Scheduling = scheduling
 	} else {
@@ -126,27 +302,120 @@ func (rp *RedisPersistence) LoadSettings() (*PomodoroSettings, error) {
 	return settings, nil
 }
 
-// SaveSystemState saves the current system state to Redis
+// behaviorSettingsDocument is the stored form of BehaviorSettings, with the
+// durations in milliseconds
+type behaviorSettingsDocument struct {
+	AutoStartNext  bool  `json:"autoStartNext"`
+	StrictResume   bool  `json:"strictResume"`
+	IdleTimeoutMs  int64 `json:"idleTimeoutMs"`
+	RepeatWindowMs int64 `json:"repeatWindowMs"`
+	// PauseWhenUnwatched and UnwatchedGraceMs are absent in settings saved
+	// before presence tracking; they decode to off and DefaultUnwatchedGrace
+	PauseWhenUnwatched bool   `json:"pauseWhenUnwatched"`
+	UnwatchedGraceMs   *int64 `json:"unwatchedGraceMs,omitempty"`
+}
+
+// SaveBehaviorSettings saves the behavior settings to Redis
+func (rp *RedisPersistence) SaveBehaviorSettings(behavior BehaviorSettings) error {
+	graceMs := behavior.UnwatchedGrace.Milliseconds()
+	data, err := json.Marshal(behaviorSettingsDocument{
+		AutoStartNext:  behavior.AutoStartNext,
+		StrictResume:   behavior.StrictResume,
+		IdleTimeoutMs:  behavior.IdleTimeout.Milliseconds(),
+		RepeatWindowMs: behavior.RepeatWindow.Milliseconds(),
+
+		PauseWhenUnwatched: behavior.PauseWhenUnwatched,
+		UnwatchedGraceMs:   &graceMs,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode behavior settings: %w", err)
+	}
+
+	err = rp.withRetry("behavior settings save", func(client *redis.Client) error {
+		return client.Set(rp.ctx, behaviorSettingsKey, data, 0).Err()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save behavior settings to Redis: %w", err)
+	}
+	return nil
+}
+
+// LoadBehaviorSettings returns the behavior settings stored in Redis, or nil
+// when none have been saved yet. Stored settings that no longer validate are
+// reported as ErrInvalidBehavior.
+func (rp *RedisPersistence) LoadBehaviorSettings() (*BehaviorSettings, error) {
+	data, err := rp.getClient().Get(rp.ctx, behaviorSettingsKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load behavior settings from Redis: %w", err)
+	}
+
+	var doc behaviorSettingsDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode: %v", ErrInvalidBehavior, err)
+	}
+	behavior := &BehaviorSettings{
+		AutoStartNext: doc.AutoStartNext,
+		StrictResume:  doc.StrictResume,
+		IdleTimeout:   time.Duration(doc.IdleTimeoutMs) * time.Millisecond,
+		RepeatWindow:  time.Duration(doc.RepeatWindowMs) * time.Millisecond,
+
+		PauseWhenUnwatched: doc.PauseWhenUnwatched,
+		UnwatchedGrace:     DefaultUnwatchedGrace,
+	}
+	if doc.UnwatchedGraceMs != nil {
+		behavior.UnwatchedGrace = time.Duration(*doc.UnwatchedGraceMs) * time.Millisecond
+	}
+	if err := behavior.Validate(); err != nil {
+		return nil, err
+	}
+	return behavior, nil
+}
+
+// SaveSystemState saves the current system state to Redis as a single JSON
+// document. The check and the write run atomically in a script, so a delayed
+// save cannot overwrite a newer state; such a save returns ErrStaleSystemState.
 func (rp *RedisPersistence) SaveSystemState(state *SystemState) error {
-	err := rp.client.HSet(rp.ctx, "systemState", map[string]interface{}{
-		"currentSession": state.CurrentSession,
-		"endTime":        state.EndTime.Format(time.RFC3339),
-		"timezone":       state.Timezone,
-		"state":          state.State,
-		"timeRemaining":  state.TimeRemaining,
-		"isRunning":      state.IsRunning,
-		"isPaused":       state.IsPaused,
-	}).Err()
+	doc, err := json.Marshal(systemStateDocument{
+		SchemaVersion: systemStateSchemaVersion,
+		SystemState:   *state,
+		EndTime:       state.EndTime.UnixMilli(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode system state: %w", err)
+	}
+
+	var written bool
+	rp.stateSaves.Add(1)
+	err = rp.withRetry("system state save", func(client *redis.Client) error {
+		result, err := saveSystemStateScript.Run(rp.ctx, client,
+			[]string{systemStateKey, legacySystemStateKey}, state.Version, doc).Int()
+		written = result == 1
+		return err
+	})
 
 	if err != nil {
 		return fmt.Errorf("failed to save system state to Redis: %w", err)
 	}
+	if !written {
+		return fmt.Errorf("system state version %d not saved: %w", state.Version, ErrStaleSystemState)
+	}
 
-	log.Printf("Saved system state to Redis: session=%d, state=%s, remaining=%dms",
-		state.CurrentSession, state.State, state.TimeRemaining)
+	slog.Debug("saved system state to Redis",
+		"session", state.CurrentSession,
+		"state", state.State,
+		"remaining_ms", state.TimeRemaining)
 	return nil
 }
 
+// SystemStateSaves returns how many system state saves have been sent to Redis,
+// not counting retries
+func (rp *RedisPersistence) SystemStateSaves() int64 {
+	return rp.stateSaves.Load()
+}
+
 // getSystemTimezone returns the current system timezone
 func (rp *RedisPersistence) getSystemTimezone() string {
 	zone, _ := time.Now().Zone()
@@ -159,9 +428,81 @@ func (rp *RedisPersistence) getSystemTimezoneName() string {
 	return loc.String()
 }
 
-// LoadSystemState loads the system state from Redis
+// LoadSystemState loads the system state from Redis, falling back to the
+// legacy hash when no JSON document has been saved yet
 func (rp *RedisPersistence) LoadSystemState() (*SystemState, error) {
-	result, err := rp.client.HGetAll(rp.ctx, "systemState").Result()
+	load, err := rp.LoadSystemStateWithRepairs()
+	if err != nil {
+		return nil, err
+	}
+	return load.Repaired, nil
+}
+
+// SystemStateLoad is the system state as stored in Redis alongside the state
+// LoadSystemState returns after repairing it
+type SystemStateLoad struct {
+	Raw      *SystemState
+	Repaired *SystemState
+	// Repairs describes each inconsistency fixed; empty when the state was valid
+	Repairs []string
+}
+
+// LoadSystemStateWithRepairs loads the system state like LoadSystemState and
+// also returns the state before repair and the repairs applied, so operators
+// can tell when Redis holds bad data
+func (rp *RedisPersistence) LoadSystemStateWithRepairs() (*SystemStateLoad, error) {
+	raw, err := rp.loadStoredSystemState()
+	if err != nil {
+		return nil, err
+	}
+
+	repaired := *raw
+	repairs := rp.validateAndRepairState(&repaired)
+	return &SystemStateLoad{Raw: raw, Repaired: &repaired, Repairs: repairs}, nil
+}
+
+// loadStoredSystemState decodes the system state from Redis without repairing it
+func (rp *RedisPersistence) loadStoredSystemState() (*SystemState, error) {
+	data, err := rp.getClient().Get(rp.ctx, systemStateKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return rp.loadLegacySystemState()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load system state from Redis: %w", err)
+	}
+
+	var header struct {
+		SchemaVersion int `json:"schemaVersion"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("failed to decode system state: %w", err)
+	}
+
+	switch {
+	case header.SchemaVersion > systemStateSchemaVersion:
+		return nil, fmt.Errorf("system state schema version %d is newer than supported version %d",
+			header.SchemaVersion, systemStateSchemaVersion)
+	case header.SchemaVersion == 1:
+		// endTime is an RFC3339 string, which SystemState decodes itself
+		state := &SystemState{}
+		if err := json.Unmarshal(data, state); err != nil {
+			return nil, fmt.Errorf("failed to decode system state: %w", err)
+		}
+		return state, nil
+	}
+
+	var doc systemStateDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode system state: %w", err)
+	}
+	doc.SystemState.EndTime = time.UnixMilli(doc.EndTime)
+	return &doc.SystemState, nil
+}
+
+// loadLegacySystemState loads the system state from the hash used before the
+// JSON document
+func (rp *RedisPersistence) loadLegacySystemState() (*SystemState, error) {
+	result, err := rp.getClient().HGetAll(rp.ctx, legacySystemStateKey).Result()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load system state from Redis: %w", err)
 	}
@@ -214,7 +555,7 @@ func (rp *RedisPersistence) LoadSystemState() (*SystemState, error) {
 	// Parse time remaining
 	if timeRemaining, ok := result["timeRemaining"]; ok {
 		if _, err := fmt.Sscanf(timeRemaining, "%d", &state.TimeRemaining); err != nil {
-			log.Printf("⚠️ Invalid time remaining in Redis, defaulting to 0: %v", err)
+			slog.Warn("invalid time remaining in Redis, defaulting to 0", "value", timeRemaining, "error", err)
 			state.TimeRemaining = 0
 		}
 	}
@@ -229,81 +570,504 @@ func (rp *RedisPersistence) LoadSystemState() (*SystemState, error) {
 		state.IsPaused = (isPaused == "true" || isPaused == "1")
 	}
 
-	// Validate and repair the loaded state
-	rp.validateAndRepairState(state)
+	// States saved before tags existed have no tag
+	state.Tag = result["tag"]
+
+	// States saved before versioning are unversioned
+	if version, ok := result["version"]; ok {
+		if _, err := fmt.Sscanf(version, "%d", &state.Version); err != nil {
+			state.Version = 0
+		}
+	}
 
 	return state, nil
 }
 
-// validateAndRepairState validates loaded state and repairs inconsistencies
-func (rp *RedisPersistence) validateAndRepairState(state *SystemState) {
-	repairs := 0
+// validateAndRepairState validates loaded state, repairs inconsistencies and
+// returns a description of each repair
+func (rp *RedisPersistence) validateAndRepairState(state *SystemState) []string {
+	var repairs []string
+	repair := func(format string, args ...any) {
+		description := fmt.Sprintf(format, args...)
+		slog.Warn("repairing Redis state", "repair", description)
+		repairs = append(repairs, description)
+	}
 
-	// Validate session range (assuming max 100 sessions for safety)
-	if state.CurrentSession < 0 || state.CurrentSession > 100 {
-		log.Printf("⚠️ Invalid session number %d, resetting to 0", state.CurrentSession)
+	// Validate session range; the schedule is not known here, so only
+	// indexes no schedule can reach are repaired
+	if state.CurrentSession < 0 || state.CurrentSession >= MaxSessions {
+		repair("Invalid session number %d, resetting to 0", state.CurrentSession)
 		state.CurrentSession = 0
-		repairs++
 	}
 
 	// Validate state string
 	validStates := map[string]bool{
-		string(StateIdle):       true,
-		string(StateWorking):    true,
-		string(StateShortBreak): true,
-		string(StateLongBreak):  true,
-		string(StatePaused):     true,
+		string(StateIdle):        true,
+		string(StateWorking):     true,
+		string(StateShortBreak):  true,
+		string(StateLongBreak):   true,
+		string(StateCustomBreak): true,
+		string(StatePaused):      true,
+		string(StateWaiting):     true,
 	}
 	if !validStates[state.State] {
-		log.Printf("⚠️ Invalid state '%s', resetting to 'idle'", state.State)
+		repair("Invalid state '%s', resetting to 'idle'", state.State)
 		state.State = string(StateIdle)
-		repairs++
 	}
 
 	// Validate state consistency
 	if state.State == string(StateIdle) && (state.IsRunning || state.IsPaused || state.TimeRemaining > 0) {
-		log.Printf("⚠️ Idle state has running flags, fixing")
+		repair("Idle state has running flags, fixing")
 		state.IsRunning = false
 		state.IsPaused = false
 		state.TimeRemaining = 0
-		repairs++
 	}
 
 	if state.IsRunning && state.IsPaused {
-		log.Printf("⚠️ Cannot be both running and paused, setting to paused")
+		repair("Cannot be both running and paused, setting to paused")
 		state.IsRunning = false
-		repairs++
 	}
 
 	if state.TimeRemaining < 0 {
-		log.Printf("⚠️ Negative time remaining %d, setting to 0", state.TimeRemaining)
+		repair("Negative time remaining %d, setting to 0", state.TimeRemaining)
 		state.TimeRemaining = 0
-		repairs++
 	}
 
-	if repairs > 0 {
-		log.Printf("🔧 Repaired %d inconsistencies in Redis state", repairs)
+	if len(repairs) > 0 {
+		slog.Info("repaired inconsistencies in Redis state", "count", len(repairs))
 	}
+	return repairs
 }
 
-// SaveSessionStatistics saves session statistics to Redis
+const (
+	// DefaultStatsRetention is how long the daily session statistics keys are kept
+	DefaultStatsRetention = 30 * 24 * time.Hour
+	// MaxStatsRetention is the longest retention accepted by SetStatsRetention
+	MaxStatsRetention = 3650 * 24 * time.Hour
+)
+
+// SetStatsRetention sets how long a daily session statistics key is kept after
+// its last write, applied as the key's TTL. Keys written before the change
+// keep their TTL until they are written again.
+func (rp *RedisPersistence) SetStatsRetention(retention time.Duration) error {
+	if retention <= 0 || retention > MaxStatsRetention {
+		return fmt.Errorf("statistics retention must be greater than 0 and at most %v, got %v", MaxStatsRetention, retention)
+	}
+
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	rp.statsRetention = retention
+	return nil
+}
+
+// GetStatsRetention returns how long the daily session statistics keys are kept
+func (rp *RedisPersistence) GetStatsRetention() time.Duration {
+	rp.mu.RLock()
+	defer rp.mu.RUnlock()
+	return rp.statsRetention
+}
+
+// SaveSessionStatistics saves session statistics to Redis. Each key aggregates
+// the sessions of one type completed on one day, so counts survive restarts.
 func (rp *RedisPersistence) SaveSessionStatistics(sessionType string, duration time.Duration, completedAt time.Time) error {
+	return rp.SaveSessionRecord(SessionRecord{
+		State:     ClockState(sessionType),
+		Duration:  duration,
+		Completed: completedAt,
+	})
+}
+
+// SaveSessionRecord adds a completed session to its daily statistics key. The
+// type, duration, completedAt and tag fields describe the latest session, and
+// a tagged session also increments a "tag:<tag>" count.
+func (rp *RedisPersistence) SaveSessionRecord(record SessionRecord) error {
+	return rp.saveSessionRecord(record, nil)
+}
+
+// SaveRecordedSession saves a completed session to the statistics and, in the
+// same transaction, marks it as the last recorded session
+func (rp *RedisPersistence) SaveRecordedSession(marker RecordedSession, record SessionRecord) error {
+	data, err := json.Marshal(marker)
+	if err != nil {
+		return fmt.Errorf("failed to encode recorded session: %w", err)
+	}
+	return rp.saveSessionRecord(record, data)
+}
+
+// saveSessionRecord saves record to the statistics and the session history
+// together with an encoded RecordedSession, if one is given
+func (rp *RedisPersistence) saveSessionRecord(record SessionRecord, marker []byte) error {
+	sessionType, duration, completedAt := string(record.State), record.Duration, record.Completed
+	retention := rp.GetStatsRetention()
 	key := fmt.Sprintf("session_stats:%s:%s", sessionType, completedAt.Format("2006-01-02"))
+	history, err := json.Marshal(sessionRecordDocument{
+		State:     record.State,
+		Duration:  record.Duration,
+		Completed: record.Completed,
+		Tag:       record.Tag,
+		ID:        time.Now().UnixNano(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode session record: %w", err)
+	}
+	expired := strconv.FormatInt(time.Now().Add(-retention).UnixMilli(), 10)
 
 	sessionData := map[string]interface{}{
 		"type":        sessionType,
 		"duration":    int(duration.Seconds()),
 		"completedAt": completedAt.Format(time.RFC3339),
+		"tag":         record.Tag,
 	}
 
-	err := rp.client.HSet(rp.ctx, key, sessionData).Err()
+	err = rp.withRetry("session statistics save", func(client *redis.Client) error {
+		pipe := client.TxPipeline()
+		pipe.HSet(rp.ctx, key, sessionData)
+		pipe.HIncrBy(rp.ctx, key, "count", 1)
+		pipe.HIncrBy(rp.ctx, key, "totalDuration", int64(duration.Seconds()))
+		if record.Tag != "" {
+			pipe.HIncrBy(rp.ctx, key, "tag:"+record.Tag, 1)
+		}
+		pipe.Expire(rp.ctx, key, retention)
+		pipe.ZAdd(rp.ctx, sessionHistoryKey, redis.Z{
+			Score:  float64(completedAt.UnixMilli()),
+			Member: history,
+		})
+		pipe.ZRemRangeByScore(rp.ctx, sessionHistoryKey, "-inf", "("+expired)
+		pipe.Expire(rp.ctx, sessionHistoryKey, retention)
+		if marker != nil {
+			pipe.Set(rp.ctx, lastRecordedSessionKey, marker, 0)
+		}
+		_, err := pipe.Exec(rp.ctx)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("failed to save session statistics: %w", err)
 	}
 
-	// Set expiration for 30 days
-	rp.client.Expire(rp.ctx, key, 30*24*time.Hour)
+	slog.Debug("saved session statistics", "type", sessionType, "duration", duration)
+	return nil
+}
+
+// LoadLastRecordedSession returns the last session marked by
+// SaveRecordedSession, or nil when none has been
+func (rp *RedisPersistence) LoadLastRecordedSession() (*RecordedSession, error) {
+	data, err := rp.getClient().Get(rp.ctx, lastRecordedSessionKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load last recorded session: %w", err)
+	}
+
+	var marker RecordedSession
+	if err := json.Unmarshal(data, &marker); err != nil {
+		return nil, fmt.Errorf("failed to decode last recorded session: %w", err)
+	}
+	return &marker, nil
+}
+
+const (
+	// DefaultCompletionEventLimit is how many completion events are kept for
+	// clients catching up after a reconnect
+	DefaultCompletionEventLimit = 50
+	// MaxCompletionEventLimit is the largest limit accepted by SetCompletionEventBuffer
+	MaxCompletionEventLimit = 1000
+	// DefaultCompletionEventTTL is how long a completion event is kept
+	DefaultCompletionEventTTL = 24 * time.Hour
+	// MaxCompletionEventTTL is the longest expiry accepted by SetCompletionEventBuffer
+	MaxCompletionEventTTL = 7 * 24 * time.Hour
+)
+
+// completionEventDocument is the stored form of a CompletionEvent
+type completionEventDocument struct {
+	CompletedState    ClockState `json:"completedState"`
+	NextState         ClockState `json:"nextState"`
+	CycleFinished     bool       `json:"cycleFinished"`
+	SessionNumber     int        `json:"sessionNumber"`
+	WorkMinutes       int        `json:"workMinutes"`
+	ShortBreakMinutes int        `json:"shortBreakMinutes"`
+	LongBreakMinutes  int        `json:"longBreakMinutes"`
+	CompletedAt       time.Time  `json:"completedAt"`
+}
+
+// SetCompletionEventBuffer sets how many completion events are kept and how
+// long each is kept. Events beyond the limit are dropped oldest first.
+func (rp *RedisPersistence) SetCompletionEventBuffer(limit int, ttl time.Duration) error {
+	if limit < 1 || limit > MaxCompletionEventLimit {
+		return fmt.Errorf("completion event limit must be between 1 and %d, got %d", MaxCompletionEventLimit, limit)
+	}
+	if ttl <= 0 || ttl > MaxCompletionEventTTL {
+		return fmt.Errorf("completion event expiry must be greater than 0 and at most %v, got %v", MaxCompletionEventTTL, ttl)
+	}
+
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	rp.completionEventLimit = limit
+	rp.completionEventTTL = ttl
+	return nil
+}
+
+// GetCompletionEventBuffer returns the completion event limit and expiry
+func (rp *RedisPersistence) GetCompletionEventBuffer() (limit int, ttl time.Duration) {
+	rp.mu.RLock()
+	defer rp.mu.RUnlock()
+	return rp.completionEventLimit, rp.completionEventTTL
+}
+
+// SaveCompletionEvent appends a completion event to the buffer, dropping
+// events that expired or no longer fit
+func (rp *RedisPersistence) SaveCompletionEvent(event CompletionEvent) error {
+	limit, ttl := rp.GetCompletionEventBuffer()
+	data, err := json.Marshal(completionEventDocument(event))
+	if err != nil {
+		return fmt.Errorf("failed to encode completion event: %w", err)
+	}
+	expired := strconv.FormatInt(time.Now().Add(-ttl).UnixMilli(), 10)
+
+	err = rp.withRetry("completion event save", func(client *redis.Client) error {
+		pipe := client.TxPipeline()
+		pipe.ZAdd(rp.ctx, completionEventsKey, redis.Z{
+			Score:  float64(event.CompletedAt.UnixMilli()),
+			Member: data,
+		})
+		pipe.ZRemRangeByScore(rp.ctx, completionEventsKey, "-inf", "("+expired)
+		pipe.ZRemRangeByRank(rp.ctx, completionEventsKey, 0, int64(-limit-1))
+		pipe.Expire(rp.ctx, completionEventsKey, ttl)
+		_, err := pipe.Exec(rp.ctx)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save completion event to Redis: %w", err)
+	}
+	return nil
+}
+
+// LoadCompletionEvents returns the buffered completion events completed after
+// since, oldest first. Events are stored to the millisecond and since is
+// compared exclusively at that precision, so passing back the time of the last
+// event seen does not return it again, yet keeps any completed in the same
+// second. A zero since returns every event.
+func (rp *RedisPersistence) LoadCompletionEvents(since time.Time) ([]CompletionEvent, error) {
+	// Events past the expiry may not have been pruned yet
+	_, ttl := rp.GetCompletionEventBuffer()
+	expired := time.Now().Add(-ttl).UnixMilli()
+	from := strconv.FormatInt(expired, 10)
+	if !since.IsZero() && since.UnixMilli() >= expired {
+		from = "(" + strconv.FormatInt(since.UnixMilli(), 10)
+	}
+
+	members, err := rp.getClient().ZRangeByScore(rp.ctx, completionEventsKey, &redis.ZRangeBy{
+		Min: from,
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load completion events from Redis: %w", err)
+	}
+
+	events := make([]CompletionEvent, 0, len(members))
+	for _, member := range members {
+		var doc completionEventDocument
+		if err := json.Unmarshal([]byte(member), &doc); err != nil {
+			slog.Warn("skipping undecodable completion event", "error", err)
+			continue
+		}
+		events = append(events, CompletionEvent(doc))
+	}
+	return events, nil
+}
+
+// SaveTemplate stores a named schedule template, replacing any of the same
+// name. The schedule is checked with ValidateSchedule and the durations must be
+// whole minutes within the session limits.
+func (rp *RedisPersistence) SaveTemplate(name string, schedule []ClockState, work, shortBreak, longBreak time.Duration) error {
+	for _, d := range []time.Duration{work, shortBreak, longBreak} {
+		if d%time.Minute != 0 {
+			return fmt.Errorf("%w: durations must be whole minutes, got %v", ErrInvalidTemplate, d)
+		}
+	}
+	template := &ScheduleTemplate{
+		Name:           name,
+		Schedule:       schedule,
+		WorkTime:       int(work.Minutes()),
+		ShortBreakTime: int(shortBreak.Minutes()),
+		LongBreakTime:  int(longBreak.Minutes()),
+	}
+	if err := template.Validate(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(template)
+	if err != nil {
+		return fmt.Errorf("failed to encode schedule template: %w", err)
+	}
+	err = rp.withRetry("template save", func(client *redis.Client) error {
+		return client.HSet(rp.ctx, scheduleTemplatesKey, name, data).Err()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save schedule template to Redis: %w", err)
+	}
 
-	log.Printf("Saved session statistics: type=%s, duration=%v", sessionType, duration)
+	slog.Info("saved schedule template to Redis", "name", name)
 	return nil
 }
+
+// LoadTemplate returns the named schedule template, or ErrTemplateNotFound.
+// A stored template that no longer validates is reported as ErrInvalidTemplate.
+func (rp *RedisPersistence) LoadTemplate(name string) (*ScheduleTemplate, error) {
+	data, err := rp.getClient().HGet(rp.ctx, scheduleTemplatesKey, name).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schedule template: %w", err)
+	}
+
+	var template ScheduleTemplate
+	if err := json.Unmarshal(data, &template); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode %s: %v", ErrInvalidTemplate, name, err)
+	}
+	if err := template.Validate(); err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// ListTemplates returns every stored schedule template sorted by name,
+// skipping any that no longer decode
+func (rp *RedisPersistence) ListTemplates() ([]ScheduleTemplate, error) {
+	result, err := rp.getClient().HGetAll(rp.ctx, scheduleTemplatesKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schedule templates: %w", err)
+	}
+
+	templates := make([]ScheduleTemplate, 0, len(result))
+	for name, data := range result {
+		var template ScheduleTemplate
+		if err := json.Unmarshal([]byte(data), &template); err != nil {
+			slog.Warn("skipping undecodable schedule template", "name", name, "error", err)
+			continue
+		}
+		templates = append(templates, template)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+// PersistedStatistics holds statistics totals reconstructed from Redis
+type PersistedStatistics struct {
+	WorkSessions int
+	ShortBreaks  int
+	LongBreaks   int
+	WorkTime     time.Duration
+	BreakTime    time.Duration
+
+	CustomBreaks    int
+	CustomBreakTime time.Duration
+}
+
+// LoadSessionStatistics scans the session_stats:* keys and sums them into totals.
+// Keys expire after the statistics retention, 30 days by default, so the totals
+// only cover that window; keys that
+// expire or vanish between the scan and the read are skipped.
+func (rp *RedisPersistence) LoadSessionStatistics() (*PersistedStatistics, error) {
+	stats := &PersistedStatistics{}
+
+	client := rp.getClient()
+	iter := client.Scan(rp.ctx, 0, "session_stats:*", 100).Iterator()
+	for iter.Next(rp.ctx) {
+		result, err := client.HGetAll(rp.ctx, iter.Val()).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load session statistics: %w", err)
+		}
+		if len(result) == 0 {
+			continue
+		}
+
+		// Keys written before aggregation only hold a single session
+		count := 1
+		seconds := 0
+		if countStr, ok := result["count"]; ok {
+			fmt.Sscanf(countStr, "%d", &count)
+			fmt.Sscanf(result["totalDuration"], "%d", &seconds)
+		} else {
+			fmt.Sscanf(result["duration"], "%d", &seconds)
+		}
+		duration := time.Duration(seconds) * time.Second
+
+		switch ClockState(result["type"]) {
+		case StateWorking:
+			stats.WorkSessions += count
+			stats.WorkTime += duration
+		case StateShortBreak:
+			stats.ShortBreaks += count
+			stats.BreakTime += duration
+		case StateLongBreak:
+			stats.LongBreaks += count
+			stats.BreakTime += duration
+		case StateCustomBreak:
+			stats.CustomBreaks += count
+			stats.CustomBreakTime += duration
+		default:
+			slog.Warn("skipping session statistics of unknown type", "type", result["type"], "key", iter.Val())
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan session statistics: %w", err)
+	}
+
+	return stats, nil
+}
+
+// LoadSessionHistory returns the persisted session records within the
+// statistics retention, oldest first. Sessions saved before the history was
+// persisted are only included in LoadSessionStatistics.
+func (rp *RedisPersistence) LoadSessionHistory() ([]SessionRecord, error) {
+	from := time.Now().Add(-rp.GetStatsRetention()).UnixMilli()
+	members, err := rp.getClient().ZRangeByScore(rp.ctx, sessionHistoryKey, &redis.ZRangeBy{
+		Min: strconv.FormatInt(from, 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session history from Redis: %w", err)
+	}
+
+	records := make([]SessionRecord, 0, len(members))
+	for _, member := range members {
+		var doc sessionRecordDocument
+		if err := json.Unmarshal([]byte(member), &doc); err != nil {
+			slog.Warn("skipping undecodable session record", "error", err)
+			continue
+		}
+		records = append(records, SessionRecord{
+			State:     doc.State,
+			Duration:  doc.Duration,
+			Completed: doc.Completed,
+			Tag:       doc.Tag,
+		})
+	}
+	return records, nil
+}
+
+// DeleteSessionStatistics removes all persisted session statistics keys,
+// including the session history, and returns the number of keys deleted
+func (rp *RedisPersistence) DeleteSessionStatistics() (int64, error) {
+	client := rp.getClient()
+	deleted, err := client.Del(rp.ctx, sessionHistoryKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete session history: %w", err)
+	}
+	iter := client.Scan(rp.ctx, 0, "session_stats:*", 100).Iterator()
+	for iter.Next(rp.ctx) {
+		n, err := client.Del(rp.ctx, iter.Val()).Result()
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete session statistics: %w", err)
+		}
+		deleted += n
+	}
+	if err := iter.Err(); err != nil {
+		return deleted, fmt.Errorf("failed to scan session statistics: %w", err)
+	}
+
+	slog.Info("deleted session statistics keys", "count", deleted)
+	return deleted, nil
+}
@@ -0,0 +1,196 @@
+package clock
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// DefaultRedisRetryAttempts is how many times a Redis write is tried before giving up
+	DefaultRedisRetryAttempts = 3
+	// DefaultRedisRetryBackoff is the wait before the first retry; it doubles on each retry
+	DefaultRedisRetryBackoff = 100 * time.Millisecond
+	// maxRedisRetryBackoff caps the doubling so a large attempt count stays bounded
+	maxRedisRetryBackoff = 5 * time.Second
+
+	// DefaultRedisHealthCheckInterval is how often the Redis connection is pinged
+	DefaultRedisHealthCheckInterval = 10 * time.Second
+	// redisHealthCheckTimeout bounds a single health check ping or reconnect
+	redisHealthCheckTimeout = 5 * time.Second
+)
+
+// SetRetryPolicy configures how Redis writes are retried. attempts counts the
+// first try, so 1 disables retries; backoff is the wait before the first retry
+// and doubles after each one.
+func (rp *RedisPersistence) SetRetryPolicy(attempts int, backoff time.Duration) error {
+	if attempts < 1 {
+		return fmt.Errorf("retry attempts must be at least 1, got %d", attempts)
+	}
+	if backoff < 0 {
+		return fmt.Errorf("retry backoff must not be negative, got %v", backoff)
+	}
+
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	rp.retryAttempts = attempts
+	rp.retryBackoff = backoff
+	return nil
+}
+
+// GetRetryPolicy returns the configured retry attempts and initial backoff
+func (rp *RedisPersistence) GetRetryPolicy() (attempts int, backoff time.Duration) {
+	rp.mu.RLock()
+	defer rp.mu.RUnlock()
+	return rp.retryAttempts, rp.retryBackoff
+}
+
+// SetHealthCallback sets a function called whenever Redis goes from healthy to
+// failing or back. err is the failure that degraded persistence, nil on recovery.
+func (rp *RedisPersistence) SetHealthCallback(callback func(healthy bool, err error)) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	rp.onHealthChange = callback
+}
+
+// IsHealthy reports whether the last Redis operation or health check succeeded
+func (rp *RedisPersistence) IsHealthy() bool {
+	rp.mu.RLock()
+	defer rp.mu.RUnlock()
+	return rp.healthy
+}
+
+// getClient returns the current client; the health check may replace it
+func (rp *RedisPersistence) getClient() *redis.Client {
+	rp.mu.RLock()
+	defer rp.mu.RUnlock()
+	return rp.client
+}
+
+// withRetry runs a write operation, retrying failures with exponential backoff.
+// Note that a retried write may be applied twice if Redis executed it but the
+// reply was lost.
+func (rp *RedisPersistence) withRetry(op string, fn func(client *redis.Client) error) error {
+	attempts, backoff := rp.GetRetryPolicy()
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = fn(rp.getClient()); err == nil {
+			rp.setHealthy(true, nil)
+			return nil
+		}
+		if attempt == attempts {
+			break
+		}
+
+		slog.Warn("Redis write failed, retrying",
+			"op", op,
+			"attempt", attempt,
+			"attempts", attempts,
+			"backoff", backoff,
+			"addr", rp.opts.Addr,
+			"error", err)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxRedisRetryBackoff)
+	}
+
+	rp.setHealthy(false, err)
+	return err
+}
+
+// setHealthy records the connection health and reports transitions
+func (rp *RedisPersistence) setHealthy(healthy bool, err error) {
+	rp.mu.Lock()
+	changed := rp.healthy != healthy
+	rp.healthy = healthy
+	callback := rp.onHealthChange
+	rp.mu.Unlock()
+
+	// Called outside the lock so the callback may use the persistence
+	if changed && callback != nil {
+		callback(healthy, err)
+	}
+}
+
+// StartHealthCheck pings Redis every interval in the background and replaces
+// the client when the ping fails, until Close is called. Calling it again
+// restarts the check with the new interval.
+func (rp *RedisPersistence) StartHealthCheck(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("health check interval must be positive, got %v", interval)
+	}
+
+	rp.mu.Lock()
+	rp.stopHealthCheckLocked()
+	stop := make(chan struct{})
+	rp.healthStop = stop
+	rp.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				rp.checkHealth()
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// stopHealthCheckLocked stops the background health check, if running.
+// The caller must hold rp.mu.
+func (rp *RedisPersistence) stopHealthCheckLocked() {
+	if rp.healthStop != nil {
+		close(rp.healthStop)
+		rp.healthStop = nil
+	}
+}
+
+// checkHealth pings Redis and reconnects with a fresh client if the ping fails
+func (rp *RedisPersistence) checkHealth() {
+	ctx, cancel := context.WithTimeout(rp.ctx, redisHealthCheckTimeout)
+	defer cancel()
+
+	err := rp.getClient().Ping(ctx).Err()
+	if err == nil {
+		rp.setHealthy(true, nil)
+		return
+	}
+	rp.setHealthy(false, err)
+
+	if err := rp.reconnect(ctx); err != nil {
+		slog.Warn("Redis reconnect failed", "addr", rp.opts.Addr, "error", err)
+		return
+	}
+	rp.setHealthy(true, nil)
+}
+
+// reconnect replaces the client with a new one built from the original options
+func (rp *RedisPersistence) reconnect(ctx context.Context) error {
+	client := redis.NewClient(rp.opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return err
+	}
+
+	rp.mu.Lock()
+	if rp.closed {
+		rp.mu.Unlock()
+		client.Close()
+		return redis.ErrClosed
+	}
+	old := rp.client
+	rp.client = client
+	rp.mu.Unlock()
+
+	old.Close()
+	slog.Info("reconnected to Redis", "addr", rp.opts.Addr)
+	return nil
+}
@@ -2,10 +2,47 @@ package clock
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 )
 
+// ResumePolicy controls what happens to a session that was active when the
+// server stopped
+type ResumePolicy string
+
+const (
+	// ResumeAuto continues a running session where it left off (the default)
+	ResumeAuto ResumePolicy = "auto"
+	// ResumePaused restores a running session paused, waiting for a human to resume it
+	ResumePaused ResumePolicy = "paused"
+	// ResumeIdle discards the saved session and starts idle
+	ResumeIdle ResumePolicy = "idle"
+)
+
+// DefaultResumeGracePeriod is the extra time, beyond the rest of its cycle,
+// that a saved session may be overdue and still be caught up to the present
+const DefaultResumeGracePeriod = 0
+
+// DefaultDuplicateRecordWindow is how close to the last session recorded before
+// a restart a resumed completion of that session is treated as the same one
+const DefaultDuplicateRecordWindow = 10 * time.Second
+
+// maxResumeCatchUpSessions bounds how many elapsed sessions a resume records
+// before giving up and resetting to idle
+const maxResumeCatchUpSessions = 100
+
+// ParseResumePolicy validates a resume policy name; empty means ResumeAuto
+func ParseResumePolicy(s string) (ResumePolicy, error) {
+	switch policy := ResumePolicy(s); policy {
+	case "":
+		return ResumeAuto, nil
+	case ResumeAuto, ResumePaused, ResumeIdle:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid resume policy %q: must be auto, paused or idle", s)
+	}
+}
+
 // ResumeManager handles the logic for resuming clock state from Redis
 type ResumeManager struct {
 	clockRunner *ClockRunner
@@ -21,30 +58,28 @@ func NewResumeManager(clockRunner *ClockRunner) *ResumeManager {
 // ResumeFromRedis loads and resumes the system state from Redis
 func (rm *ResumeManager) ResumeFromRedis() error {
 	if rm.clockRunner.redisPersistence == nil {
-		log.Printf("⚠️ Redis persistence not available, starting with default idle state")
+		slog.Warn("Redis persistence not available, starting idle")
 		return rm.resetToIdle("redis not available")
 	}
 
 	state, err := rm.clockRunner.redisPersistence.LoadSystemState()
 	if err != nil {
-		log.Printf("❌ Failed to load system state from Redis: %v", err)
-		log.Printf("🔄 Falling back to idle state due to Redis error")
+		slog.Error("failed to load system state from Redis, falling back to idle", "error", err)
 		return rm.resetToIdle("redis load failed")
 	}
 
 	// Log the current state from Redis with more details
 	rm.logResumeDebugInfo(state)
 
-	// Check if the server has been offline for too long
-	if rm.shouldResetDueToTimeout(state) {
-		log.Printf("🔄 Server was offline for too long, resetting to idle")
+	// Check if the server has been offline for too long; a paused session
+	// does not count down, so it cannot have expired
+	if !state.IsPaused && rm.shouldResetDueToTimeout(state) {
 		return rm.resetToIdle("server was offline for too long")
 	}
 
 	// Resume based on state
 	if err := rm.resumeBasedOnState(state); err != nil {
-		log.Printf("❌ Failed to resume based on state: %v", err)
-		log.Printf("🔄 Falling back to idle state due to resume error")
+		slog.Error("failed to resume, falling back to idle", "error", err)
 		return rm.resetToIdle("resume failed")
 	}
 
@@ -53,32 +88,65 @@ func (rm *ResumeManager) ResumeFromRedis() error {
 
 // logResumeDebugInfo logs detailed information about the resume process
 func (rm *ResumeManager) logResumeDebugInfo(state *SystemState) {
-	log.Printf("=== RESUME DEBUG INFO ===")
-	log.Printf("Loaded from Redis - Session: %d, State: %s, Timezone: %s",
-		state.CurrentSession, state.State, state.Timezone)
-	log.Printf("Running: %v, Paused: %v, TimeRemaining: %ds",
-		state.IsRunning, state.IsPaused, state.TimeRemaining)
-	log.Printf("End time: %s", state.EndTime.Format("2006-01-02 15:04:05"))
-
 	serverTime := time.Now()
-	log.Printf("Server time: %s", serverTime.Format("2006-01-02 15:04:05"))
-	log.Printf("Time difference: %v", serverTime.Sub(state.EndTime))
+	slog.Info("loaded system state from Redis",
+		"session", state.CurrentSession,
+		"state", state.State,
+		"timezone", state.Timezone,
+		"running", state.IsRunning,
+		"paused", state.IsPaused,
+		"remaining_ms", state.TimeRemaining,
+		"end_time", state.EndTime,
+		"server_time", serverTime,
+		"overdue", serverTime.Sub(state.EndTime))
 }
 
-// shouldResetDueToTimeout checks if the server was offline for too long
+// shouldResetDueToTimeout checks if the server was offline for too long, which
+// is longer than the rest of the saved cycle plus the grace period. Otherwise
+// the saved session and any later sessions that also elapsed are completed as
+// if the server had stayed up.
 func (rm *ResumeManager) shouldResetDueToTimeout(state *SystemState) bool {
 	serverTime := time.Now()
-	if state.EndTime.Before(serverTime) {
-		log.Printf("❌ Server was offline for too long (end time: %s, server time: %s). Resetting clock state.",
-			state.EndTime.Format("2006-01-02 15:04:05"), serverTime.Format("2006-01-02 15:04:05"))
-		return true
+	overdue := serverTime.Sub(state.EndTime)
+	if overdue <= 0 {
+		return false
+	}
+
+	cycleRemaining := rm.cycleRemainingAfter(state.CurrentSession)
+	grace := rm.clockRunner.resumeGracePeriod
+	if overdue < cycleRemaining+grace {
+		slog.Info("session ended while server was down, completing it",
+			"end_time", state.EndTime,
+			"overdue", overdue,
+			"cycle_remaining", cycleRemaining,
+			"grace", grace)
+		return false
+	}
+
+	slog.Warn("server was offline past the end of the cycle",
+		"end_time", state.EndTime,
+		"server_time", serverTime,
+		"cycle_remaining", cycleRemaining,
+		"grace", grace)
+	return true
+}
+
+// cycleRemainingAfter returns the combined duration of the sessions that follow
+// the given one in the cycle
+func (rm *ResumeManager) cycleRemainingAfter(session int) time.Duration {
+	var remaining time.Duration
+	for next := session + 1; ; next++ {
+		_, _, total, duration := rm.clockRunner.sessionManager.GetSessionInfoAt(next)
+		if next >= total {
+			return remaining
+		}
+		remaining += duration
 	}
-	return false
 }
 
 // resetToIdle resets the clock to idle state
 func (rm *ResumeManager) resetToIdle(reason string) error {
-	log.Printf("🔄 Resetting to idle state: %s", reason)
+	slog.Info("resetting clock to idle", "reason", reason)
 
 	// Reset the clock state
 	rm.clockRunner.stateManager.SetState(StateIdle)
@@ -87,55 +155,87 @@ func (rm *ResumeManager) resetToIdle(reason string) error {
 
 	// Save the reset state to Redis
 	if err := rm.clockRunner.persistenceManager.SaveSystemStateToRedis(); err != nil {
-		log.Printf("Failed to save reset state to Redis: %v", err)
+		slog.Error("failed to save reset state to Redis", "error", err)
 	}
 
-	log.Printf("✅ Clock reset to idle state")
 	return nil
 }
 
 // resumeBasedOnState resumes the clock based on the loaded state
 func (rm *ResumeManager) resumeBasedOnState(state *SystemState) error {
-	log.Printf("🔄 ResumeManager: resumeBasedOnState: Session: %d, State: '%s', Running: %v, Paused: %v, TimeRemaining: %dms",
-		state.CurrentSession, state.State, state.IsRunning, state.IsPaused, state.TimeRemaining)
+	slog.Debug("resuming from saved state",
+		"session", state.CurrentSession,
+		"state", state.State,
+		"running", state.IsRunning,
+		"paused", state.IsPaused,
+		"remaining_ms", state.TimeRemaining)
 
 	// Synchronize state and timer before resuming
 	rm.clockRunner.synchronizeStateTimer()
 
 	// Validate the state first
 	if err := rm.validateSystemState(state); err != nil {
-		log.Printf("❌ Invalid state detected: %v", err)
+		slog.Warn("invalid saved state", "error", err)
 		return rm.resetToIdle("invalid state detected")
 	}
 
 	// Handle idle state
 	if state.State == string(StateIdle) {
-		log.Printf("🔄 Restoring idle state")
 		return rm.restoreIdleState()
 	}
 
-	// Handle active sessions (working, short_break, long_break)
-	if state.State == string(StateWorking) || state.State == string(StateShortBreak) || state.State == string(StateLongBreak) {
+	// Handle a completed session waiting for its successor to be started
+	if state.State == string(StateWaiting) {
+		if rm.clockRunner.resumePolicy == ResumeIdle {
+			return rm.resetToIdle("resume policy is idle")
+		}
+		return rm.resumeWaitingSession(state)
+	}
+
+	// Handle a paused clock, which is saved as paused rather than with the
+	// state of the session it paused
+	if state.State == string(StatePaused) {
+		if rm.clockRunner.resumePolicy == ResumeIdle {
+			return rm.resetToIdle("resume policy is idle")
+		}
+		return rm.resumePausedSession(state)
+	}
+
+	// Handle active sessions (working, short_break, long_break, custom_break)
+	if isSessionState(ClockState(state.State)) {
+		policy := rm.clockRunner.resumePolicy
+		if policy == ResumeIdle {
+			return rm.resetToIdle("resume policy is idle")
+		}
+
 		// Priority: paused > running > interrupted
+		var err error
 		if state.IsPaused {
-			return rm.resumePausedSession(state)
+			err = rm.resumePausedSession(state)
 		} else if state.IsRunning {
-			return rm.resumeRunningSession(state)
+			err = rm.resumeRunningSession(state)
 		} else {
 			// Session is not running and not paused
 			if state.TimeRemaining > 0 {
 				// Has time remaining - treat as running session that should continue
 				// This handles cases where the session state was saved but flags got reset
-				return rm.resumeRunningSession(state)
+				err = rm.resumeRunningSession(state)
 			} else {
 				// No time remaining - move to next session
-				return rm.resumeCompletedSession(state)
+				err = rm.resumeCompletedSession(state)
 			}
 		}
+
+		// Whatever ends up running, including a session that followed one
+		// completed during the restart, waits for someone to resume it
+		if err == nil && policy == ResumePaused {
+			rm.pauseResumedSession()
+		}
+		return err
 	}
 
 	// Unknown state - reset to idle
-	log.Printf("🔄 Unknown state '%s' - resetting to idle", state.State)
+	slog.Warn("unknown saved state", "state", state.State)
 	return rm.resetToIdle("unknown state")
 }
 
@@ -145,14 +245,15 @@ func (rm *ResumeManager) validateSystemState(state *SystemState) error {
 		return fmt.Errorf("system state is nil")
 	}
 
-	// Validate current session is within valid range
+	// Validate current session is within the schedule, which SetSchedule keeps
+	// to at most MaxSessions
 	totalSessions := rm.clockRunner.sessionManager.GetTotalSessions()
 	if state.CurrentSession < 0 || state.CurrentSession >= totalSessions {
 		return fmt.Errorf("current session %d is out of valid range [0, %d)", state.CurrentSession, totalSessions)
 	}
 
 	// Validate state string
-	if state.State != string(StateIdle) && state.State != string(StateWorking) && state.State != string(StateShortBreak) && state.State != string(StateLongBreak) && state.State != string(StatePaused) {
+	if state.State != string(StateIdle) && state.State != string(StatePaused) && state.State != string(StateWaiting) && !isSessionState(ClockState(state.State)) {
 		return fmt.Errorf("invalid state: %s", state.State)
 	}
 
@@ -165,6 +266,10 @@ func (rm *ResumeManager) validateSystemState(state *SystemState) error {
 		return fmt.Errorf("paused state should have IsPaused=true")
 	}
 
+	if state.State == string(StateWaiting) && (state.IsRunning || state.IsPaused) {
+		return fmt.Errorf("waiting state should not have running/paused flags")
+	}
+
 	if state.IsRunning && state.IsPaused {
 		return fmt.Errorf("cannot be both running and paused")
 	}
@@ -182,10 +287,11 @@ func (rm *ResumeManager) restoreIdleState() error {
 
 // resumeRunningSession resumes a running session
 func (rm *ResumeManager) resumeRunningSession(state *SystemState) error {
-	log.Printf("▶️ Resuming running session %d with %dms remaining", state.CurrentSession, state.TimeRemaining)
+	slog.Info("resuming running session", "session", state.CurrentSession, "state", state.State, "remaining_ms", state.TimeRemaining)
 
 	// Set the session number and validate
 	rm.clockRunner.sessionManager.SetCurrentSession(state.CurrentSession)
+	rm.clockRunner.sessionManager.SetCurrentTag(state.Tag)
 
 	// Set the state to the actual session state (working/short_break/long_break)
 	clockState := ClockState(state.State)
@@ -193,19 +299,17 @@ func (rm *ResumeManager) resumeRunningSession(state *SystemState) error {
 
 	// Reset tick counter (no longer needed with new approach)
 
-	// Calculate remaining time (from milliseconds)
-	endTime := state.EndTime
-	remainingTime := endTime.Sub(time.Now())
+	// Calculate remaining time from the millisecond end time
+	remainingTime := state.RemainingAt(time.Now())
 
 	// Handle case where session has completed while server was down
 	if remainingTime <= 0 {
-		log.Printf("⚡ Session completed while server was down, moving to next session")
-		rm.handleCompletedSession(clockState)
+		slog.Info("session completed while server was down", "session", state.CurrentSession, "state", state.State)
+		rm.handleCompletedSession(clockState, state.EndTime)
 		return nil
 	}
 
 	// Start timer with remaining time
-	log.Printf("⏱️ Starting timer with %v remaining", remainingTime)
 	err := rm.startTimerWithCallbacks(remainingTime, clockState)
 	if err != nil {
 		return err
@@ -221,32 +325,37 @@ func (rm *ResumeManager) resumeRunningSession(state *SystemState) error {
 
 // resumePausedSession resumes a paused session
 func (rm *ResumeManager) resumePausedSession(state *SystemState) error {
-	log.Printf("⏸️ Resuming paused session %d with %dms remaining", state.CurrentSession, state.TimeRemaining)
+	slog.Info("resuming paused session", "session", state.CurrentSession, "state", state.State, "remaining_ms", state.TimeRemaining)
 
 	// Set the session number
 	rm.clockRunner.sessionManager.SetCurrentSession(state.CurrentSession)
+	rm.clockRunner.sessionManager.SetCurrentTag(state.Tag)
 
 	// For paused sessions, set state to paused (not the session state)
+	rm.clockRunner.stateManager.SetPauseReason(state.PauseReason)
 	rm.clockRunner.stateManager.SetState(StatePaused)
 
-	// Get the session state for timer setup
+	// Get the session state for timer setup; a paused clock saves its state as
+	// paused, so the session state comes from the schedule
 	sessionState := ClockState(state.State)
+	if sessionState == StatePaused {
+		sessionState, _, _, _ = rm.clockRunner.sessionManager.GetSessionInfoAt(state.CurrentSession)
+	}
 
-	// Calculate remaining time (from milliseconds)
-	endTime := state.EndTime
-	remainingTime := endTime.Sub(time.Now())
+	// The time left stopped counting down when the session was paused
+	remainingTime := time.Duration(state.TimeRemaining) * time.Millisecond
 
 	// If there's time remaining, start the timer but pause it immediately
 	if remainingTime > 0 {
-		log.Printf("⏱️ Setting up paused timer with %v remaining", remainingTime)
 		if err := rm.startTimerWithCallbacks(remainingTime, sessionState); err != nil {
 			return err
 		}
 		rm.clockRunner.timerManager.PauseTimer()
-		log.Printf("✅ Successfully restored paused session with %v remaining", remainingTime)
 	} else {
-		log.Printf("⚠️ No time remaining for paused session, will complete when resumed")
+		slog.Warn("paused session has no time remaining, it will complete when resumed", "session", state.CurrentSession)
 	}
+	// Resuming runs while the runner is constructed, so nothing else holds cr.mu yet
+	rm.clockRunner.armIdleTimerLocked()
 
 	// Start periodic Redis saves for resumed paused session
 	rm.clockRunner.runSaveStateToRedis()
@@ -254,12 +363,41 @@ func (rm *ResumeManager) resumePausedSession(state *SystemState) error {
 	return nil
 }
 
+// resumeWaitingSession restores a clock that was waiting to begin its next
+// session; the session starts with its full duration when someone starts it
+func (rm *ResumeManager) resumeWaitingSession(state *SystemState) error {
+	slog.Info("resuming waiting session", "session", state.CurrentSession)
+
+	rm.clockRunner.sessionManager.SetCurrentSession(state.CurrentSession)
+	rm.clockRunner.sessionManager.SetCurrentTag(state.Tag)
+	waitForSession(rm.clockRunner)
+	return nil
+}
+
+// pauseResumedSession pauses a session started while resuming, for ResumePaused
+func (rm *ResumeManager) pauseResumedSession() {
+	cr := rm.clockRunner
+	if !cr.stateManager.CanPause() {
+		return
+	}
+
+	slog.Info("resume policy is paused, pausing resumed session",
+		"session", cr.sessionManager.GetCurrentSession(),
+		"state", cr.stateManager.GetState())
+	cr.stateManager.SetState(StatePaused)
+	cr.timerManager.PauseTimer()
+	// Resuming runs while the runner is constructed, so nothing else holds cr.mu yet
+	cr.armIdleTimerLocked()
+	cr.saveStateToRedis()
+}
+
 // resumeInterruptedSession handles sessions that were interrupted (not running, not paused)
 func (rm *ResumeManager) resumeInterruptedSession(state *SystemState) error {
-	log.Printf("⚡ Resuming interrupted session %d with %dms remaining", state.CurrentSession, state.TimeRemaining)
+	slog.Info("resuming interrupted session as paused", "session", state.CurrentSession, "state", state.State, "remaining_ms", state.TimeRemaining)
 
 	// Set the session number
 	rm.clockRunner.sessionManager.SetCurrentSession(state.CurrentSession)
+	rm.clockRunner.sessionManager.SetCurrentTag(state.Tag)
 
 	// Set the state to the actual session state
 	clockState := ClockState(state.State)
@@ -267,20 +405,17 @@ func (rm *ResumeManager) resumeInterruptedSession(state *SystemState) error {
 
 	// Reset tick counter (no longer needed with new approach)
 
-	// Calculate remaining time (from milliseconds)
-	endTime := state.EndTime
-	remainingTime := endTime.Sub(time.Now())
+	// Calculate remaining time from the millisecond end time
+	remainingTime := state.RemainingAt(time.Now())
 
 	// If there's time remaining, set up the timer but keep it paused
 	if remainingTime > 0 {
-		log.Printf("⏱️ Setting up interrupted session with %v remaining (paused)", remainingTime)
 		if err := rm.startTimerWithCallbacks(remainingTime, clockState); err != nil {
 			return err
 		}
 		rm.clockRunner.timerManager.PauseTimer()
-		log.Printf("✅ Successfully restored interrupted session with %v remaining", remainingTime)
 	} else {
-		log.Printf("⚠️ Interrupted session had no time remaining, treating as paused with full duration")
+		slog.Warn("interrupted session has no time remaining, restoring it paused with its full duration", "session", state.CurrentSession)
 		// Get the full duration for this session type
 		sessionDuration := rm.clockRunner.sessionManager.GetCurrentSessionDuration()
 		if sessionDuration > 0 {
@@ -290,6 +425,7 @@ func (rm *ResumeManager) resumeInterruptedSession(state *SystemState) error {
 			rm.clockRunner.timerManager.PauseTimer()
 		}
 	}
+	rm.clockRunner.armIdleTimerLocked()
 
 	// Start periodic Redis saves for resumed interrupted session
 	rm.clockRunner.runSaveStateToRedis()
@@ -299,42 +435,115 @@ func (rm *ResumeManager) resumeInterruptedSession(state *SystemState) error {
 
 // resumeCompletedSession handles sessions that completed while server was down
 func (rm *ResumeManager) resumeCompletedSession(state *SystemState) error {
-	log.Printf("⚡ Resuming completed session %d", state.CurrentSession)
+	slog.Info("resuming completed session", "session", state.CurrentSession, "state", state.State)
 
 	// Set the session number
 	rm.clockRunner.sessionManager.SetCurrentSession(state.CurrentSession)
+	rm.clockRunner.sessionManager.SetCurrentTag(state.Tag)
 
 	// Mark the session as completed and move to next
 	clockState := ClockState(state.State)
-	rm.handleCompletedSession(clockState)
+	rm.handleCompletedSession(clockState, state.EndTime)
 
 	return nil
 }
 
-// handleCompletedSession handles when a session completed while server was down
-func (rm *ResumeManager) handleCompletedSession(completedState ClockState) {
-	// Record the completed session
-	duration := rm.clockRunner.sessionManager.GetCurrentSessionDuration()
-	rm.clockRunner.statsManager.RecordSession(completedState, duration)
-
-	// Move to next session
-	if !rm.clockRunner.sessionManager.NextSession() {
-		// Completed all sessions
-		rm.clockRunner.stateManager.SetState(StateIdle)
-		if rm.clockRunner.onStateChange != nil {
-			rm.clockRunner.onStateChange(StateIdle)
+// handleCompletedSession handles a session that ended at endTime while the
+// server was down. Every later session that would also have ended by now is
+// recorded too, and the clock lands on the session that should be running,
+// with the time it would have left. ResumeFromRedis has already reset the
+// clock if the outage outlasted the rest of the cycle.
+func (rm *ResumeManager) handleCompletedSession(completedState ClockState, endTime time.Time) {
+	cr := rm.clockRunner
+	now := time.Now()
+
+	if cr.strictResume {
+		slog.Warn("strict resume: sessions completed while server was down were not recorded",
+			"skipped", rm.countElapsedSessions(endTime, now),
+			"session", cr.sessionManager.GetCurrentSession(),
+			"state", completedState)
+		rm.resetToIdle("strict resume")
+		return
+	}
+
+	for caughtUp := 0; ; caughtUp++ {
+		if caughtUp >= maxResumeCatchUpSessions {
+			slog.Warn("too many sessions elapsed while server was down, stopping catch-up",
+				"sessions", caughtUp,
+				"session", cr.sessionManager.GetCurrentSession())
+			rm.resetToIdle("catch-up limit reached")
+			return
+		}
+
+		// Record the completed session as of when it ended
+		record := currentSessionRecord(cr, completedState, cr.sessionManager.GetCurrentSessionDuration())
+		record.Completed = endTime
+		recordSession(cr, record)
+
+		// Move to next session
+		if !cr.sessionManager.NextSession() {
+			// Completed all sessions
+			cr.stateManager.SetState(StateIdle)
+			notifyStateChange(cr, StateIdle)
+			cr.saveStateToRedis()
+			slog.Info("completed all pomodoro sessions while server was down", "caught_up", caughtUp+1)
+			return
+		}
+
+		// Without auto-start the next session has been waiting since then
+		if !cr.GetAutoStartNext() {
+			slog.Info("session completed while server was down, waiting to start the next one", "caught_up", caughtUp+1)
+			beginNextSession(cr)
+			cr.runSaveStateToRedis()
+			return
+		}
+
+		completedState = cr.sessionManager.GetCurrentSessionState()
+		endTime = endTime.Add(cr.sessionManager.GetCurrentSessionDuration())
+		if endTime.After(now) {
+			break
 		}
-		log.Printf("✅ All sessions completed while server was down")
+	}
+
+	// Continue the session that should be running now with the time it has left
+	state := cr.sessionManager.GetCurrentSessionState()
+	cr.stateManager.SetState(state)
+	if err := rm.startTimerWithCallbacks(endTime.Sub(now), state); err != nil {
+		slog.Error("failed to start caught-up session, starting it from the beginning", "error", err)
+		cr.startNewSession()
 		return
 	}
+	notifyStateChange(cr, state)
+	cr.runSaveStateToRedis()
+	cr.saveStateToRedis()
+}
+
+// countElapsedSessions counts the current session, which ended at endTime, and
+// every later session of the cycle that would also have ended by now
+func (rm *ResumeManager) countElapsedSessions(endTime, now time.Time) int {
+	cr := rm.clockRunner
+	if !cr.GetAutoStartNext() {
+		// The next session would have waited to be started
+		return 1
+	}
 
-	// Start next session
-	rm.clockRunner.startNewSession()
+	elapsed := 1
+	for session := cr.sessionManager.GetCurrentSession() + 1; elapsed < maxResumeCatchUpSessions; session++ {
+		_, _, total, duration := cr.sessionManager.GetSessionInfoAt(session)
+		if session >= total {
+			break
+		}
+		endTime = endTime.Add(duration)
+		if endTime.After(now) {
+			break
+		}
+		elapsed++
+	}
+	return elapsed
 }
 
 // startTimerWithCallbacks starts the timer with proper callbacks
 func (rm *ResumeManager) startTimerWithCallbacks(remainingTime time.Duration, clockState ClockState) error {
-	log.Printf("▶️ ResumeManager: startTimerWithCallbacks: remainingTime=%v, clockState=%v", remainingTime, clockState)
 	if remainingTime <= 0 {
 		return fmt.Errorf("remaining time must be positive, got %v", remainingTime)
 	}
@@ -345,34 +554,41 @@ func (rm *ResumeManager) startTimerWithCallbacks(remainingTime time.Duration, cl
 	}
 
 	onComplete := func(completedState ClockState) {
-		log.Printf("✅ Session completed: %s", completedState)
+		slog.Info("session completed", "state", completedState, "session", rm.clockRunner.sessionManager.GetCurrentSession())
 
 		// Record the completed session
 		duration := rm.clockRunner.sessionManager.GetCurrentSessionDuration()
-		rm.clockRunner.statsManager.RecordSession(completedState, duration)
+		recordCompletedSession(rm.clockRunner, completedState, duration)
 
 		if rm.clockRunner.onComplete != nil {
 			rm.clockRunner.onComplete(completedState)
 		}
 
 		// Move to next session
-		if !rm.clockRunner.sessionManager.NextSession() {
+		completedSession := rm.clockRunner.sessionManager.GetCurrentSession()
+		hasNextSession := rm.clockRunner.sessionManager.NextSession()
+		completion := newCompletionEvent(rm.clockRunner, completedState, completedSession, hasNextSession)
+		notifyCompletionWebhook(rm.clockRunner, completion)
+		saveCompletionEvent(rm.clockRunner, completion)
+		if !hasNextSession {
 			// Completed all sessions
 			rm.clockRunner.stateManager.SetState(StateIdle)
-			if rm.clockRunner.onStateChange != nil {
-				rm.clockRunner.onStateChange(StateIdle)
-			}
-			log.Printf("🎉 All pomodoro sessions completed!")
+			notifyStateChange(rm.clockRunner, StateIdle)
+			rm.clockRunner.publishCompletion(completion)
+			slog.Info("completed all pomodoro sessions")
 			return
 		}
 
 		// Start next session
-		rm.clockRunner.startNewSession()
+		beginNextSession(rm.clockRunner)
+		rm.clockRunner.publishCompletion(completion)
 	}
 
 	// Start the timer with remaining time
 	rm.clockRunner.timerManager.StartTimer(remainingTime, clockState, onTick, onComplete)
-	log.Printf("⏱️ Started timer with %v remaining for %s session", remainingTime, clockState)
+	// The session began before the restart; count that part as elapsed
+	rm.clockRunner.timerManager.setSessionLength(rm.clockRunner.sessionManager.GetCurrentSessionDuration())
+	slog.Info("timer started", "state", clockState, "remaining_ms", remainingTime.Milliseconds())
 
 	return nil
 }
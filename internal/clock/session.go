@@ -3,6 +3,7 @@ package clock
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,100 +15,288 @@ type SessionManager struct {
 	workDuration       time.Duration
 	shortBreakDuration time.Duration
 	longBreakDuration  time.Duration
-	schedule           []ClockState
-
-	// Current session info
-	currentSession int
+	// customBreakDuration is the default length of StateCustomBreak sessions
+	customBreakDuration time.Duration
+	// schedule is replaced as a whole by SetScheduleSpecs and never modified in
+	// place, so readers share it without copying
+	schedule atomic.Pointer[scheduleSnapshot]
+
+	// Current session info; currentTag labels the current session and is
+	// cleared whenever another session becomes current. currentSession is
+	// only written while holding mu but may be read without it.
+	currentSession atomic.Int64
+	currentTag     string
+
+	// minDuration is the shortest duration SetDurations accepts
+	minDuration time.Duration
+	utils       *ClockUtils
 }
 
 // NewSessionManager creates a new session manager with default settings
 func NewSessionManager() *SessionManager {
-	return &SessionManager{
-		workDuration:       25 * time.Minute,
-		shortBreakDuration: 5 * time.Minute,
-		longBreakDuration:  15 * time.Minute,
-		schedule:           []ClockState{StateWorking, StateShortBreak, StateWorking, StateShortBreak, StateWorking, StateShortBreak, StateWorking, StateLongBreak},
-		currentSession:     0,
+	sm := &SessionManager{
+		workDuration:        25 * time.Minute,
+		shortBreakDuration:  5 * time.Minute,
+		longBreakDuration:   15 * time.Minute,
+		customBreakDuration: 10 * time.Minute,
+		minDuration:         MinSessionDuration,
+		utils:               NewClockUtils(),
 	}
+	schedule := []ClockState{StateWorking, StateShortBreak, StateWorking, StateShortBreak, StateWorking, StateShortBreak, StateWorking, StateLongBreak}
+	sm.schedule.Store(&scheduleSnapshot{states: schedule, durations: make([]time.Duration, len(schedule))})
+	return sm
+}
+
+// scheduleSnapshot is an immutable schedule together with its per-session
+// duration overrides, so readers never see one without the other
+type scheduleSnapshot struct {
+	states []ClockState
+	// durations is parallel to states; a zero entry falls back to the
+	// default duration for that state
+	durations []time.Duration
+}
+
+// loadSchedule returns the shared schedule; callers must not modify it
+func (sm *SessionManager) loadSchedule() []ClockState {
+	return sm.schedule.Load().states
+}
+
+// current returns the current session number
+func (sm *SessionManager) current() int {
+	return int(sm.currentSession.Load())
 }
 
-// SetDurations configures the session manager with custom durations
-func (sm *SessionManager) SetDurations(work, shortBreak, longBreak time.Duration) {
+// SessionSpec describes a single scheduled session. A zero Duration means the
+// session uses the default duration configured for its state.
+type SessionSpec struct {
+	State    ClockState
+	Duration time.Duration
+}
+
+// SetDurations configures the session manager with custom durations.
+// Each duration must be between the minimum duration (MinSessionDuration by
+// default) and MaxSessionDuration; otherwise nothing is changed.
+func (sm *SessionManager) SetDurations(work, shortBreak, longBreak time.Duration) error {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
+	if err := sm.validateDurationsLocked(work, shortBreak, longBreak); err != nil {
+		return err
+	}
+
 	sm.workDuration = work
 	sm.shortBreakDuration = shortBreak
 	sm.longBreakDuration = longBreak
+	return nil
+}
+
+// validateDurationsLocked checks durations against the configured limits; the
+// caller must hold sm.mu
+func (sm *SessionManager) validateDurationsLocked(work, shortBreak, longBreak time.Duration) error {
+	durations := []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"work", work},
+		{"short break", shortBreak},
+		{"long break", longBreak},
+	}
+	for _, d := range durations {
+		if !sm.utils.IsValidDurationWithMin(d.duration, sm.minDuration) {
+			return fmt.Errorf("invalid %s duration %v: must be between %v and %v",
+				d.name, d.duration, sm.minDuration, MaxSessionDuration)
+		}
+	}
+	return nil
+}
+
+// SetDurationsAndSchedule sets the durations and the schedule together, so
+// neither changes unless both are valid
+func (sm *SessionManager) SetDurationsAndSchedule(work, shortBreak, longBreak time.Duration, schedule []ClockState) error {
+	specs := make([]SessionSpec, len(schedule))
+	for i, state := range schedule {
+		specs[i] = SessionSpec{State: state}
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if err := sm.validateDurationsLocked(work, shortBreak, longBreak); err != nil {
+		return err
+	}
+	if err := sm.validateScheduleSpecs(specs); err != nil {
+		return err
+	}
+
+	sm.workDuration = work
+	sm.shortBreakDuration = shortBreak
+	sm.longBreakDuration = longBreak
+	sm.setScheduleSpecsLocked(specs)
+	return nil
+}
+
+// SetCustomBreakDuration sets the default duration of custom break sessions.
+// It is validated against the same limits as SetDurations.
+func (sm *SessionManager) SetCustomBreakDuration(d time.Duration) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if !sm.utils.IsValidDurationWithMin(d, sm.minDuration) {
+		return fmt.Errorf("invalid custom break duration %v: must be between %v and %v",
+			d, sm.minDuration, MaxSessionDuration)
+	}
+	sm.customBreakDuration = d
+	return nil
+}
+
+// GetCustomBreakDuration returns the default duration of custom break sessions
+func (sm *SessionManager) GetCustomBreakDuration() time.Duration {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.customBreakDuration
+}
+
+// SetMinDuration lowers or raises the minimum duration accepted by SetDurations.
+// Production code should keep the default; tests use this to run sub-second sessions.
+func (sm *SessionManager) SetMinDuration(min time.Duration) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.minDuration = min
 }
 
 // GetDurations returns the current durations in minutes
 func (sm *SessionManager) GetDurations() (workMinutes, shortBreakMinutes, longBreakMinutes int) {
-	// No lock needed - durations are only modified by write operations
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
 	return int(sm.workDuration.Minutes()), int(sm.shortBreakDuration.Minutes()), int(sm.longBreakDuration.Minutes())
 }
 
 // GetCurrentSession returns the current session number (0-based)
 func (sm *SessionManager) GetCurrentSession() int {
-	// No lock needed - currentSession is only modified by write operations
-	// and read operations don't need to be synchronized for this simple value
-	return sm.currentSession
+	return sm.current()
 }
 
 // GetTotalSessions returns the total number of sessions in the schedule
 func (sm *SessionManager) GetTotalSessions() int {
-	// No lock needed - schedule length is immutable once set
-	return len(sm.schedule)
+	return len(sm.loadSchedule())
 }
 
 // GetCurrentSessionState returns the state of the current session
 func (sm *SessionManager) GetCurrentSessionState() ClockState {
-	// No lock needed - schedule is immutable once set, currentSession is only modified by writes
-	if sm.currentSession >= len(sm.schedule) {
+	schedule := sm.loadSchedule()
+	current := sm.current()
+	if current >= len(schedule) {
 		return StateIdle
 	}
-	return sm.schedule[sm.currentSession]
+	return schedule[current]
 }
 
 // GetCurrentSessionDuration returns the duration of the current session
 func (sm *SessionManager) GetCurrentSessionDuration() time.Duration {
-	// No lock needed - all data is either immutable or only modified by write operations
-	if sm.currentSession >= len(sm.schedule) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	snap := sm.schedule.Load()
+	current := sm.current()
+	if current >= len(snap.states) {
 		return 0
 	}
+	return sm.sessionDurationAt(snap, current)
+}
+
+// sessionDurationAt returns the duration of the session at the given index of
+// snap, preferring a per-session override over the default for its state; the
+// caller must hold sm.mu for reading
+func (sm *SessionManager) sessionDurationAt(snap *scheduleSnapshot, session int) time.Duration {
+	if session < len(snap.durations) && snap.durations[session] > 0 {
+		return snap.durations[session]
+	}
 
-	state := sm.schedule[sm.currentSession]
-	switch state {
+	switch snap.states[session] {
 	case StateWorking:
 		return sm.workDuration
 	case StateShortBreak:
 		return sm.shortBreakDuration
 	case StateLongBreak:
 		return sm.longBreakDuration
+	case StateCustomBreak:
+		return sm.customBreakDuration
 	default:
 		return 0
 	}
 }
 
+// GetCycleDurations returns the total duration of the schedule and the
+// combined duration of the sessions before the current one
+func (sm *SessionManager) GetCycleDurations() (total, completed time.Duration) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	snap := sm.schedule.Load()
+	current := sm.current()
+	for i := range snap.states {
+		duration := sm.sessionDurationAt(snap, i)
+		total += duration
+		if i < current {
+			completed += duration
+		}
+	}
+	return total, completed
+}
+
 // NextSession advances to the next session
 func (sm *SessionManager) NextSession() bool {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	sm.currentSession++
-	if sm.currentSession >= len(sm.schedule) {
+	next := sm.current() + 1
+	sm.currentTag = ""
+	if next >= len(sm.loadSchedule()) {
 		// Completed all sessions, reset
-		sm.currentSession = 0
+		next = 0
 		// return false // Indicates cycle completion - clock is running forever
 	}
+	sm.currentSession.Store(int64(next))
 	return true // Indicates more sessions available
 }
 
+// PreviousSession moves back to the previous session. It stops at the first
+// session rather than wrapping to the end of the cycle and returns false when
+// already there.
+func (sm *SessionManager) PreviousSession() bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	current := sm.current()
+	if current <= 0 {
+		sm.currentSession.Store(0)
+		return false
+	}
+	sm.currentSession.Store(int64(current - 1))
+	sm.currentTag = ""
+	return true
+}
+
 // ResetSessions resets the session counter to the beginning
 func (sm *SessionManager) ResetSessions() {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
-	sm.currentSession = 0
+	sm.currentSession.Store(0)
+	sm.currentTag = ""
+}
+
+// SetCurrentTag labels the current session; an empty tag clears the label
+func (sm *SessionManager) SetCurrentTag(tag string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.currentTag = tag
+}
+
+// GetCurrentTag returns the label of the current session, if any
+func (sm *SessionManager) GetCurrentTag() string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.currentTag
 }
 
 // SetCurrentSession sets the current session number (for resuming from persistence)
@@ -116,85 +305,147 @@ func (sm *SessionManager) SetCurrentSession(session int) {
 	defer sm.mu.Unlock()
 
 	// Ensure session is within valid range
-	if session < 0 {
+	if total := len(sm.loadSchedule()); session < 0 {
 		session = 0
-	} else if session >= len(sm.schedule) {
-		session = len(sm.schedule) - 1
+	} else if session >= total {
+		session = total - 1
 	}
 
-	sm.currentSession = session
+	sm.currentSession.Store(int64(session))
+}
+
+// GetRemainingSessions counts the sessions of each state from the current one,
+// which has not finished yet, to the end of the schedule
+func (sm *SessionManager) GetRemainingSessions() map[ClockState]int {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	schedule := sm.loadSchedule()
+	current := sm.current()
+	if current < 0 || current > len(schedule) {
+		current = 0
+	}
+	return sm.utils.GetScheduleSummary(schedule[current:])
 }
 
 // IsLastSession returns true if this is the last session in the cycle
 func (sm *SessionManager) IsLastSession() bool {
-	// No lock needed - schedule length is immutable, currentSession only modified by writes
-	return sm.currentSession == len(sm.schedule)-1
+	return sm.current() == len(sm.loadSchedule())-1
 }
 
 // GetSessionInfo returns information about the current session
 func (sm *SessionManager) GetSessionInfo() (state ClockState, sessionNum int, totalSessions int, duration time.Duration) {
-	// No lock needed - all data is either immutable or only modified by write operations
-	state = sm.GetCurrentSessionState()
-	sessionNum = sm.currentSession // 0-based for consistency
-	totalSessions = len(sm.schedule)
-	duration = sm.GetCurrentSessionDuration()
-
-	return
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	// Read the schedule and session once so the values agree with each other
+	snap := sm.schedule.Load()
+	sessionNum = sm.current() // 0-based for consistency
+	totalSessions = len(snap.states)
+	if sessionNum >= totalSessions {
+		return StateIdle, sessionNum, totalSessions, 0
+	}
+	return snap.states[sessionNum], sessionNum, totalSessions, sm.sessionDurationAt(snap, sessionNum)
 }
 
 // GetSessionInfoAt returns information about a specific session
 func (sm *SessionManager) GetSessionInfoAt(session int) (state ClockState, sessionNum int, totalSessions int, duration time.Duration) {
-	// No lock needed - all data is either immutable or only modified by write operations
-	if session < 0 || session >= len(sm.schedule) {
-		return StateIdle, 0, len(sm.schedule), 0
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	snap := sm.schedule.Load()
+	if session < 0 || session >= len(snap.states) {
+		return StateIdle, 0, len(snap.states), 0
 	}
 
-	state = sm.schedule[session]
+	state = snap.states[session]
 	sessionNum = session
-	totalSessions = len(sm.schedule)
-
-	// Calculate duration for the specific session
-	switch state {
-	case StateWorking:
-		duration = sm.workDuration
-	case StateShortBreak:
-		duration = sm.shortBreakDuration
-	case StateLongBreak:
-		duration = sm.longBreakDuration
-	default:
-		duration = 0
-	}
+	totalSessions = len(snap.states)
+	duration = sm.sessionDurationAt(snap, session)
 
 	return
 }
 
-// GetSchedule returns a copy of the current schedule
+// GetSchedule returns the current schedule. The slice is shared with every
+// other caller and must not be modified; clone it to make changes.
 func (sm *SessionManager) GetSchedule() []ClockState {
-	// No lock needed for reading - simple data access
-	schedule := make([]ClockState, len(sm.schedule))
-	copy(schedule, sm.schedule)
-	return schedule
+	// No lock or copy needed - SetScheduleSpecs swaps in a new slice
+	return sm.loadSchedule()
+}
+
+// GetScheduleSpecs returns a copy of the current schedule including any
+// per-session duration overrides
+func (sm *SessionManager) GetScheduleSpecs() []SessionSpec {
+	snap := sm.schedule.Load()
+	specs := make([]SessionSpec, len(snap.states))
+	for i, state := range snap.states {
+		specs[i] = SessionSpec{State: state}
+		if i < len(snap.durations) {
+			specs[i].Duration = snap.durations[i]
+		}
+	}
+	return specs
 }
 
 // SetSchedule sets a custom session schedule
 func (sm *SessionManager) SetSchedule(schedule []ClockState) error {
+	specs := make([]SessionSpec, len(schedule))
+	for i, state := range schedule {
+		specs[i] = SessionSpec{State: state}
+	}
+	return sm.SetScheduleSpecs(specs)
+}
+
+// SetScheduleSpecs sets a custom session schedule with optional per-session durations
+func (sm *SessionManager) SetScheduleSpecs(specs []SessionSpec) error {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	// Validate schedule
-	for _, state := range schedule {
-		if state != StateWorking && state != StateShortBreak && state != StateLongBreak {
-			return fmt.Errorf("invalid state in schedule: %s", state)
-		}
+	if err := sm.validateScheduleSpecs(specs); err != nil {
+		return err
 	}
+	sm.setScheduleSpecsLocked(specs)
+	return nil
+}
 
-	if len(schedule) == 0 {
-		return fmt.Errorf("schedule cannot be empty")
+// validateScheduleSpecs checks a schedule before it is set
+func (sm *SessionManager) validateScheduleSpecs(specs []SessionSpec) error {
+	// Validate schedule; errors carry the position of the offending session
+	states := ScheduleStates(specs)
+	if err := sm.utils.ValidateSchedule(states); err != nil {
+		return err
+	}
+	// Overrides are held to the same limits as the defaults, so e.g. two long
+	// breaks of different lengths in one cycle are each checked
+	for i, spec := range specs {
+		if spec.Duration < 0 {
+			return fmt.Errorf("invalid duration at position %d: %v", i, spec.Duration)
+		}
+		if spec.Duration > 0 && !sm.utils.IsValidDurationWithMin(spec.Duration, sm.minDuration) {
+			return fmt.Errorf("invalid duration at position %d: %v must be between %v and %v",
+				i, spec.Duration, sm.minDuration, MaxSessionDuration)
+		}
 	}
 
-	sm.schedule = make([]ClockState, len(schedule))
-	copy(sm.schedule, schedule)
-	sm.currentSession = 0 // Reset to beginning
-
+	// A cycle made only of breaks has nothing to take a break from
+	if sm.utils.GetScheduleSummary(states)[StateWorking] == 0 {
+		return fmt.Errorf("schedule must contain at least one work session")
+	}
 	return nil
 }
+
+// setScheduleSpecsLocked replaces the schedule and starts it over from the
+// first session; the caller must hold sm.mu
+func (sm *SessionManager) setScheduleSpecsLocked(specs []SessionSpec) {
+	snap := &scheduleSnapshot{
+		states:    make([]ClockState, len(specs)),
+		durations: make([]time.Duration, len(specs)),
+	}
+	for i, spec := range specs {
+		snap.states[i] = spec.State
+		snap.durations[i] = spec.Duration
+	}
+	sm.schedule.Store(snap)
+	sm.currentSession.Store(0) // Reset to beginning
+	sm.currentTag = ""
+}
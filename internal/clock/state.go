@@ -2,34 +2,33 @@ package clock
 
 import (
 	"fmt"
-	"sync"
+	"sync/atomic"
 )
 
 // StateManager handles clock state transitions and validation
 type StateManager struct {
-	mu    sync.RWMutex
-	state ClockState
+	// state holds a ClockState; atomic access keeps polling reads lock-free and race-free
+	state atomic.Value
+	// pauseReason holds the string given with the latest pause
+	pauseReason atomic.Value
 }
 
 // NewStateManager creates a new state manager
 func NewStateManager() *StateManager {
-	return &StateManager{
-		state: StateIdle,
-	}
+	sm := &StateManager{}
+	sm.state.Store(StateIdle)
+	sm.pauseReason.Store("")
+	return sm
 }
 
 // GetState returns the current state
 func (sm *StateManager) GetState() ClockState {
-	// No lock needed - in polling scenarios, slightly stale reads are acceptable
-	// and write operations should have priority
-	return sm.state
+	return sm.state.Load().(ClockState)
 }
 
 // SetState sets the current state
 func (sm *StateManager) SetState(state ClockState) {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-	sm.state = state
+	sm.state.Store(state)
 }
 
 // IsIdle returns true if the clock is idle
@@ -39,8 +38,17 @@ func (sm *StateManager) IsIdle() bool {
 
 // IsRunning returns true if the clock is actively running
 func (sm *StateManager) IsRunning() bool {
-	state := sm.GetState()
-	return state == StateWorking || state == StateShortBreak || state == StateLongBreak
+	return isSessionState(sm.GetState())
+}
+
+// isSessionState reports whether state is one that can appear in a schedule
+func isSessionState(state ClockState) bool {
+	switch state {
+	case StateWorking, StateShortBreak, StateLongBreak, StateCustomBreak:
+		return true
+	default:
+		return false
+	}
 }
 
 // IsPaused returns true if the clock is paused
@@ -48,10 +56,28 @@ func (sm *StateManager) IsPaused() bool {
 	return sm.GetState() == StatePaused
 }
 
+// SetPauseReason records why the clock was paused; an empty reason clears it
+func (sm *StateManager) SetPauseReason(reason string) {
+	sm.pauseReason.Store(reason)
+}
+
+// GetPauseReason returns why the clock was paused, or "" when it is not paused
+func (sm *StateManager) GetPauseReason() string {
+	if !sm.IsPaused() {
+		return ""
+	}
+	return sm.pauseReason.Load().(string)
+}
+
+// IsWaiting returns true if the clock is waiting to begin the next session
+func (sm *StateManager) IsWaiting() bool {
+	return sm.GetState() == StateWaiting
+}
+
 // CanStart returns true if the clock can be started
 func (sm *StateManager) CanStart() bool {
 	state := sm.GetState()
-	return state == StateIdle || state == StatePaused
+	return state == StateIdle || state == StatePaused || state == StateWaiting
 }
 
 // CanPause returns true if the clock can be paused
@@ -66,7 +92,7 @@ func (sm *StateManager) CanStop() bool {
 
 // CanSkip returns true if the clock can be skipped
 func (sm *StateManager) CanSkip() bool {
-	return sm.IsRunning() || sm.IsPaused()
+	return sm.IsRunning() || sm.IsPaused() || sm.IsWaiting()
 }
 
 // ValidateTransition validates if a state transition is allowed
@@ -77,9 +103,9 @@ func (sm *StateManager) ValidateTransition(newState ClockState) error {
 	case StateIdle:
 		// Can transition to idle from any state
 		return nil
-	case StateWorking, StateShortBreak, StateLongBreak:
-		// Can only transition to running states from idle or paused
-		if currentState != StateIdle && currentState != StatePaused {
+	case StateWorking, StateShortBreak, StateLongBreak, StateCustomBreak:
+		// Can only transition to running states from idle, paused or waiting
+		if currentState != StateIdle && currentState != StatePaused && currentState != StateWaiting {
 			return fmt.Errorf("cannot transition from %s to %s", currentState, newState)
 		}
 		return nil
@@ -89,6 +115,12 @@ func (sm *StateManager) ValidateTransition(newState ClockState) error {
 			return fmt.Errorf("cannot pause from state %s", currentState)
 		}
 		return nil
+	case StateWaiting:
+		// Can only wait for the next session once a running session completes
+		if !sm.IsRunning() {
+			return fmt.Errorf("cannot wait from state %s", currentState)
+		}
+		return nil
 	default:
 		return fmt.Errorf("invalid state: %s", newState)
 	}
@@ -1,6 +1,9 @@
 package clock
 
 import (
+	"errors"
+	"fmt"
+	"sort"
 	"sync"
 	"time"
 )
@@ -14,13 +17,32 @@ type StatisticsManager struct {
 	totalShortBreaks  int
 	totalLongBreaks   int
 
+	// Custom breaks are tracked on their own and excluded from the break totals
+	totalCustomBreaks    int
+	totalCustomBreakTime time.Duration
+
 	// Timing statistics
 	totalWorkTime    time.Duration
 	totalBreakTime   time.Duration
 	totalSessionTime time.Duration
 
+	// totalPauses counts pauses since the process started and pausesByReason
+	// those given a reason; neither is persisted
+	totalPauses    int
+	pausesByReason map[string]int
+
 	// Session history
 	sessionHistory []SessionRecord
+
+	// location and dayStart determine day and week boundaries; now is the time source
+	location *time.Location
+	dayStart time.Duration
+	now      func() time.Time
+
+	// dailyWorkGoal is the number of work sessions to complete each day, 0 when
+	// unset; goalReachedDay is the start of the last day it was reached on
+	dailyWorkGoal  int
+	goalReachedDay time.Time
 }
 
 // SessionRecord represents a completed session
@@ -28,28 +50,106 @@ type SessionRecord struct {
 	State     ClockState
 	Duration  time.Duration
 	Completed time.Time
+	// Tag is the optional label set while the session ran, e.g. "writing report"
+	Tag string
 }
 
 // NewStatisticsManager creates a new statistics manager
 func NewStatisticsManager() *StatisticsManager {
 	return &StatisticsManager{
 		sessionHistory: make([]SessionRecord, 0),
+		location:       time.Local,
+		now:            time.Now,
 	}
 }
 
-// RecordSession records a completed session
-func (sm *StatisticsManager) RecordSession(state ClockState, duration time.Duration) {
+// SetLocation sets the time zone used for "today" and "this week" boundaries
+func (sm *StatisticsManager) SetLocation(loc *time.Location) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if loc == nil {
+		loc = time.Local
+	}
+	sm.location = loc
+}
+
+// GetLocation returns the time zone used for day and week boundaries
+func (sm *StatisticsManager) GetLocation() *time.Location {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.location
+}
+
+// SetDayStart sets the offset from midnight at which a new day begins, e.g. 4h
+// so that sessions finished shortly after midnight still count toward the previous day
+func (sm *StatisticsManager) SetDayStart(offset time.Duration) error {
+	if offset < 0 || offset >= 24*time.Hour {
+		return fmt.Errorf("day start must be within [0, 24h), got %v", offset)
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.dayStart = offset
+	return nil
+}
+
+// GetDayStart returns the offset from midnight at which a new day begins
+func (sm *StatisticsManager) GetDayStart() time.Duration {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.dayStart
+}
+
+// SetNowFunc overrides the time source used for day and week boundaries (useful in tests)
+func (sm *StatisticsManager) SetNowFunc(now func() time.Time) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	record := SessionRecord{
+	if now == nil {
+		now = time.Now
+	}
+	sm.now = now
+}
+
+// startOfDay returns the start of the current day in the configured location,
+// honoring the configured day start offset
+func (sm *StatisticsManager) startOfDay() time.Time {
+	now := sm.now().In(sm.location)
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, sm.location).Add(sm.dayStart)
+	if now.Before(start) {
+		// Still within the previous day until the boundary is reached
+		start = time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, sm.location).Add(sm.dayStart)
+	}
+	return start
+}
+
+// RecordSession records a completed session
+func (sm *StatisticsManager) RecordSession(state ClockState, duration time.Duration) {
+	sm.RecordSessionAt(state, duration, time.Now())
+}
+
+// RecordSessionAt records a session that completed at the given time
+func (sm *StatisticsManager) RecordSessionAt(state ClockState, duration time.Duration, completedAt time.Time) {
+	sm.Record(SessionRecord{
 		State:     state,
 		Duration:  duration,
-		Completed: time.Now(),
-	}
+		Completed: completedAt,
+	})
+}
+
+// Record adds a completed session, including its tag, to the history and totals
+func (sm *StatisticsManager) Record(record SessionRecord) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
 
 	sm.sessionHistory = append(sm.sessionHistory, record)
+	sm.countLocked(record)
+}
 
+// countLocked adds record to the counters and timing totals
+func (sm *StatisticsManager) countLocked(record SessionRecord) {
+	state, duration := record.State, record.Duration
 	switch state {
 	case StateWorking:
 		sm.totalWorkSessions++
@@ -60,28 +160,149 @@ func (sm *StatisticsManager) RecordSession(state ClockState, duration time.Durat
 	case StateLongBreak:
 		sm.totalLongBreaks++
 		sm.totalBreakTime += duration
+	case StateCustomBreak:
+		sm.totalCustomBreaks++
+		sm.totalCustomBreakTime += duration
+		return
 	}
 
 	sm.totalSessionTime += duration
 }
 
+// MaxImportSessions caps how many sessions one ImportSessions call accepts
+const MaxImportSessions = 10000
+
+// ErrInvalidSessionRecord is returned when an imported session is malformed
+var ErrInvalidSessionRecord = errors.New("invalid session record")
+
+// ImportSessions adds previously completed sessions, e.g. from another app, to
+// the history and totals. Every record is checked first: its state must be a
+// session state, its duration positive and its completion time set and not in
+// the future. Nothing is imported if any record is invalid. The history stays
+// ordered by completion time.
+func (sm *StatisticsManager) ImportSessions(records []SessionRecord) error {
+	if len(records) > MaxImportSessions {
+		return fmt.Errorf("%w: more than %d sessions", ErrInvalidSessionRecord, MaxImportSessions)
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	now := sm.now()
+	for i, record := range records {
+		switch record.State {
+		case StateWorking, StateShortBreak, StateLongBreak, StateCustomBreak:
+		default:
+			return fmt.Errorf("%w: session %d has state %q", ErrInvalidSessionRecord, i, record.State)
+		}
+		if record.Duration <= 0 {
+			return fmt.Errorf("%w: session %d has a non-positive duration", ErrInvalidSessionRecord, i)
+		}
+		if record.Completed.IsZero() {
+			return fmt.Errorf("%w: session %d has no completion time", ErrInvalidSessionRecord, i)
+		}
+		if record.Completed.After(now) {
+			return fmt.Errorf("%w: session %d completed in the future", ErrInvalidSessionRecord, i)
+		}
+	}
+
+	for _, record := range records {
+		sm.sessionHistory = append(sm.sessionHistory, record)
+		sm.countLocked(record)
+	}
+	sort.SliceStable(sm.sessionHistory, func(i, j int) bool {
+		return sm.sessionHistory[i].Completed.Before(sm.sessionHistory[j].Completed)
+	})
+	return nil
+}
+
+// RecordPause counts a pause of a running session, and its reason if one was given
+func (sm *StatisticsManager) RecordPause(reason string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.totalPauses++
+	if reason == "" {
+		return
+	}
+	if sm.pausesByReason == nil {
+		sm.pausesByReason = make(map[string]int)
+	}
+	sm.pausesByReason[reason]++
+}
+
+// StatisticsSnapshot is a consistent point-in-time copy of all statistics
+type StatisticsSnapshot struct {
+	WorkSessions int
+	ShortBreaks  int
+	LongBreaks   int
+
+	WorkTime  time.Duration
+	BreakTime time.Duration
+	TotalTime time.Duration
+
+	CustomBreaks    int
+	CustomBreakTime time.Duration
+
+	Pauses int
+	// PausesByReason counts the pauses given each reason
+	PausesByReason map[string]int
+
+	History []SessionRecord
+}
+
+// Snapshot returns all counters, timing totals, and a copy of the history taken
+// under a single read lock, so counts always match the history
+func (sm *StatisticsManager) Snapshot() StatisticsSnapshot {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	history := make([]SessionRecord, len(sm.sessionHistory))
+	copy(history, sm.sessionHistory)
+	pausesByReason := make(map[string]int, len(sm.pausesByReason))
+	for reason, count := range sm.pausesByReason {
+		pausesByReason[reason] = count
+	}
+
+	return StatisticsSnapshot{
+		WorkSessions:    sm.totalWorkSessions,
+		ShortBreaks:     sm.totalShortBreaks,
+		LongBreaks:      sm.totalLongBreaks,
+		WorkTime:        sm.totalWorkTime,
+		BreakTime:       sm.totalBreakTime,
+		TotalTime:       sm.totalSessionTime,
+		CustomBreaks:    sm.totalCustomBreaks,
+		CustomBreakTime: sm.totalCustomBreakTime,
+		Pauses:          sm.totalPauses,
+		PausesByReason:  pausesByReason,
+		History:         history,
+	}
+}
+
 // GetStatistics returns the current statistics
 func (sm *StatisticsManager) GetStatistics() (workSessions, shortBreaks, longBreaks int) {
-	// No lock needed - in polling scenarios, slightly stale reads are acceptable
-	// and write operations should have priority
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
 	return sm.totalWorkSessions, sm.totalShortBreaks, sm.totalLongBreaks
 }
 
+// GetCustomBreakStatistics returns the number and total duration of completed custom breaks
+func (sm *StatisticsManager) GetCustomBreakStatistics() (customBreaks int, customBreakTime time.Duration) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.totalCustomBreaks, sm.totalCustomBreakTime
+}
+
 // GetTimingStatistics returns timing statistics
 func (sm *StatisticsManager) GetTimingStatistics() (workTime, breakTime, totalTime time.Duration) {
-	// No lock needed - in polling scenarios, slightly stale reads are acceptable
-	// and write operations should have priority
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
 	return sm.totalWorkTime, sm.totalBreakTime, sm.totalSessionTime
 }
 
 // GetSessionHistory returns the session history
 func (sm *StatisticsManager) GetSessionHistory() []SessionRecord {
-	// No lock needed for reading - may return slightly stale data during writes
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
 	history := make([]SessionRecord, len(sm.sessionHistory))
 	copy(history, sm.sessionHistory)
 	return history
@@ -89,7 +310,8 @@ func (sm *StatisticsManager) GetSessionHistory() []SessionRecord {
 
 // GetRecentSessions returns the most recent N sessions
 func (sm *StatisticsManager) GetRecentSessions(count int) []SessionRecord {
-	// No lock needed for reading - may return slightly stale data during writes
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
 	if count <= 0 {
 		return []SessionRecord{}
 	}
@@ -106,7 +328,8 @@ func (sm *StatisticsManager) GetRecentSessions(count int) []SessionRecord {
 
 // GetSessionsByState returns all sessions of a specific state
 func (sm *StatisticsManager) GetSessionsByState(state ClockState) []SessionRecord {
-	// No lock needed for reading - may return slightly stale data during writes
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
 	var sessions []SessionRecord
 	for _, record := range sm.sessionHistory {
 		if record.State == state {
@@ -118,12 +341,13 @@ func (sm *StatisticsManager) GetSessionsByState(state ClockState) []SessionRecor
 
 // GetTodaySessions returns sessions completed today
 func (sm *StatisticsManager) GetTodaySessions() []SessionRecord {
-	// No lock needed for reading - may return slightly stale data during writes
-	today := time.Now().Truncate(24 * time.Hour)
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	today := sm.startOfDay()
 	var todaySessions []SessionRecord
 
 	for _, record := range sm.sessionHistory {
-		if record.Completed.After(today) {
+		if !record.Completed.Before(today) {
 			todaySessions = append(todaySessions, record)
 		}
 	}
@@ -131,14 +355,138 @@ func (sm *StatisticsManager) GetTodaySessions() []SessionRecord {
 	return todaySessions
 }
 
+// MaxDailyWorkGoal is the largest daily work goal accepted
+const MaxDailyWorkGoal = 100
+
+// SetDailyWorkGoal sets how many work sessions to complete each day; 0 removes the goal
+func (sm *StatisticsManager) SetDailyWorkGoal(goal int) error {
+	if goal < 0 || goal > MaxDailyWorkGoal {
+		return fmt.Errorf("daily work goal must be between 0 and %d, got %d", MaxDailyWorkGoal, goal)
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.dailyWorkGoal = goal
+	return nil
+}
+
+// GetDailyWorkGoal returns how many work sessions to complete each day, 0 when unset
+func (sm *StatisticsManager) GetDailyWorkGoal() int {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.dailyWorkGoal
+}
+
+// DailyGoalProgress compares the work sessions completed today with the daily goal
+type DailyGoalProgress struct {
+	Completed int
+	Goal      int
+	// Percent is capped at 100 and is 0 without a goal
+	Percent float64
+	Reached bool
+}
+
+// GetDailyGoalProgress returns the progress toward the daily work goal, using
+// the configured location and day start
+func (sm *StatisticsManager) GetDailyGoalProgress() DailyGoalProgress {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.dailyGoalProgressLocked()
+}
+
+// dailyGoalProgressLocked computes the goal progress; the caller must hold sm.mu
+func (sm *StatisticsManager) dailyGoalProgressLocked() DailyGoalProgress {
+	progress := DailyGoalProgress{Goal: sm.dailyWorkGoal}
+	today := sm.startOfDay()
+	for _, record := range sm.sessionHistory {
+		if record.State == StateWorking && !record.Completed.Before(today) {
+			progress.Completed++
+		}
+	}
+
+	if progress.Goal > 0 {
+		progress.Reached = progress.Completed >= progress.Goal
+		progress.Percent = min(100, float64(progress.Completed)/float64(progress.Goal)*100)
+	}
+	return progress
+}
+
+// MarkDailyGoalReached reports whether the daily work goal has just been
+// reached: it returns true once per day, the first time it is called after the
+// goal is met, and false otherwise
+func (sm *StatisticsManager) MarkDailyGoalReached() (DailyGoalProgress, bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	progress := sm.dailyGoalProgressLocked()
+	today := sm.startOfDay()
+	if !progress.Reached || sm.goalReachedDay.Equal(today) {
+		return progress, false
+	}
+	sm.goalReachedDay = today
+	return progress, true
+}
+
+// GetTodayFocusTime returns the total duration of work sessions completed today,
+// using the configured location and day start
+func (sm *StatisticsManager) GetTodayFocusTime() time.Duration {
+	var focus time.Duration
+	for _, record := range sm.GetTodaySessions() {
+		if record.State == StateWorking {
+			focus += record.Duration
+		}
+	}
+	return focus
+}
+
+// DurationBucket counts completed work sessions within a duration range
+type DurationBucket struct {
+	Label string
+	// Max is the longest duration in the bucket; 0 means unbounded
+	Max   time.Duration
+	Count int
+}
+
+// durationBuckets are the ranges used by GetDurationDistribution, shortest first
+var durationBuckets = []DurationBucket{
+	{Label: "<15m", Max: 15*time.Minute - 1},
+	{Label: "15-25m", Max: 25 * time.Minute},
+	{Label: "25-45m", Max: 45 * time.Minute},
+	{Label: ">45m"},
+}
+
+// GetDurationDistribution buckets completed work sessions by duration: under
+// 15 minutes, 15 to 25, over 25 up to 45, and over 45. Every bucket is
+// returned, shortest first, even when empty.
+func (sm *StatisticsManager) GetDurationDistribution() []DurationBucket {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	buckets := make([]DurationBucket, len(durationBuckets))
+	copy(buckets, durationBuckets)
+	for _, record := range sm.sessionHistory {
+		if record.State != StateWorking {
+			continue
+		}
+		for i := range buckets {
+			if buckets[i].Max == 0 || record.Duration <= buckets[i].Max {
+				buckets[i].Count++
+				break
+			}
+		}
+	}
+	return buckets
+}
+
 // GetWeeklyStats returns statistics for the current week
 func (sm *StatisticsManager) GetWeeklyStats() (workSessions, shortBreaks, longBreaks int, workTime, breakTime time.Duration) {
-	// No lock needed for reading - may return slightly stale data during writes
-	weekStart := time.Now().Truncate(24 * time.Hour)
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	weekStart := sm.startOfDay()
 	weekStart = weekStart.AddDate(0, 0, -int(weekStart.Weekday()))
 
 	for _, record := range sm.sessionHistory {
-		if record.Completed.After(weekStart) {
+		if !record.Completed.Before(weekStart) {
 			switch record.State {
 			case StateWorking:
 				workSessions++
@@ -156,6 +504,86 @@ func (sm *StatisticsManager) GetWeeklyStats() (workSessions, shortBreaks, longBr
 	return
 }
 
+// MaxStatsRange is the longest window GetStatsForRange accepts
+const MaxStatsRange = 92 * 24 * time.Hour
+
+// ErrInvalidStatsRange is returned when a statistics window is reversed or too long
+var ErrInvalidStatsRange = errors.New("invalid statistics range")
+
+// GetStatsForRange aggregates the sessions completed in [start, end) into a
+// snapshot whose History holds those sessions, oldest first. Pauses are not
+// recorded with a time, so they are not counted. Like the rest of the history,
+// only sessions completed since the server started, or restored from Redis
+// within the statistics retention, are covered.
+func (sm *StatisticsManager) GetStatsForRange(start, end time.Time) (StatisticsSnapshot, error) {
+	if end.Before(start) {
+		return StatisticsSnapshot{}, fmt.Errorf("%w: end %s is before start %s",
+			ErrInvalidStatsRange, end.Format(time.RFC3339), start.Format(time.RFC3339))
+	}
+	if end.Sub(start) > MaxStatsRange {
+		return StatisticsSnapshot{}, fmt.Errorf("%w: longer than %v", ErrInvalidStatsRange, MaxStatsRange)
+	}
+
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	snapshot := StatisticsSnapshot{History: make([]SessionRecord, 0)}
+	for _, record := range sm.sessionHistory {
+		if record.Completed.Before(start) || !record.Completed.Before(end) {
+			continue
+		}
+		snapshot.History = append(snapshot.History, record)
+
+		switch record.State {
+		case StateWorking:
+			snapshot.WorkSessions++
+			snapshot.WorkTime += record.Duration
+		case StateShortBreak:
+			snapshot.ShortBreaks++
+			snapshot.BreakTime += record.Duration
+		case StateLongBreak:
+			snapshot.LongBreaks++
+			snapshot.BreakTime += record.Duration
+		case StateCustomBreak:
+			snapshot.CustomBreaks++
+			snapshot.CustomBreakTime += record.Duration
+			continue
+		}
+		snapshot.TotalTime += record.Duration
+	}
+	return snapshot, nil
+}
+
+// Hydrate replaces the counters and timing totals with previously persisted
+// values. HydrateHistory restores the session history separately.
+func (sm *StatisticsManager) Hydrate(workSessions, shortBreaks, longBreaks int, workTime, breakTime time.Duration, customBreaks int, customBreakTime time.Duration) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.totalWorkSessions = workSessions
+	sm.totalShortBreaks = shortBreaks
+	sm.totalLongBreaks = longBreaks
+	sm.totalWorkTime = workTime
+	sm.totalBreakTime = breakTime
+	sm.totalSessionTime = workTime + breakTime
+	sm.totalCustomBreaks = customBreaks
+	sm.totalCustomBreakTime = customBreakTime
+}
+
+// HydrateHistory replaces the session history with previously persisted
+// records, oldest first, without changing the counters. If they already meet
+// today's work goal, it is not announced again.
+func (sm *StatisticsManager) HydrateHistory(history []SessionRecord) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.sessionHistory = make([]SessionRecord, len(history))
+	copy(sm.sessionHistory, history)
+	if sm.dailyGoalProgressLocked().Reached {
+		sm.goalReachedDay = sm.startOfDay()
+	}
+}
+
 // ResetStatistics resets all statistics
 func (sm *StatisticsManager) ResetStatistics() {
 	sm.mu.Lock()
@@ -167,13 +595,19 @@ func (sm *StatisticsManager) ResetStatistics() {
 	sm.totalWorkTime = 0
 	sm.totalBreakTime = 0
 	sm.totalSessionTime = 0
+	sm.totalCustomBreaks = 0
+	sm.totalCustomBreakTime = 0
+	sm.totalPauses = 0
+	sm.pausesByReason = nil
 	sm.sessionHistory = make([]SessionRecord, 0)
+	sm.goalReachedDay = time.Time{}
 }
 
-// GetAverageSessionDuration returns the average duration of completed sessions
+// GetAverageSessionDuration returns the average duration of completed work
+// sessions and breaks, excluding custom breaks; 0 when none were completed
 func (sm *StatisticsManager) GetAverageSessionDuration() time.Duration {
-	// No lock needed - in polling scenarios, slightly stale reads are acceptable
-	// and write operations should have priority
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
 	totalSessions := sm.totalWorkSessions + sm.totalShortBreaks + sm.totalLongBreaks
 	if totalSessions == 0 {
 		return 0
@@ -182,9 +616,12 @@ func (sm *StatisticsManager) GetAverageSessionDuration() time.Duration {
 	return sm.totalSessionTime / time.Duration(totalSessions)
 }
 
-// GetProductivityScore returns a simple productivity score based on work sessions
+// GetProductivityScore returns the percentage (0-100) of completed sessions that
+// were work sessions. Short and long breaks count against the score; custom
+// breaks are left out. It is 0 when no sessions were completed.
 func (sm *StatisticsManager) GetProductivityScore() float64 {
-	// No lock needed for reading simple integer values
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
 	totalSessions := sm.totalWorkSessions + sm.totalShortBreaks + sm.totalLongBreaks
 	if totalSessions == 0 {
 		return 0.0
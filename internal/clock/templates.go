@@ -0,0 +1,111 @@
+package clock
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"time"
+)
+
+var (
+	// ErrTemplateNotFound is returned when no template has the requested name
+	ErrTemplateNotFound = errors.New("schedule template not found")
+	// ErrInvalidTemplate wraps the reason a template was rejected
+	ErrInvalidTemplate = errors.New("invalid schedule template")
+	// ErrClockNotIdle is returned when a template is applied while a session is active
+	ErrClockNotIdle = errors.New("clock is not idle")
+)
+
+// templateNamePattern limits template names to something safe in a URL path
+var templateNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,50}$`)
+
+// ScheduleTemplate is a named schedule with its durations, saved by admins to
+// switch between setups such as "deep-work" or "study"
+type ScheduleTemplate struct {
+	Name     string       `json:"name"`
+	Schedule []ClockState `json:"schedule"`
+	// Durations are in minutes, like PomodoroSettings
+	WorkTime       int `json:"workTime"`
+	ShortBreakTime int `json:"shortBreakTime"`
+	LongBreakTime  int `json:"longBreakTime"`
+}
+
+// Durations returns the template's work, short break and long break durations
+func (t *ScheduleTemplate) Durations() (work, shortBreak, longBreak time.Duration) {
+	return time.Duration(t.WorkTime) * time.Minute,
+		time.Duration(t.ShortBreakTime) * time.Minute,
+		time.Duration(t.LongBreakTime) * time.Minute
+}
+
+// Validate checks the name, the schedule with ValidateSchedule and the
+// durations; errors wrap ErrInvalidTemplate
+func (t *ScheduleTemplate) Validate() error {
+	if !templateNamePattern.MatchString(t.Name) {
+		return fmt.Errorf("%w: name must be 1 to 50 letters, digits, '-' or '_'", ErrInvalidTemplate)
+	}
+
+	utils := NewClockUtils()
+	if err := utils.ValidateSchedule(t.Schedule); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	if utils.GetScheduleSummary(t.Schedule)[StateWorking] == 0 {
+		return fmt.Errorf("%w: schedule must contain at least one work session", ErrInvalidTemplate)
+	}
+
+	work, shortBreak, longBreak := t.Durations()
+	for _, d := range []time.Duration{work, shortBreak, longBreak} {
+		if !utils.IsValidDuration(d) {
+			return fmt.Errorf("%w: durations must be between %v and %v", ErrInvalidTemplate, MinSessionDuration, MaxSessionDuration)
+		}
+	}
+	return nil
+}
+
+// SaveTemplate validates and stores a named template in Redis, replacing any
+// template of the same name
+func (cr *ClockRunner) SaveTemplate(name string, schedule []ClockState, work, shortBreak, longBreak time.Duration) error {
+	if cr.redisPersistence == nil {
+		return fmt.Errorf("redis persistence not initialized")
+	}
+	return cr.redisPersistence.SaveTemplate(name, schedule, work, shortBreak, longBreak)
+}
+
+// ListTemplates returns the templates saved in Redis, sorted by name
+func (cr *ClockRunner) ListTemplates() ([]ScheduleTemplate, error) {
+	if cr.redisPersistence == nil {
+		return nil, fmt.Errorf("redis persistence not initialized")
+	}
+	return cr.redisPersistence.ListTemplates()
+}
+
+// ApplyTemplate loads a template from Redis and sets its durations and
+// schedule together; nothing changes if either is invalid. The clock must be
+// idle, since the schedule starts over from the first session.
+func (cr *ClockRunner) ApplyTemplate(name string) (*ScheduleTemplate, error) {
+	if cr.redisPersistence == nil {
+		return nil, fmt.Errorf("redis persistence not initialized")
+	}
+	template, err := cr.redisPersistence.LoadTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+
+	cr.mu.Lock()
+	if !cr.stateManager.IsIdle() {
+		cr.mu.Unlock()
+		return nil, ErrClockNotIdle
+	}
+	work, shortBreak, longBreak := template.Durations()
+	err = cr.sessionManager.SetDurationsAndSchedule(work, shortBreak, longBreak, template.Schedule)
+	cr.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+
+	slog.Info("applied schedule template", "name", name, "sessions", len(template.Schedule))
+	if err := cr.persistenceManager.SaveSettingsToRedis(); err != nil {
+		slog.Error("failed to save settings to Redis", "error", err)
+	}
+	return template, nil
+}
@@ -2,6 +2,8 @@ package clock
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 )
@@ -15,13 +17,19 @@ type TimerManager struct {
 	ticker *time.Ticker
 	ctx    context.Context
 	cancel context.CancelFunc
+	// tickerDone is closed when the tickerLoop of the current timer returns
+	tickerDone chan struct{}
 
 	// Timing info
 	startTime       time.Time
-	pauseTime       time.Time
 	timeRemaining   time.Duration
 	sessionDuration time.Duration
 	currentState    ClockState
+	tickInterval    time.Duration
+
+	// sessionLength is the full length of the session including adjustments;
+	// sessionDuration only covers the stretch since the last resume
+	sessionLength time.Duration
 
 	// Callbacks
 	onTick     func(time.Duration)
@@ -29,11 +37,52 @@ type TimerManager struct {
 
 	// Track if we're in completion process
 	isCompleting bool
+
+	// expireOnResume is set when a paused session's remaining time was adjusted
+	// down to zero, so that resuming completes it instead of doing nothing
+	expireOnResume bool
 }
 
+var (
+	// ErrNoTimer is returned when adjusting while no session is timed
+	ErrNoTimer = errors.New("no running or paused timer")
+	// ErrTimerCompleting is returned when adjusting a session that is already completing
+	ErrTimerCompleting = errors.New("session is completing")
+)
+
 // NewTimerManager creates a new timer manager
 func NewTimerManager() *TimerManager {
-	return &TimerManager{}
+	return &TimerManager{tickInterval: DefaultTickInterval}
+}
+
+const (
+	// DefaultTickInterval is how often onTick fires while a timer is running
+	DefaultTickInterval = 100 * time.Millisecond
+	// MinTickInterval is the finest tick granularity accepted by SetTickInterval
+	MinTickInterval = time.Millisecond
+	// MaxTickInterval is the coarsest tick granularity accepted by SetTickInterval
+	MaxTickInterval = time.Second
+)
+
+// SetTickInterval sets how often onTick fires. The ticker is only created when a
+// timer starts or resumes, so a change made mid-session takes effect on the next
+// StartTimer or ResumeTimer rather than on the running ticker.
+func (tm *TimerManager) SetTickInterval(d time.Duration) error {
+	if d < MinTickInterval || d > MaxTickInterval {
+		return fmt.Errorf("tick interval must be between %v and %v, got %v", MinTickInterval, MaxTickInterval, d)
+	}
+
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.tickInterval = d
+	return nil
+}
+
+// GetTickInterval returns how often onTick fires
+func (tm *TimerManager) GetTickInterval() time.Duration {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.tickInterval
 }
 
 // StartTimer starts the timer for a session
@@ -45,6 +94,7 @@ func (tm *TimerManager) StartTimer(duration time.Duration, state ClockState, onT
 	tm.stopTimer()
 
 	tm.sessionDuration = duration
+	tm.sessionLength = duration
 	tm.currentState = state
 	tm.timeRemaining = duration
 	tm.startTime = time.Now()
@@ -53,6 +103,7 @@ func (tm *TimerManager) StartTimer(duration time.Duration, state ClockState, onT
 
 	// Reset completion flag when starting a new timer
 	tm.isCompleting = false
+	tm.expireOnResume = false
 
 	// Create context for cancellation
 	tm.ctx, tm.cancel = context.WithCancel(context.Background())
@@ -62,9 +113,10 @@ func (tm *TimerManager) StartTimer(duration time.Duration, state ClockState, onT
 		tm.handleSessionComplete()
 	})
 
-	// Start the ticker for periodic updates (every 100ms for more responsive updates)
-	tm.ticker = time.NewTicker(100 * time.Millisecond)
-	go tm.tickerLoop()
+	// Start the ticker for periodic updates
+	tm.ticker = time.NewTicker(tm.tickInterval)
+	tm.tickerDone = make(chan struct{})
+	go tm.tickerLoop(tm.ctx, tm.ticker, tm.onTick, tm.tickerDone)
 }
 
 // PauseTimer pauses the timer and calculates remaining time
@@ -78,7 +130,6 @@ func (tm *TimerManager) PauseTimer() time.Duration {
 
 	// Calculate remaining time before stopping
 	tm.timeRemaining = tm.getTimeRemainingLocked()
-	tm.pauseTime = time.Now()
 
 	// Stop the timer and ticker
 	tm.stopTimer()
@@ -91,9 +142,10 @@ func (tm *TimerManager) ResumeTimer() {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
 
-	if tm.timeRemaining <= 0 {
+	if tm.timeRemaining <= 0 && !tm.expireOnResume {
 		return
 	}
+	tm.expireOnResume = false
 
 	// Ensure any existing timer is stopped first
 	tm.stopTimer()
@@ -101,14 +153,9 @@ func (tm *TimerManager) ResumeTimer() {
 	// Create new context
 	tm.ctx, tm.cancel = context.WithCancel(context.Background())
 
-	// Update start time to account for the pause duration
-	if !tm.pauseTime.IsZero() {
-		pauseDuration := time.Since(tm.pauseTime)
-		tm.startTime = time.Now().Add(-pauseDuration)
-	} else {
-		// If no pause time recorded, just use current time as start
-		tm.startTime = time.Now()
-	}
+	// The remaining time is counted from now; the time spent paused must not
+	// be subtracted from it
+	tm.startTime = time.Now()
 
 	// Update session duration to match remaining time
 	tm.sessionDuration = tm.timeRemaining
@@ -119,8 +166,9 @@ func (tm *TimerManager) ResumeTimer() {
 	})
 
 	// Start the ticker
-	tm.ticker = time.NewTicker(100 * time.Millisecond)
-	go tm.tickerLoop()
+	tm.ticker = time.NewTicker(tm.tickInterval)
+	tm.tickerDone = make(chan struct{})
+	go tm.tickerLoop(tm.ctx, tm.ticker, tm.onTick, tm.tickerDone)
 
 }
 
@@ -129,25 +177,69 @@ func (tm *TimerManager) StopTimer() {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
 	tm.stopTimer()
+	tm.expireOnResume = false
 }
 
-// GetTimeRemaining returns the current remaining time
-func (tm *TimerManager) GetTimeRemaining() time.Duration {
-	// No lock needed for reading - simple data access
+// AdjustRemaining adds delta, which may be negative, to the time remaining in
+// the current session and returns the new remaining time. The result is clamped
+// to [0, MaxSessionDuration]. A running session adjusted to zero completes
+// right away; a paused one completes as soon as it is resumed.
+func (tm *TimerManager) AdjustRemaining(delta time.Duration) (time.Duration, error) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	if tm.isCompleting {
+		return 0, ErrTimerCompleting
+	}
+
 	if tm.timer == nil {
-		return tm.timeRemaining
+		// Paused: only the stored remaining time changes
+		if tm.timeRemaining <= 0 && !tm.expireOnResume {
+			return 0, ErrNoTimer
+		}
+		before := tm.timeRemaining
+		tm.timeRemaining = clampRemaining(tm.timeRemaining + delta)
+		tm.sessionLength += tm.timeRemaining - before
+		tm.expireOnResume = tm.timeRemaining == 0
+		return tm.timeRemaining, nil
 	}
 
-	// Calculate remaining time based on elapsed time
-	elapsed := time.Since(tm.startTime)
-	if elapsed >= tm.sessionDuration {
-		return 0
+	// The timer may have fired already, with its completion waiting on tm.mu
+	if !tm.timer.Stop() {
+		return 0, ErrTimerCompleting
 	}
-	return tm.sessionDuration - elapsed
+
+	before := tm.getTimeRemainingLocked()
+	remaining := clampRemaining(before + delta)
+	// Keep startTime so elapsed stays right; only the total changes
+	tm.sessionDuration = time.Since(tm.startTime) + remaining
+	tm.sessionLength += remaining - before
+	tm.timer = time.AfterFunc(remaining, func() {
+		tm.handleSessionComplete()
+	})
+	return remaining, nil
 }
 
-// getTimeRemainingLocked calculates remaining time with lock held (internal method)
+// clampRemaining bounds an adjusted remaining time to [0, MaxSessionDuration]
+func clampRemaining(d time.Duration) time.Duration {
+	return min(max(d, 0), MaxSessionDuration)
+}
+
+// GetTimeRemaining returns the current remaining time
+func (tm *TimerManager) GetTimeRemaining() time.Duration {
+	// Read lock keeps this consistent with StartTimer/ResumeTimer writes
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.getTimeRemainingLocked()
+}
+
+// getTimeRemainingLocked calculates remaining time; the caller must hold tm.mu
 func (tm *TimerManager) getTimeRemainingLocked() time.Duration {
+	// Once the timer has fired the session is over, even while the completion
+	// callback is still running and before timeRemaining is reset to 0
+	if tm.isCompleting {
+		return 0
+	}
 	if tm.timer == nil {
 		return tm.timeRemaining
 	}
@@ -160,12 +252,52 @@ func (tm *TimerManager) getTimeRemainingLocked() time.Duration {
 	return tm.sessionDuration - elapsed
 }
 
+// GetElapsed returns how much of the current session has passed: its full
+// length, including adjustments, less the remaining time. It stays frozen
+// while the timer is paused.
+func (tm *TimerManager) GetElapsed() time.Duration {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.getElapsedLocked()
+}
+
+// getElapsedLocked calculates elapsed time; the caller must hold tm.mu
+func (tm *TimerManager) getElapsedLocked() time.Duration {
+	return max(tm.sessionLength-tm.getTimeRemainingLocked(), 0)
+}
+
+// GetSessionTiming returns the elapsed and remaining time of the current
+// session from one consistent reading
+func (tm *TimerManager) GetSessionTiming() (elapsed, remaining time.Duration) {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	remaining = tm.getTimeRemainingLocked()
+	return max(tm.sessionLength-remaining, 0), remaining
+}
+
+// setSessionLength sets the full length of a session whose timer was started
+// with only its remaining time, such as one resumed after a restart. It is
+// never set below the remaining time.
+func (tm *TimerManager) setSessionLength(length time.Duration) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.sessionLength = max(length, tm.getTimeRemainingLocked())
+}
+
 // IsRunning returns true if the timer is currently running
 func (tm *TimerManager) IsRunning() bool {
-	// No lock needed - in polling scenarios, slightly stale reads are acceptable
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
 	return tm.timer != nil
 }
 
+// IsCompleting returns true while the completion callback of the current timer is running
+func (tm *TimerManager) IsCompleting() bool {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.isCompleting
+}
+
 // stopTimer is an internal method to stop the timer and ticker
 func (tm *TimerManager) stopTimer() {
 	if tm.timer != nil {
@@ -188,22 +320,24 @@ func (tm *TimerManager) stopTimer() {
 
 }
 
-// tickerLoop runs the ticker loop for periodic updates
-func (tm *TimerManager) tickerLoop() {
-	ticker := tm.ticker // Capture ticker reference
-	if ticker == nil {
-		// This can happen if ticker is stopped between check and goroutine start
-		return
-	}
-
+// tickerLoop runs the ticker loop for periodic updates. The context, ticker and
+// callback are passed in rather than read from tm, so each goroutine watches the
+// context it was started with even after a restart reassigns tm.ctx. done is
+// closed on return, once no tick is being delivered.
+func (tm *TimerManager) tickerLoop(ctx context.Context, ticker *time.Ticker, onTick func(time.Duration), done chan struct{}) {
+	defer close(done)
 	for {
 		select {
 		case <-ticker.C:
-			if tm.onTick != nil {
-				remaining := tm.GetTimeRemaining()
-				tm.onTick(remaining)
+			// A tick and the cancellation can be ready at the same time; don't
+			// report a tick for a timer that has already been stopped
+			if ctx.Err() != nil {
+				return
+			}
+			if onTick != nil {
+				onTick(tm.GetTimeRemaining())
 			}
-		case <-tm.ctx.Done():
+		case <-ctx.Done():
 			return
 		}
 	}
@@ -227,6 +361,36 @@ func (tm *TimerManager) handleSessionComplete() {
 		tm.cancel = nil
 	}
 
+	// Wait for a tick being delivered by tickerLoop to finish, so ticks never
+	// run concurrently with each other
+	if done := tm.tickerDone; done != nil {
+		tm.tickerDone = nil
+		tm.mu.Unlock()
+		<-done
+		tm.mu.Lock()
+
+		// The timer was stopped or restarted while waiting
+		if !tm.isCompleting {
+			tm.mu.Unlock()
+			return
+		}
+	}
+
+	// Send a last tick of exactly zero, so displays land on 00:00 before the
+	// completion callback moves on; earlier ticks can stop short of zero
+	if tm.onTick != nil {
+		onTick := tm.onTick
+		tm.mu.Unlock()
+		onTick(0)
+		tm.mu.Lock()
+
+		// The timer was stopped or restarted while the tick was delivered
+		if !tm.isCompleting {
+			tm.mu.Unlock()
+			return
+		}
+	}
+
 	// Call completion callback while still holding the lock
 	if tm.onComplete != nil {
 		completionState := tm.currentState
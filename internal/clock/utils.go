@@ -1,17 +1,36 @@
 package clock
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 )
 
 // TimeFormatter handles time formatting utilities
-type TimeFormatter struct{}
+type TimeFormatter struct {
+	// grace is how far past zero a remaining time may go before it reads as
+	// over, see SetCompletionGrace
+	grace time.Duration
+}
+
+// DefaultCompletionGrace is how far past zero FormatTimeRemaining keeps
+// showing a session as running
+const DefaultCompletionGrace = 50 * time.Millisecond
 
 // NewTimeFormatter creates a new time formatter
 func NewTimeFormatter() *TimeFormatter {
-	return &TimeFormatter{}
+	return &TimeFormatter{grace: DefaultCompletionGrace}
+}
+
+// SetCompletionGrace sets how far below zero a remaining time may drop before
+// FormatTimeRemaining reports "Time's up!". Remaining times computed from an
+// end time can dip below zero a little before the completion event arrives;
+// within the grace they read "00:00 remaining" instead. Negative values are
+// treated as zero.
+func (tf *TimeFormatter) SetCompletionGrace(grace time.Duration) {
+	tf.grace = max(grace, 0)
 }
 
 // FormatDuration formats a duration as MM:SS
@@ -51,9 +70,11 @@ func (tf *TimeFormatter) FormatDurationLong(d time.Duration) string {
 	return fmt.Sprintf("%d minutes", minutes)
 }
 
-// FormatTimeRemaining formats remaining time with context
+// FormatTimeRemaining formats remaining time with context. The session reads
+// as running, down to "00:00 remaining", until remaining drops below the
+// completion grace; the completion event is what ends it.
 func (tf *TimeFormatter) FormatTimeRemaining(remaining time.Duration) string {
-	if remaining <= 0 {
+	if remaining < -tf.grace {
 		return "Time's up!"
 	}
 
@@ -103,10 +124,46 @@ func NewClockUtils() *ClockUtils {
 	return &ClockUtils{}
 }
 
+const (
+	// MinSessionDuration is the shortest session duration accepted by default
+	MinSessionDuration = time.Minute
+	// MaxSessionDuration is the longest session duration accepted
+	MaxSessionDuration = 4 * time.Hour
+	// MaxSessions is the longest schedule accepted; saved session indexes at or
+	// beyond it are treated as corrupt
+	MaxSessions = 100
+)
+
 // IsValidDuration checks if a duration is valid for pomodoro sessions
 func (cu *ClockUtils) IsValidDuration(d time.Duration) bool {
 	// Minimum 1 minute, maximum 4 hours
-	return d >= time.Minute && d <= 4*time.Hour
+	return cu.IsValidDurationWithMin(d, MinSessionDuration)
+}
+
+// IsValidDurationWithMin checks if a duration is valid against a custom minimum
+func (cu *ClockUtils) IsValidDurationWithMin(d, min time.Duration) bool {
+	return d > 0 && d >= min && d <= MaxSessionDuration
+}
+
+// ErrInvertedDurations is returned by ValidateDurations for durations that are
+// each valid but ordered in a way that is usually a mistake
+var ErrInvertedDurations = errors.New("inverted durations")
+
+// ValidateDurations reports a work session shorter than a short break, or a
+// long break shorter than a short break. The limits of each duration are
+// checked separately by IsValidDuration.
+func (cu *ClockUtils) ValidateDurations(work, shortBreak, longBreak time.Duration) error {
+	var problems []string
+	if work < shortBreak {
+		problems = append(problems, fmt.Sprintf("work %v is shorter than the short break %v", work, shortBreak))
+	}
+	if longBreak < shortBreak {
+		problems = append(problems, fmt.Sprintf("long break %v is shorter than the short break %v", longBreak, shortBreak))
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInvertedDurations, strings.Join(problems, "; "))
 }
 
 // GetRecommendedDurations returns recommended pomodoro durations
@@ -122,6 +179,29 @@ func (cu *ClockUtils) GetRecommendedDurations() map[string]time.Duration {
 	}
 }
 
+// ErrUnknownPreset is returned when a duration preset name is not recognised
+var ErrUnknownPreset = errors.New("unknown preset")
+
+// presetBreaks names the recommended break durations paired with each work preset
+var presetBreaks = map[string]struct{ shortBreak, longBreak string }{
+	"classic": {"short_break", "long_break"},
+	"short":   {"short_break", "long_break"},
+	"long":    {"short_break", "long_break"},
+	"micro":   {"micro_break", "short_break"},
+}
+
+// GetDurationPreset maps a work preset from GetRecommendedDurations ("classic",
+// "short", "long" or "micro") to a full set of work and break durations
+func (cu *ClockUtils) GetDurationPreset(name string) (work, shortBreak, longBreak time.Duration, err error) {
+	breaks, ok := presetBreaks[name]
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("%w: %q", ErrUnknownPreset, name)
+	}
+
+	recommended := cu.GetRecommendedDurations()
+	return recommended[name], recommended[breaks.shortBreak], recommended[breaks.longBreak], nil
+}
+
 // CalculateSessionProgress calculates the progress percentage of a session
 func (cu *ClockUtils) CalculateSessionProgress(elapsed, total time.Duration) float64 {
 	if total <= 0 {
@@ -165,8 +245,12 @@ func (cu *ClockUtils) FormatSessionInfo(state ClockState, sessionNum, totalSessi
 		return fmt.Sprintf("Short Break %d/%d (%s)", sessionNum, totalSessions, formatter.FormatDuration(duration))
 	case StateLongBreak:
 		return fmt.Sprintf("Long Break %d/%d (%s)", sessionNum, totalSessions, formatter.FormatDuration(duration))
+	case StateCustomBreak:
+		return fmt.Sprintf("Custom Break %d/%d (%s)", sessionNum, totalSessions, formatter.FormatDuration(duration))
 	case StatePaused:
 		return "Session Paused"
+	case StateWaiting:
+		return "Waiting to Start"
 	case StateIdle:
 		return "Ready to Start"
 	default:
@@ -179,9 +263,12 @@ func (cu *ClockUtils) ValidateSchedule(schedule []ClockState) error {
 	if len(schedule) == 0 {
 		return fmt.Errorf("schedule cannot be empty")
 	}
+	if len(schedule) > MaxSessions {
+		return fmt.Errorf("schedule has %d sessions, more than the maximum of %d", len(schedule), MaxSessions)
+	}
 
 	for i, state := range schedule {
-		if state != StateWorking && state != StateShortBreak && state != StateLongBreak {
+		if !isSessionState(state) {
 			return fmt.Errorf("invalid state at position %d: %s", i, state)
 		}
 	}
@@ -200,25 +287,47 @@ func (cu *ClockUtils) GetScheduleSummary(schedule []ClockState) map[ClockState]i
 	return summary
 }
 
-func ParseScheduling(schedulingString string) ([]ClockState, error) {
+// ParseScheduling parses a scheduling string such as "W-SB-W-LB" into session
+// specs. Each token may carry a duration in minutes, e.g. "W:50-SB:10-W:25-LB:20";
+// bare tokens use the default duration for their state.
+func ParseScheduling(schedulingString string) ([]SessionSpec, error) {
 	// remove all whitespace, newlines and tabs
 	schedulingString = strings.ReplaceAll(schedulingString, " ", "")
 	schedulingString = strings.ReplaceAll(schedulingString, "\n", "")
 	schedulingString = strings.ReplaceAll(schedulingString, "\t", "")
 
 	tokens := strings.Split(schedulingString, "-")
-	schedule := make([]ClockState, 0, len(tokens))
+	schedule := make([]SessionSpec, 0, len(tokens))
 	for _, token := range tokens {
+		stateToken, minutesToken, hasDuration := strings.Cut(token, ":")
+
 		// check if token is a valid ClockState
-		if _, ok := ClockStateMap[token]; ok {
-			schedule = append(schedule, ClockState(token))
-		} else {
+		if _, ok := ClockStateMap[stateToken]; !ok {
 			return nil, fmt.Errorf("invalid token: %s, valid tokens are: %v", token, ClockStateMap)
 		}
+
+		spec := SessionSpec{State: ClockState(stateToken)}
+		if hasDuration {
+			minutes, err := strconv.Atoi(minutesToken)
+			if err != nil || minutes <= 0 {
+				return nil, fmt.Errorf("invalid duration in token: %s, expected positive minutes", token)
+			}
+			spec.Duration = time.Duration(minutes) * time.Minute
+		}
+		schedule = append(schedule, spec)
 	}
 	return schedule, nil
 }
 
+// ScheduleStates extracts the states from a slice of session specs
+func ScheduleStates(specs []SessionSpec) []ClockState {
+	states := make([]ClockState, len(specs))
+	for i, spec := range specs {
+		states[i] = spec.State
+	}
+	return states
+}
+
 // FormatScheduling converts a slice of ClockState to a string format joined by "-"
 func FormatScheduling(schedule []ClockState) string {
 	if len(schedule) == 0 {
@@ -235,6 +344,8 @@ func FormatScheduling(schedule []ClockState) string {
 			tokens[i] = string(StateShortBreak)
 		case StateLongBreak:
 			tokens[i] = string(StateLongBreak)
+		case StateCustomBreak:
+			tokens[i] = string(StateCustomBreak)
 		default:
 			tokens[i] = string(state)
 		}
@@ -242,3 +353,21 @@ func FormatScheduling(schedule []ClockState) string {
 
 	return strings.Join(tokens, "-")
 }
+
+// FormatSchedulingSpecs converts session specs to the scheduling string format,
+// appending ":<minutes>" to sessions that carry a custom duration
+func FormatSchedulingSpecs(specs []SessionSpec) string {
+	if len(specs) == 0 {
+		return ""
+	}
+
+	tokens := make([]string, len(specs))
+	for i, spec := range specs {
+		tokens[i] = string(spec.State)
+		if spec.Duration > 0 {
+			tokens[i] = fmt.Sprintf("%s:%d", spec.State, int(spec.Duration.Minutes()))
+		}
+	}
+
+	return strings.Join(tokens, "-")
+}
@@ -0,0 +1,70 @@
+package clock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	// webhookTimeout bounds how long a slow endpoint can hold a delivery
+	webhookTimeout = 5 * time.Second
+)
+
+// CompletionWebhookPayload is the JSON body posted to the completion webhook
+type CompletionWebhookPayload struct {
+	CompletedState ClockState `json:"completedState"`
+	NextState      ClockState `json:"nextState"`
+	SessionNumber  int        `json:"sessionNumber"`
+	CompletedAt    time.Time  `json:"completedAt"`
+}
+
+// WebhookNotifier posts session completion events to a configured URL
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a new webhook notifier for the given URL
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// NotifyAsync delivers the payload in the background so the clock goroutine is never blocked.
+// Failures are logged and otherwise ignored.
+func (wn *WebhookNotifier) NotifyAsync(payload CompletionWebhookPayload) {
+	go func() {
+		if err := wn.Notify(payload); err != nil {
+			slog.Warn("failed to deliver completion webhook",
+				"session", payload.SessionNumber,
+				"state", payload.CompletedState,
+				"error", err)
+		}
+	}()
+}
+
+// Notify delivers the payload synchronously
+func (wn *WebhookNotifier) Notify(payload CompletionWebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	resp, err := wn.client.Post(wn.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
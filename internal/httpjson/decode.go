@@ -0,0 +1,83 @@
+// Package httpjson decodes JSON request bodies with a size limit and strict
+// field checking, so handlers reject junk with a clear message instead of
+// silently ignoring it.
+package httpjson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxBodyBytes is the body size limit used by most handlers
+const DefaultMaxBodyBytes = 1 << 20
+
+var (
+	// ErrBodyTooLarge is returned when the body exceeds the limit
+	ErrBodyTooLarge = errors.New("request body too large")
+	// ErrInvalidBody wraps the reason a body could not be decoded
+	ErrInvalidBody = errors.New("invalid request body")
+)
+
+// Decode reads a single JSON value from the request body into dst. The body is
+// limited to maxBytes, and unknown fields or data after the value are
+// rejected. Errors wrap ErrBodyTooLarge or ErrInvalidBody and their message is
+// meant for the client.
+func Decode(w http.ResponseWriter, r *http.Request, dst any, maxBytes int64) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		return decodeError(err, maxBytes)
+	}
+	// Anything but the end of the body after the value is rejected
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return decodeError(err, maxBytes)
+		}
+		return fmt.Errorf("%w: body must contain a single JSON value", ErrInvalidBody)
+	}
+	return nil
+}
+
+// decodeError turns a json.Decoder error into one fit for the client
+func decodeError(err error, maxBytes int64) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var tooLarge *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &tooLarge):
+		return fmt.Errorf("%w: must not exceed %d bytes", ErrBodyTooLarge, maxBytes)
+	case errors.Is(err, io.EOF):
+		return fmt.Errorf("%w: body must not be empty", ErrInvalidBody)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return fmt.Errorf("%w: malformed JSON", ErrInvalidBody)
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("%w: malformed JSON at position %d", ErrInvalidBody, syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return fmt.Errorf("%w: unexpected JSON %s", ErrInvalidBody, typeErr.Value)
+		}
+		return fmt.Errorf("%w: wrong type for field %q", ErrInvalidBody, typeErr.Field)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no error type for unknown fields
+		return fmt.Errorf("%w: unknown field %s", ErrInvalidBody, strings.TrimPrefix(err.Error(), "json: unknown field "))
+	default:
+		return fmt.Errorf("%w: %v", ErrInvalidBody, err)
+	}
+}
+
+// StatusCode returns the HTTP status for a Decode error: 413 when the body
+// was too large and 400 otherwise
+func StatusCode(err error) int {
+	if errors.Is(err, ErrBodyTooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
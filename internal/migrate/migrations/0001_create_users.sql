@@ -0,0 +1,36 @@
+-- Enable UUID extension for generating user IDs
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+-- Create user role enum type; databases initialised from
+-- scripts/postgres/schema.sql already have it
+DO $$
+BEGIN
+	CREATE TYPE user_role AS ENUM ('USER', 'ADMIN');
+EXCEPTION
+	WHEN duplicate_object THEN NULL;
+END
+$$;
+
+-- Create users table
+CREATE TABLE IF NOT EXISTS users(
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	username TEXT UNIQUE NOT NULL,
+	email TEXT UNIQUE NOT NULL,
+	password_hash TEXT NOT NULL,
+	role user_role NOT NULL DEFAULT 'USER',
+	created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT now(),
+	email_verified BOOLEAN NOT NULL DEFAULT false
+);
+
+-- Add the verification flag to databases created before it existed
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT false;
+
+-- Create indexes for better query performance
+CREATE INDEX IF NOT EXISTS idx_users_username ON users(username);
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
+
+-- Add comments for documentation
+COMMENT ON TABLE users IS 'User accounts for the Pomodoro service';
+COMMENT ON COLUMN users.role IS 'User role for access control: USER or ADMIN';
+COMMENT ON COLUMN users.email_verified IS 'Set once the user follows their email verification link';
@@ -0,0 +1,12 @@
+-- Create per-user pomodoro settings; durations are in minutes
+CREATE TABLE IF NOT EXISTS user_settings(
+	user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+	work_minutes INTEGER NOT NULL,
+	short_break_minutes INTEGER NOT NULL,
+	long_break_minutes INTEGER NOT NULL,
+	scheduling TEXT NOT NULL,
+	updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT now()
+);
+
+COMMENT ON TABLE user_settings IS 'Pomodoro durations and schedule chosen by each user';
+COMMENT ON COLUMN user_settings.scheduling IS 'Session order such as W-SB-W-LB, optionally with per-session minutes';
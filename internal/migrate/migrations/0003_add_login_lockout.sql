@@ -0,0 +1,6 @@
+-- Track consecutive failed logins so repeated bad passwords lock the account
+ALTER TABLE users ADD COLUMN IF NOT EXISTS failed_login_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITHOUT TIME ZONE;
+
+COMMENT ON COLUMN users.failed_login_attempts IS 'Consecutive failed logins since the last success or lock';
+COMMENT ON COLUMN users.locked_until IS 'Logins are refused until this time (UTC) after too many failures';
@@ -0,0 +1,5 @@
+-- Emails, and usernames with CASE_INSENSITIVE_USERNAMES, are checked for
+-- duplicates without case; these indexes keep those lookups fast. They are not
+-- unique, so existing rows differing only in case do not block the migration.
+CREATE INDEX IF NOT EXISTS idx_users_email_lower ON users(lower(email));
+CREATE INDEX IF NOT EXISTS idx_users_username_lower ON users(lower(username));
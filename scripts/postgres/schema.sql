@@ -14,16 +14,42 @@ CREATE TABLE IF NOT EXISTS users(
 	email TEXT UNIQUE NOT NULL,
 	password_hash TEXT NOT NULL,
 	role user_role NOT NULL DEFAULT 'USER',
-	created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT now()
+	created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT now(),
+	email_verified BOOLEAN NOT NULL DEFAULT false,
+	failed_login_attempts INTEGER NOT NULL DEFAULT 0,
+	locked_until TIMESTAMP WITHOUT TIME ZONE
 );
 
+-- Add the verification flag to databases created before it existed
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS failed_login_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITHOUT TIME ZONE;
+
 -- Create indexes for better query performance
 CREATE INDEX IF NOT EXISTS idx_users_username ON users(username);
 CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+CREATE INDEX IF NOT EXISTS idx_users_email_lower ON users(lower(email));
+CREATE INDEX IF NOT EXISTS idx_users_username_lower ON users(lower(username));
 CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
 
 -- Add comments for documentation
 COMMENT ON TABLE users IS 'User accounts for the Pomodoro service';
 COMMENT ON COLUMN users.role IS 'User role for access control: USER or ADMIN';
+COMMENT ON COLUMN users.email_verified IS 'Set once the user follows their email verification link';
+COMMENT ON COLUMN users.failed_login_attempts IS 'Consecutive failed logins since the last success or lock';
+COMMENT ON COLUMN users.locked_until IS 'Logins are refused until this time (UTC) after too many failures';
+
 
 
+-- Create per-user pomodoro settings; durations are in minutes
+CREATE TABLE IF NOT EXISTS user_settings(
+	user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+	work_minutes INTEGER NOT NULL,
+	short_break_minutes INTEGER NOT NULL,
+	long_break_minutes INTEGER NOT NULL,
+	scheduling TEXT NOT NULL,
+	updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT now()
+);
+
+COMMENT ON TABLE user_settings IS 'Pomodoro durations and schedule chosen by each user';
+COMMENT ON COLUMN user_settings.scheduling IS 'Session order such as W-SB-W-LB, optionally with per-session minutes';
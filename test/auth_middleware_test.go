@@ -0,0 +1,251 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"pomodoroService/internal/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakeAuthRepo is an in-memory AuthRepository for middleware tests
+type fakeAuthRepo struct {
+	users map[string]*auth.User
+}
+
+func newFakeAuthRepo() *fakeAuthRepo {
+	return &fakeAuthRepo{users: make(map[string]*auth.User)}
+}
+
+func (f *fakeAuthRepo) addUser(id, username, role string) *auth.User {
+	user := &auth.User{ID: &id, Username: &username, Role: &role}
+	f.users[username] = user
+	return user
+}
+
+func (f *fakeAuthRepo) CreateUser(user *auth.User) error {
+	f.users[*user.Username] = user
+	return nil
+}
+
+func (f *fakeAuthRepo) AuthenticateUser(credentials *auth.UserLoginCredentials) (bool, error) {
+	_, ok := f.users[credentials.Username]
+	return ok, nil
+}
+
+func (f *fakeAuthRepo) GetUserInfo(username string) (*auth.User, error) {
+	user, ok := f.users[username]
+	if !ok {
+		return nil, auth.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (f *fakeAuthRepo) UpdateUserRole(username, role string) error {
+	user, ok := f.users[username]
+	if !ok {
+		return auth.ErrUserNotFound
+	}
+	user.Role = &role
+	return nil
+}
+
+func (f *fakeAuthRepo) VerifyEmail(username string) error {
+	user, ok := f.users[username]
+	if !ok {
+		return auth.ErrUserNotFound
+	}
+	verified := true
+	user.EmailVerified = &verified
+	return nil
+}
+
+func (f *fakeAuthRepo) DeleteUser(userID string) error {
+	for username, user := range f.users {
+		if *user.ID == userID {
+			delete(f.users, username)
+			return nil
+		}
+	}
+	return auth.ErrUserNotFound
+}
+
+// serveWithToken runs a request for the given user through the middleware and returns the status code
+func serveWithToken(t *testing.T, middleware func(http.Handler) http.Handler, user *auth.User) int {
+	t.Helper()
+
+	token, err := auth.GenerateJWT(user)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestMiddlewareDeletedUserIsUnauthorized(t *testing.T) {
+	repo := newFakeAuthRepo()
+	user := repo.addUser("1", "ghost", "USER")
+
+	token, err := auth.GenerateJWT(user)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	// The token stays valid after the account is removed
+	if err := repo.DeleteUser("1"); err != nil {
+		t.Fatalf("Failed to delete user: %v", err)
+	}
+
+	handler := auth.RequireAnyUserRole(repo)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/profile", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a token whose user no longer exists, got %d", rec.Code)
+	}
+}
+
+func TestAdminRoleRequiresVerifiedEmail(t *testing.T) {
+	repo := newFakeAuthRepo()
+	admin := repo.addUser("1", "admin", "ADMIN")
+
+	if code := serveWithToken(t, auth.RequireAdminRole(repo), admin); code != http.StatusForbidden {
+		t.Errorf("Expected 403 for an unverified admin, got %d", code)
+	}
+
+	// Unverified users can still use routes that do not require verification
+	if code := serveWithToken(t, auth.RequireAnyUserRole(repo), admin); code != http.StatusOK {
+		t.Errorf("Expected 200 for an unverified user on an open route, got %d", code)
+	}
+	if code := serveWithToken(t, auth.RequireAnyUserRole(repo, auth.RequireVerifiedEmail()), admin); code != http.StatusForbidden {
+		t.Errorf("Expected 403 for an unverified user on a verified-only route, got %d", code)
+	}
+
+	repo.VerifyEmail("admin")
+	if code := serveWithToken(t, auth.RequireAdminRole(repo), admin); code != http.StatusOK {
+		t.Errorf("Expected 200 for a verified admin, got %d", code)
+	}
+}
+
+func TestMiddlewareRecordsAuthenticatedUser(t *testing.T) {
+	repo := newFakeAuthRepo()
+	user := repo.addUser("42", "alice", "USER")
+
+	token, err := auth.GenerateJWT(user)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	handler := auth.RequireAnyUserRole(repo)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx, userID := auth.WithAuthenticatedUser(req.Context())
+	req = req.WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer "+token)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if *userID != "42" {
+		t.Errorf("Expected user ID 42 to be recorded, got %q", *userID)
+	}
+
+	// Rejected requests leave it empty
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx, userID = auth.WithAuthenticatedUser(req.Context())
+	handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+	if *userID != "" {
+		t.Errorf("Expected no user ID for an unauthenticated request, got %q", *userID)
+	}
+}
+
+func TestValidateJWTRequiresIssuer(t *testing.T) {
+	repo := newFakeAuthRepo()
+	user := repo.addUser("1", "alice", "USER")
+
+	token, err := auth.GenerateJWT(user)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	claims, err := auth.ValidateJWT(token)
+	if err != nil {
+		t.Fatalf("Expected a generated token to validate, got %v", err)
+	}
+	if claims.Issuer != "pomodoro-service" {
+		t.Errorf("Expected issuer pomodoro-service, got %q", claims.Issuer)
+	}
+
+	// Tokens signed with the same secret but another issuer, or none, are rejected
+	for _, issuer := range []string{"other-service", ""} {
+		forged := jwt.NewWithClaims(jwt.SigningMethodHS256, &auth.JWTClaims{
+			Username: "alice",
+			UserID:   "1",
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+				Issuer:    issuer,
+			},
+		})
+		signed, err := forged.SignedString([]byte(os.Getenv("JWT_SECRET")))
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+
+		// A missing issuer is reported as a missing required claim
+		if _, err := auth.ValidateJWT(signed); !errors.Is(err, jwt.ErrTokenInvalidClaims) {
+			t.Errorf("Expected invalid claims for issuer %q, got %v", issuer, err)
+		}
+
+		handler := auth.RequireAnyUserRole(repo)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+signed)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 for issuer %q, got %d", issuer, rec.Code)
+		}
+	}
+}
+
+func TestMiddlewareStoresClaims(t *testing.T) {
+	repo := newFakeAuthRepo()
+	user := repo.addUser("7", "bob", "USER")
+
+	token, err := auth.GenerateJWT(user)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	var claims *auth.JWTClaims
+	handler := auth.RequireAnyUserRole(repo)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _ = auth.GetClaimsFromContext(r.Context())
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/auth/whoami", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if claims == nil {
+		t.Fatal("Expected the token claims in the request context")
+	}
+	if claims.UserID != "7" || claims.Username != "bob" || claims.Issuer != "pomodoro-service" || claims.ExpiresAt == nil {
+		t.Errorf("Unexpected claims: %+v", claims)
+	}
+}
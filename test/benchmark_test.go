@@ -1,13 +1,14 @@
 package test
 
 import (
+	"slices"
 	"testing"
 
 	"pomodoroService/internal/clock"
 )
 
 func BenchmarkConcurrentReads(b *testing.B) {
-	cr := clock.NewClockRunner()
+	cr := NewShortDurationClockRunner()
 
 	// Start a session to have some state to read
 	cr.Start()
@@ -25,7 +26,7 @@ func BenchmarkConcurrentReads(b *testing.B) {
 }
 
 func BenchmarkMixedReadWrite(b *testing.B) {
-	cr := clock.NewClockRunner()
+	cr := NewShortDurationClockRunner()
 
 	b.ResetTimer()
 	b.RunParallel(func(pb *testing.PB) {
@@ -51,7 +52,7 @@ func BenchmarkMixedReadWrite(b *testing.B) {
 }
 
 func BenchmarkHeavyPolling(b *testing.B) {
-	cr := clock.NewClockRunner()
+	cr := NewShortDurationClockRunner()
 
 	// Start a session
 	cr.Start()
@@ -68,3 +69,29 @@ func BenchmarkHeavyPolling(b *testing.B) {
 		}
 	})
 }
+
+// BenchmarkGetSchedule compares reading the shared schedule with the copy every
+// read used to make
+func BenchmarkGetSchedule(b *testing.B) {
+	cr := NewShortDurationClockRunner()
+	cr.Start()
+	defer cr.Stop()
+
+	b.Run("Shared", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_ = cr.GetSchedule()
+			}
+		})
+	})
+
+	b.Run("Copy", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_ = slices.Clone(cr.GetSchedule())
+			}
+		})
+	})
+}
@@ -2,6 +2,7 @@ package test
 
 import (
 	"pomodoroService/internal/clock"
+	"runtime"
 	"sync"
 	"testing"
 	"time"
@@ -9,7 +10,7 @@ import (
 
 // TestPomodoroWorkflow tests a complete pomodoro workflow
 func TestPomodoroWorkflow(t *testing.T) {
-	cr := clock.NewClockRunner()
+	cr := NewShortDurationClockRunner()
 
 	// Set longer durations for more reliable testing
 	cr.SetDurations(200*time.Millisecond, 100*time.Millisecond, 150*time.Millisecond)
@@ -37,7 +38,7 @@ func TestPomodoroWorkflow(t *testing.T) {
 
 	// Start the pomodoro session
 	t.Log("Starting pomodoro session...")
-	err := cr.Start()
+	_, err := cr.Start()
 	if err != nil {
 		t.Fatalf("Failed to start clock: %v", err)
 	}
@@ -76,7 +77,7 @@ func TestPomodoroWorkflow(t *testing.T) {
 
 // TestPauseResumeWorkflow tests pausing and resuming during a session
 func TestPauseResumeWorkflow(t *testing.T) {
-	cr := clock.NewClockRunner()
+	cr := NewShortDurationClockRunner()
 	cr.SetDurations(200*time.Millisecond, 100*time.Millisecond, 150*time.Millisecond)
 
 	var pauseResumeCount int
@@ -133,7 +134,7 @@ func TestPauseResumeWorkflow(t *testing.T) {
 
 // TestSkipWorkflow tests skipping sessions
 func TestSkipWorkflow(t *testing.T) {
-	cr := clock.NewClockRunner()
+	cr := NewShortDurationClockRunner()
 	cr.SetDurations(100*time.Millisecond, 50*time.Millisecond, 75*time.Millisecond)
 
 	var skippedStates []clock.ClockState
@@ -177,7 +178,7 @@ func TestSkipWorkflow(t *testing.T) {
 
 // TestConcurrentAccess tests multiple goroutines accessing the clock runner
 func TestConcurrentAccess(t *testing.T) {
-	cr := clock.NewClockRunner()
+	cr := NewShortDurationClockRunner()
 	cr.SetDurations(1*time.Second, 500*time.Millisecond, 750*time.Millisecond)
 
 	var wg sync.WaitGroup
@@ -239,7 +240,7 @@ func TestConcurrentAccess(t *testing.T) {
 
 // TestRealisticPomodoroSession tests with realistic durations
 func TestRealisticPomodoroSession(t *testing.T) {
-	cr := clock.NewClockRunner()
+	cr := NewShortDurationClockRunner()
 
 	// Use realistic durations (but shorter for testing)
 	cr.SetDurations(2*time.Second, 1*time.Second, 3*time.Second)
@@ -295,9 +296,49 @@ func TestRealisticPomodoroSession(t *testing.T) {
 	}
 }
 
+// TestRapidRestartDoesNotLeakGoroutines tests that starting and stopping the clock
+// many times does not leave ticker goroutines running
+func TestRapidRestartDoesNotLeakGoroutines(t *testing.T) {
+	cr := NewShortDurationClockRunner()
+	cr.SetDurations(time.Second, 500*time.Millisecond, time.Second)
+	cr.SetTickInterval(time.Millisecond)
+	cr.SetCallbacks(nil, func(time.Duration) {}, nil)
+
+	baseline := runtime.NumGoroutine()
+
+	for i := 1; i <= 5000; i++ {
+		cr.Start()
+		if i%2 == 0 {
+			cr.Pause()
+			cr.Start()
+		}
+		cr.Stop()
+
+		// Stopped loops may not have been scheduled yet, so give them time to exit
+		if i%1000 == 0 {
+			if n := waitForGoroutines(baseline+2, time.Second); n > baseline+2 {
+				t.Fatalf("Goroutine count grew from %d to %d after %d cycles", baseline, n, i)
+			}
+		}
+	}
+}
+
+// waitForGoroutines polls until at most max goroutines are running or the timeout
+// expires, and returns the last observed count
+func waitForGoroutines(max int, timeout time.Duration) int {
+	deadline := time.Now().Add(timeout)
+	for {
+		n := runtime.NumGoroutine()
+		if n <= max || time.Now().After(deadline) {
+			return n
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
 // TestEdgeCases tests various edge cases
 func TestEdgeCases(t *testing.T) {
-	cr := clock.NewClockRunner()
+	cr := NewShortDurationClockRunner()
 
 	// Test very short durations
 	cr.SetDurations(10*time.Millisecond, 5*time.Millisecond, 15*time.Millisecond)
@@ -332,7 +373,7 @@ func TestEdgeCases(t *testing.T) {
 func TestMemoryLeaks(t *testing.T) {
 	// Run multiple clock runners to check for resource leaks
 	for i := 0; i < 10; i++ {
-		cr := clock.NewClockRunner()
+		cr := NewShortDurationClockRunner()
 		cr.SetDurations(50*time.Millisecond, 25*time.Millisecond, 75*time.Millisecond)
 
 		cr.Start()
@@ -348,7 +389,7 @@ func TestMemoryLeaks(t *testing.T) {
 
 // BenchmarkIntegration benchmarks the complete pomodoro workflow
 func BenchmarkIntegration(b *testing.B) {
-	cr := clock.NewClockRunner()
+	cr := NewShortDurationClockRunner()
 	cr.SetDurations(10*time.Millisecond, 5*time.Millisecond, 15*time.Millisecond)
 
 	b.ResetTimer()
@@ -261,26 +261,26 @@ func TestErrorConditions(t *testing.T) {
 
 	// Test starting when already running
 	tcr.Start()
-	err := tcr.Start()
+	_, err := tcr.Start()
 	if err == nil {
 		t.Error("Expected error when starting already running clock")
 	}
 
 	// Test pausing when idle
 	tcr.Stop()
-	err = tcr.Pause()
+	_, err = tcr.Pause()
 	if err == nil {
 		t.Error("Expected error when pausing idle clock")
 	}
 
 	// Test stopping when already idle
-	err = tcr.Stop()
+	_, err = tcr.Stop()
 	if err == nil {
 		t.Error("Expected error when stopping already idle clock")
 	}
 
 	// Test skipping when idle
-	err = tcr.Skip()
+	_, err = tcr.Skip()
 	if err == nil {
 		t.Error("Expected error when skipping idle clock")
 	}
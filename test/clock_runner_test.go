@@ -1,7 +1,10 @@
 package test
 
 import (
+	"errors"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -38,7 +41,9 @@ func TestSetDurations(t *testing.T) {
 	shortDur := 10 * time.Minute
 	longDur := 20 * time.Minute
 
-	cr.SetDurations(workDur, shortDur, longDur)
+	if err := cr.SetDurations(workDur, shortDur, longDur); err != nil {
+		t.Fatalf("Expected valid durations to be accepted, got %v", err)
+	}
 
 	// Test that durations are set correctly by checking session manager behavior
 	// We can verify this by starting a session and checking the duration
@@ -50,12 +55,56 @@ func TestSetDurations(t *testing.T) {
 	cr.Stop()
 }
 
-func TestStart(t *testing.T) {
+func TestSetDurationsValidation(t *testing.T) {
 	cr := NewClockRunner()
+
+	invalid := []struct {
+		name                 string
+		work, short, longDur time.Duration
+	}{
+		{"zero work", 0, 5 * time.Minute, 15 * time.Minute},
+		{"negative short break", 25 * time.Minute, -time.Minute, 15 * time.Minute},
+		{"below minimum", 25 * time.Minute, 5 * time.Minute, 30 * time.Second},
+		{"above maximum", 5 * time.Hour, 5 * time.Minute, 15 * time.Minute},
+	}
+
+	for _, tc := range invalid {
+		if err := cr.SetDurations(tc.work, tc.short, tc.longDur); err == nil {
+			t.Errorf("%s: expected SetDurations to return an error", tc.name)
+		}
+	}
+
+	// Rejected durations must leave the defaults untouched
+	cr.Start()
+	defer cr.Stop()
+	if remaining := cr.GetTimeRemaining(); remaining <= 24*time.Minute || remaining > 25*time.Minute {
+		t.Errorf("Expected default 25m work session after rejected updates, got %v", remaining)
+	}
+}
+
+func TestWithMinDuration(t *testing.T) {
+	strict := NewClockRunner()
+	if err := strict.SetDurations(200*time.Millisecond, 100*time.Millisecond, 150*time.Millisecond); err == nil {
+		t.Error("Expected default runner to reject sub-minute durations")
+	}
+
+	relaxed := NewClockRunner(WithMinDuration(time.Millisecond))
+	if err := relaxed.SetDurations(200*time.Millisecond, 100*time.Millisecond, 150*time.Millisecond); err != nil {
+		t.Errorf("Expected WithMinDuration runner to accept sub-second durations, got %v", err)
+	}
+
+	// The maximum still applies when the minimum is lowered
+	if err := relaxed.SetDurations(5*time.Hour, time.Minute, time.Minute); err == nil {
+		t.Error("Expected WithMinDuration runner to keep enforcing the maximum duration")
+	}
+}
+
+func TestStart(t *testing.T) {
+	cr := NewShortDurationClockRunner()
 	cr.SetDurations(1*time.Second, 500*time.Millisecond, 1*time.Second)
 
 	// Test starting from idle state
-	err := cr.Start()
+	_, err := cr.Start()
 	if err != nil {
 		t.Errorf("Expected no error when starting from idle, got %v", err)
 	}
@@ -69,14 +118,222 @@ func TestStart(t *testing.T) {
 	}
 
 	// Test starting when already running
-	err = cr.Start()
+	_, err = cr.Start()
 	if err == nil {
 		t.Error("Expected error when starting already running clock")
 	}
 }
 
-func TestPause(t *testing.T) {
+func TestStartAt(t *testing.T) {
+	cr := NewShortDurationClockRunner()
+	cr.SetDurations(1*time.Second, 500*time.Millisecond, 1*time.Second)
+	defer cr.Stop()
+
+	// Default schedule has 8 sessions
+	for _, session := range []int{-1, 8, 100} {
+		if _, err := cr.StartAt(session); err == nil {
+			t.Errorf("Expected error when starting at out-of-range session %d", session)
+		}
+	}
+	if !cr.IsIdle() {
+		t.Fatalf("Expected clock to stay idle after rejected starts, got %s", cr.GetState())
+	}
+
+	// Session 3 of W-SB-W-SB-W-SB-W-LB is a short break; 7 is the long break
+	result, err := cr.StartAt(3)
+	if err != nil {
+		t.Fatalf("Expected no error when starting at session 3, got %v", err)
+	}
+	if result.State != StateShortBreak || result.Session != 3 {
+		t.Errorf("Expected short break at session 3, got %s at session %d", result.State, result.Session)
+	}
+	if cr.GetCurrentSession() != 3 || cr.GetState() != StateShortBreak {
+		t.Errorf("Expected clock at session 3 in short break, got session %d in %s", cr.GetCurrentSession(), cr.GetState())
+	}
+
+	if _, err := cr.StartAt(0); err == nil {
+		t.Error("Expected error when starting at a session while running")
+	}
+
+	cr.Stop()
+	if result, err := cr.StartAt(7); err != nil || result.State != StateLongBreak {
+		t.Errorf("Expected long break at session 7, got %s (err %v)", result.State, err)
+	}
+}
+
+func TestStartIdempotent(t *testing.T) {
+	cr := NewShortDurationClockRunner()
+	cr.SetDurations(1*time.Second, 500*time.Millisecond, 1*time.Second)
+
+	_, alreadyRunning, err := cr.StartIdempotent()
+	if err != nil {
+		t.Errorf("Expected no error when starting from idle, got %v", err)
+	}
+	if alreadyRunning {
+		t.Error("Expected first start to report that the clock was not already running")
+	}
+
+	// A second start should be a no-op rather than an error
+	_, alreadyRunning, err = cr.StartIdempotent()
+	if err != nil {
+		t.Errorf("Expected no error when starting an already running clock, got %v", err)
+	}
+	if !alreadyRunning {
+		t.Error("Expected second start to report that the clock was already running")
+	}
+
+	if cr.GetState() != StateWorking || cr.GetCurrentSession() != 0 {
+		t.Errorf("Expected clock to remain in session 0 working, got %s session %d", cr.GetState(), cr.GetCurrentSession())
+	}
+
+	cr.Stop()
+}
+
+func TestActionResultSnapshots(t *testing.T) {
+	cr := NewShortDurationClockRunner()
+	cr.SetDurations(1*time.Second, 500*time.Millisecond, 1*time.Second)
+
+	result, err := cr.Start()
+	if err != nil {
+		t.Fatalf("Expected no error when starting, got %v", err)
+	}
+	if result.State != StateWorking || result.Session != 0 {
+		t.Errorf("Expected start result working session 0, got %s session %d", result.State, result.Session)
+	}
+	if result.TimeRemaining <= 0 || result.TimeRemaining > time.Second || result.EndTime.IsZero() {
+		t.Errorf("Expected start result to carry remaining time and end time, got %v / %v", result.TimeRemaining, result.EndTime)
+	}
+
+	result, err = cr.Pause()
+	if err != nil {
+		t.Fatalf("Expected no error when pausing, got %v", err)
+	}
+	if result.State != StatePaused || !result.EndTime.IsZero() {
+		t.Errorf("Expected paused result without end time, got %s / %v", result.State, result.EndTime)
+	}
+
+	cr.Start()
+	result, err = cr.Skip()
+	if err != nil {
+		t.Fatalf("Expected no error when skipping, got %v", err)
+	}
+	if result.State != StateShortBreak || result.Session != 1 {
+		t.Errorf("Expected skip result short break session 1, got %s session %d", result.State, result.Session)
+	}
+
+	result, err = cr.Stop()
+	if err != nil {
+		t.Fatalf("Expected no error when stopping, got %v", err)
+	}
+	if result.State != StateIdle || result.Session != 0 {
+		t.Errorf("Expected stop result idle session 0, got %s session %d", result.State, result.Session)
+	}
+
+	// Failed transitions return an error and a zero result
+	if result, err := cr.Pause(); err == nil || result.State != "" {
+		t.Errorf("Expected pausing an idle clock to fail with a zero result, got %v / %+v", err, result)
+	}
+}
+
+func TestCycleProgress(t *testing.T) {
+	cr := NewShortDurationClockRunner()
+	cr.SetDurations(200*time.Millisecond, 100*time.Millisecond, 150*time.Millisecond)
+
+	// Default schedule: 4 work, 3 short breaks, 1 long break
+	expectedTotal := 4*200*time.Millisecond + 3*100*time.Millisecond + 150*time.Millisecond
+
+	progress := cr.GetCycleProgress()
+	if progress.Total != expectedTotal || progress.Elapsed != 0 || progress.Percent != 0 {
+		t.Errorf("Expected idle progress 0 of %v, got %+v", expectedTotal, progress)
+	}
+
+	cr.Start()
+	defer cr.Stop()
+	cr.Skip()
+	time.Sleep(30 * time.Millisecond)
+	cr.Pause()
+
+	// One completed work session plus part of the short break
+	progress = cr.GetCycleProgress()
+	if progress.Elapsed < 200*time.Millisecond || progress.Elapsed > 300*time.Millisecond {
+		t.Errorf("Expected elapsed between 200ms and 300ms, got %v", progress.Elapsed)
+	}
+	if progress.Percent <= 0 || progress.Percent >= 100 {
+		t.Errorf("Expected percent between 0 and 100, got %v", progress.Percent)
+	}
+
+	// Elapsed time is frozen while paused
+	time.Sleep(50 * time.Millisecond)
+	if paused := cr.GetCycleProgress(); paused.Elapsed != progress.Elapsed {
+		t.Errorf("Expected elapsed to stay at %v while paused, got %v", progress.Elapsed, paused.Elapsed)
+	}
+}
+
+func TestSessionProgress(t *testing.T) {
+	cr := NewShortDurationClockRunner()
+	cr.SetDurations(200*time.Millisecond, 100*time.Millisecond, 150*time.Millisecond)
+
+	if progress := cr.GetSessionProgress(); progress != (SessionProgress{}) {
+		t.Errorf("Expected no progress while idle, got %+v", progress)
+	}
+
+	cr.Start()
+	defer cr.Stop()
+	time.Sleep(50 * time.Millisecond)
+	cr.Pause()
+
+	progress := cr.GetSessionProgress()
+	if progress.Total != 200*time.Millisecond {
+		t.Errorf("Expected the 200ms work session as total, got %v", progress.Total)
+	}
+	if progress.Elapsed < 50*time.Millisecond || progress.Elapsed > 100*time.Millisecond {
+		t.Errorf("Expected elapsed between 50ms and 100ms, got %v", progress.Elapsed)
+	}
+	if want := float64(progress.Elapsed) / float64(progress.Total) * 100; progress.Percent != want {
+		t.Errorf("Expected %v%%, got %v%%", want, progress.Percent)
+	}
+
+	// Frozen while paused, and counted on from there after resuming
+	time.Sleep(50 * time.Millisecond)
+	if paused := cr.GetSessionProgress(); paused != progress {
+		t.Errorf("Expected progress to stay at %+v while paused, got %+v", progress, paused)
+	}
+	cr.Start()
+	if resumed := cr.GetSessionProgress(); resumed.Elapsed < progress.Elapsed || resumed.Total != progress.Total {
+		t.Errorf("Expected resumed progress to continue from %v of %v, got %+v", progress.Elapsed, progress.Total, resumed)
+	}
+}
+
+func TestFormatSessionInfo(t *testing.T) {
 	cr := NewClockRunner()
+	defer cr.Close()
+
+	if label := cr.FormatSessionInfo(); label != "Ready to Start" {
+		t.Errorf("Expected idle label, got %q", label)
+	}
+
+	cr.Start()
+	defer cr.Stop()
+	cr.Skip()
+	cr.Skip()
+
+	// Default schedule is W-SB-W-SB-W-SB-W-LB with 25 minute work sessions
+	state, session, total, duration := cr.GetSessionInfo()
+	if state != StateWorking || session != 2 || total != 8 || duration != 25*time.Minute {
+		t.Errorf("Expected work session 2 of 8 lasting 25m, got %s %d/%d %v", state, session, total, duration)
+	}
+	if label := cr.FormatSessionInfo(); label != "Work Session 3/8 (25:00)" {
+		t.Errorf("Expected %q, got %q", "Work Session 3/8 (25:00)", label)
+	}
+
+	cr.Pause()
+	if label := cr.FormatSessionInfo(); label != "Session Paused" {
+		t.Errorf("Expected paused label, got %q", label)
+	}
+}
+
+func TestPause(t *testing.T) {
+	cr := NewShortDurationClockRunner()
 	cr.SetDurations(1*time.Second, 500*time.Millisecond, 1*time.Second)
 
 	// Start the clock
@@ -86,7 +343,7 @@ func TestPause(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 
 	// Test pausing
-	err := cr.Pause()
+	_, err := cr.Pause()
 	if err != nil {
 		t.Errorf("Expected no error when pausing, got %v", err)
 	}
@@ -100,14 +357,14 @@ func TestPause(t *testing.T) {
 	}
 
 	// Test pausing when already paused
-	err = cr.Pause()
+	_, err = cr.Pause()
 	if err == nil {
 		t.Error("Expected error when pausing already paused clock")
 	}
 }
 
 func TestResumeFromPause(t *testing.T) {
-	cr := NewClockRunner()
+	cr := NewShortDurationClockRunner()
 	cr.SetDurations(1*time.Second, 500*time.Millisecond, 1*time.Second)
 
 	// Start and pause
@@ -119,7 +376,7 @@ func TestResumeFromPause(t *testing.T) {
 	remainingBefore := cr.GetTimeRemaining()
 
 	// Resume
-	err := cr.Start()
+	_, err := cr.Start()
 	if err != nil {
 		t.Errorf("Expected no error when resuming, got %v", err)
 	}
@@ -135,8 +392,132 @@ func TestResumeFromPause(t *testing.T) {
 	}
 }
 
+func TestAdjustTime(t *testing.T) {
+	cr := NewShortDurationClockRunner()
+	cr.SetDurations(time.Second, 500*time.Millisecond, time.Second)
+	defer cr.Stop()
+
+	if _, err := cr.AdjustTime(time.Second); !errors.Is(err, ErrNoActiveSession) {
+		t.Errorf("Expected ErrNoActiveSession while idle, got %v", err)
+	}
+
+	cr.Start()
+	result, err := cr.AdjustTime(5 * time.Second)
+	if err != nil {
+		t.Fatalf("Failed to add time: %v", err)
+	}
+	if result.State != StateWorking || result.TimeRemaining < 5*time.Second || result.EndTime.IsZero() {
+		t.Errorf("Expected working with over 5s remaining and an end time, got %+v", result)
+	}
+
+	// Paused sessions keep the adjusted time
+	cr.Pause()
+	result, err = cr.AdjustTime(-5 * time.Second)
+	if err != nil {
+		t.Fatalf("Failed to remove time while paused: %v", err)
+	}
+	if result.State != StatePaused || result.TimeRemaining > time.Second {
+		t.Errorf("Expected paused with at most 1s remaining, got %+v", result)
+	}
+	cr.Start()
+
+	// Removing the rest completes the work session and moves on
+	if _, err := cr.AdjustTime(-time.Hour); err != nil {
+		t.Fatalf("Failed to remove remaining time: %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for cr.GetCurrentSession() != 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if cr.GetCurrentSession() != 1 || cr.GetState() != StateShortBreak {
+		t.Errorf("Expected the short break after completing the work session, got %s in session %d", cr.GetState(), cr.GetCurrentSession())
+	}
+}
+
+func TestRepeatLastSession(t *testing.T) {
+	cr := NewShortDurationClockRunner()
+	cr.SetDurations(100*time.Millisecond, time.Second, time.Second)
+	defer cr.Stop()
+
+	if _, err := cr.RepeatLastSession(); !errors.Is(err, ErrNothingToRepeat) {
+		t.Errorf("Expected ErrNothingToRepeat before any session completed, got %v", err)
+	}
+
+	cr.Start()
+	waitForSession := func(session int) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for cr.GetCurrentSession() != session && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if cr.GetCurrentSession() != session {
+			t.Fatalf("Expected session %d, got %d", session, cr.GetCurrentSession())
+		}
+	}
+	waitForSession(1)
+
+	result, err := cr.RepeatLastSession()
+	if err != nil {
+		t.Fatalf("Failed to repeat the work session: %v", err)
+	}
+	if result.State != StateWorking || result.Session != 0 {
+		t.Errorf("Expected the work session 0 to run again, got %s in session %d", result.State, result.Session)
+	}
+	if _, err := cr.RepeatLastSession(); !errors.Is(err, ErrNothingToRepeat) {
+		t.Errorf("Expected a session to be repeatable only once, got %v", err)
+	}
+
+	// The repeat is recorded and the schedule continues with the short break
+	waitForSession(1)
+	if work, shortBreaks, _ := cr.GetStatistics(); work != 2 || shortBreaks != 0 {
+		t.Errorf("Expected 2 work sessions and no short breaks, got %d and %d", work, shortBreaks)
+	}
+	if cr.GetState() != StateShortBreak {
+		t.Errorf("Expected the short break after the repeat, got %s", cr.GetState())
+	}
+
+	// Past the window the session can no longer be repeated
+	if err := cr.SetRepeatWindow(time.Millisecond); err != nil {
+		t.Fatalf("Failed to set repeat window: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if _, err := cr.RepeatLastSession(); !errors.Is(err, ErrNothingToRepeat) {
+		t.Errorf("Expected ErrNothingToRepeat past the window, got %v", err)
+	}
+}
+
+func TestBack(t *testing.T) {
+	cr := NewShortDurationClockRunner()
+	cr.SetDurations(time.Second, time.Second, time.Second)
+	defer cr.Stop()
+
+	if _, err := cr.Back(); !errors.Is(err, ErrNoActiveSession) {
+		t.Errorf("Expected ErrNoActiveSession while idle, got %v", err)
+	}
+
+	cr.Start()
+	if _, err := cr.Back(); !errors.Is(err, ErrNoPreviousSession) {
+		t.Errorf("Expected ErrNoPreviousSession at the first session, got %v", err)
+	}
+
+	cr.Skip()
+	cr.Pause()
+	result, err := cr.Back()
+	if err != nil {
+		t.Fatalf("Failed to go back: %v", err)
+	}
+	if result.State != StateWorking || result.Session != 0 || result.TimeRemaining <= 900*time.Millisecond {
+		t.Errorf("Expected work session 0 to restart running, got %+v", result)
+	}
+
+	// Only the skip was recorded; going back neither records nor removes sessions
+	if work, _, _ := cr.GetStatistics(); work != 1 {
+		t.Errorf("Expected 1 recorded work session, got %d", work)
+	}
+}
+
 func TestStop(t *testing.T) {
-	cr := NewClockRunner()
+	cr := NewShortDurationClockRunner()
 	cr.SetDurations(1*time.Second, 500*time.Millisecond, 1*time.Second)
 
 	// Start the clock
@@ -144,7 +525,7 @@ func TestStop(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 
 	// Test stopping
-	err := cr.Stop()
+	_, err := cr.Stop()
 	if err != nil {
 		t.Errorf("Expected no error when stopping, got %v", err)
 	}
@@ -162,21 +543,54 @@ func TestStop(t *testing.T) {
 	}
 
 	// Test stopping when already idle
-	err = cr.Stop()
+	_, err = cr.Stop()
 	if err == nil {
 		t.Error("Expected error when stopping already idle clock")
 	}
 }
 
+func TestClockActionErrors(t *testing.T) {
+	cr := NewShortDurationClockRunner()
+	cr.SetDurations(time.Second, 500*time.Millisecond, time.Second)
+	defer cr.Stop()
+
+	if _, err := cr.Stop(); !errors.Is(err, ErrAlreadyIdle) {
+		t.Errorf("Expected ErrAlreadyIdle stopping an idle clock, got %v", err)
+	}
+	if _, err := cr.Pause(); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Expected ErrNotRunning pausing an idle clock, got %v", err)
+	}
+	if _, err := cr.Skip(); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Expected ErrNotRunning skipping an idle clock, got %v", err)
+	}
+
+	if _, err := cr.Start(); err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+	if _, err := cr.Start(); !errors.Is(err, ErrNotStartable) {
+		t.Errorf("Expected ErrNotStartable starting a running clock, got %v", err)
+	}
+	if _, err := cr.StartAt(1); !errors.Is(err, ErrNotStartable) {
+		t.Errorf("Expected ErrNotStartable starting a running clock at a session, got %v", err)
+	}
+
+	if _, err := cr.Pause(); err != nil {
+		t.Fatalf("Failed to pause: %v", err)
+	}
+	if _, err := cr.Pause(); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Expected ErrNotRunning pausing a paused clock, got %v", err)
+	}
+}
+
 func TestSkip(t *testing.T) {
-	cr := NewClockRunner()
+	cr := NewShortDurationClockRunner()
 	cr.SetDurations(1*time.Second, 500*time.Millisecond, 1*time.Second)
 
 	// Start the clock
 	cr.Start()
 
 	// Test skipping
-	err := cr.Skip()
+	_, err := cr.Skip()
 	if err != nil {
 		t.Errorf("Expected no error when skipping, got %v", err)
 	}
@@ -192,14 +606,14 @@ func TestSkip(t *testing.T) {
 
 	// Test skipping when idle
 	cr.Stop()
-	err = cr.Skip()
+	_, err = cr.Skip()
 	if err == nil {
 		t.Error("Expected error when skipping idle clock")
 	}
 }
 
 func TestGetTimeRemaining(t *testing.T) {
-	cr := NewClockRunner()
+	cr := NewShortDurationClockRunner()
 
 	// Set short durations for testing
 	cr.SetDurations(2*time.Second, 1*time.Second, 3*time.Second)
@@ -231,7 +645,7 @@ func TestGetTimeRemaining(t *testing.T) {
 }
 
 func TestGetFormattedTimeRemaining(t *testing.T) {
-	cr := NewClockRunner()
+	cr := NewShortDurationClockRunner()
 
 	// Test when idle
 	formatted := cr.GetFormattedTimeRemaining()
@@ -251,7 +665,7 @@ func TestGetFormattedTimeRemaining(t *testing.T) {
 }
 
 func TestCallbacks(t *testing.T) {
-	cr := NewClockRunner()
+	cr := NewShortDurationClockRunner()
 
 	var stateChanges []ClockState
 	var ticks []time.Duration
@@ -298,8 +712,475 @@ func TestCallbacks(t *testing.T) {
 	}
 }
 
+func TestTickInterval(t *testing.T) {
+	cr := NewShortDurationClockRunner()
+	cr.SetDurations(2*time.Second, time.Second, time.Second)
+
+	if cr.GetTickInterval() != 100*time.Millisecond {
+		t.Errorf("Expected default tick interval of 100ms, got %v", cr.GetTickInterval())
+	}
+	if err := cr.SetTickInterval(0); err == nil {
+		t.Error("Expected error for a zero tick interval")
+	}
+	if err := cr.SetTickInterval(2 * time.Second); err == nil {
+		t.Error("Expected error for a tick interval above 1s")
+	}
+
+	var ticks atomic.Int32
+	cr.SetCallbacks(nil, func(time.Duration) { ticks.Add(1) }, nil)
+
+	if err := cr.SetTickInterval(10 * time.Millisecond); err != nil {
+		t.Fatalf("Expected 10ms tick interval to be accepted, got %v", err)
+	}
+	cr.Start()
+	defer cr.Stop()
+
+	time.Sleep(200 * time.Millisecond)
+	if n := ticks.Load(); n < 10 {
+		t.Errorf("Expected at least 10 ticks at 10ms, got %d", n)
+	}
+
+	// A mid-session change leaves the running ticker alone
+	cr.SetTickInterval(time.Second)
+	ticks.Store(0)
+	time.Sleep(100 * time.Millisecond)
+	if n := ticks.Load(); n < 5 {
+		t.Errorf("Expected the running ticker to keep its 10ms interval, got %d ticks", n)
+	}
+
+	// ...and applies from the next resume
+	cr.Pause()
+	time.Sleep(20 * time.Millisecond) // let the old ticker loop exit
+	ticks.Store(0)
+	cr.Start()
+	time.Sleep(100 * time.Millisecond)
+	if n := ticks.Load(); n != 0 {
+		t.Errorf("Expected no ticks within 100ms after resuming at 1s, got %d", n)
+	}
+}
+
+func TestSubscribeReceivesStateAndTicks(t *testing.T) {
+	cr := NewShortDurationClockRunner()
+	cr.SetDurations(time.Second, 500*time.Millisecond, time.Second)
+	cr.SetTickInterval(10 * time.Millisecond)
+
+	first, unsubscribeFirst := cr.Subscribe(64)
+	second, unsubscribeSecond := cr.Subscribe(64)
+	defer unsubscribeSecond()
+
+	cr.Start()
+	defer cr.Stop()
+	time.Sleep(50 * time.Millisecond)
+
+	// Every subscriber sees the state change followed by ticks
+	for i, events := range []<-chan ClockEvent{first, second} {
+		event := <-events
+		if event.Type != EventStateChange || event.Snapshot.State != StateWorking {
+			t.Errorf("Subscriber %d: expected state change to W first, got %+v", i, event)
+		}
+		if event = <-events; event.Type != EventTick {
+			t.Errorf("Subscriber %d: expected a tick after the state change, got %+v", i, event)
+		}
+	}
+
+	// An unsubscribed channel is closed and stops receiving
+	unsubscribeFirst()
+	unsubscribeFirst()
+	for range first {
+	}
+
+	cr.Pause()
+	var sawPause bool
+	for !sawPause {
+		select {
+		case event := <-second:
+			sawPause = event.Type == EventStateChange && event.Snapshot.State == StatePaused
+		case <-time.After(time.Second):
+			t.Fatal("Expected remaining subscriber to see the pause")
+		}
+	}
+}
+
+func TestSubscribeCoalescesTicksForSlowReader(t *testing.T) {
+	cr := NewShortDurationClockRunner()
+	cr.SetDurations(2*time.Second, time.Second, time.Second)
+	cr.SetTickInterval(10 * time.Millisecond)
+
+	events, unsubscribe := cr.Subscribe(1)
+	defer unsubscribe()
+
+	// Fall behind by about 20 ticks without reading
+	cr.Start()
+	defer cr.Stop()
+	time.Sleep(200 * time.Millisecond)
+	cr.Pause()
+
+	var states []ClockState
+	ticks := 0
+	for len(states) < 2 {
+		select {
+		case event := <-events:
+			switch event.Type {
+			case EventTick:
+				ticks++
+			case EventStateChange:
+				states = append(states, event.Snapshot.State)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected the start and pause state changes, got %v", states)
+		}
+	}
+
+	if states[0] != StateWorking || states[1] != StatePaused {
+		t.Errorf("Expected state changes W then P, got %v", states)
+	}
+	// One tick may be in flight to the channel and one waiting; the rest coalesce
+	if ticks > 2 {
+		t.Errorf("Expected backed-up ticks to be coalesced, got %d", ticks)
+	}
+}
+
+func TestPauseWithReason(t *testing.T) {
+	cr := NewShortDurationClockRunner()
+	defer cr.Stop()
+
+	if _, err := cr.Start(); err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+	if _, err := cr.PauseWithReason(strings.Repeat("x", MaxPauseReasonLength+1)); !errors.Is(err, ErrPauseReasonTooLong) {
+		t.Fatalf("Expected ErrPauseReasonTooLong, got %v", err)
+	}
+	if cr.GetState() != StateWorking {
+		t.Fatalf("Expected a rejected pause to leave the session running, got %s", cr.GetState())
+	}
+
+	if _, err := cr.PauseWithReason("  lunch "); err != nil {
+		t.Fatalf("Failed to pause: %v", err)
+	}
+	if reason := cr.GetPauseReason(); reason != "lunch" {
+		t.Errorf("Expected pause reason %q, got %q", "lunch", reason)
+	}
+
+	// The reason is only reported while paused
+	cr.Start()
+	if reason := cr.GetPauseReason(); reason != "" {
+		t.Errorf("Expected no pause reason once resumed, got %q", reason)
+	}
+
+	// A plain pause records no reason
+	cr.Pause()
+	if reason := cr.GetPauseReason(); reason != "" {
+		t.Errorf("Expected no pause reason for a plain pause, got %q", reason)
+	}
+
+	snapshot := cr.GetStatisticsSnapshot()
+	if snapshot.Pauses != 2 {
+		t.Errorf("Expected 2 pauses, got %d", snapshot.Pauses)
+	}
+	if len(snapshot.PausesByReason) != 1 || snapshot.PausesByReason["lunch"] != 1 {
+		t.Errorf("Expected one pause for lunch, got %v", snapshot.PausesByReason)
+	}
+}
+
+func TestSubscribeReceivesCompletion(t *testing.T) {
+	cr := NewShortDurationClockRunner()
+	cr.SetDurations(100*time.Millisecond, time.Second, time.Second)
+
+	events, unsubscribe := cr.Subscribe(64)
+	defer unsubscribe()
+
+	cr.Start()
+	defer cr.Stop()
+
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.Type != EventComplete {
+				if event.Completion != nil {
+					t.Errorf("Expected no completion on a %s event", event.Type)
+				}
+				continue
+			}
+			completion := event.Completion
+			if completion == nil {
+				t.Fatal("Expected a completion event to carry the completion")
+			}
+			if completion.CompletedState != StateWorking || completion.NextState != StateShortBreak {
+				t.Errorf("Expected W followed by SB, got %s followed by %s", completion.CompletedState, completion.NextState)
+			}
+			if completion.SessionNumber != 0 || completion.CycleFinished {
+				t.Errorf("Expected session 0 mid-cycle, got session %d with cycleFinished %v", completion.SessionNumber, completion.CycleFinished)
+			}
+			if event.Snapshot.State != StateShortBreak || event.Snapshot.Session != 1 {
+				t.Errorf("Expected the snapshot to show the short break, got %s in session %d", event.Snapshot.State, event.Snapshot.Session)
+			}
+			return
+		case <-deadline:
+			t.Fatal("Expected a completion event")
+		}
+	}
+}
+
+func TestSubscribeReceivesGoalReached(t *testing.T) {
+	cr := NewShortDurationClockRunner()
+	cr.SetDurations(50*time.Millisecond, 50*time.Millisecond, time.Second)
+	if err := cr.SetDailyWorkGoal(1); err != nil {
+		t.Fatalf("Failed to set daily work goal: %v", err)
+	}
+
+	events, unsubscribe := cr.Subscribe(64)
+	defer unsubscribe()
+
+	cr.Start()
+	defer cr.Stop()
+
+	// Two work sessions complete, but the goal is announced only once
+	goals := 0
+	deadline := time.After(time.Second)
+	for cr.GetDailyGoalProgress().Completed < 2 {
+		select {
+		case event := <-events:
+			if event.Type != EventGoalReached {
+				continue
+			}
+			goals++
+			if event.Goal == nil || !event.Goal.Reached || event.Goal.Goal != 1 {
+				t.Errorf("Expected the goal event to carry the reached progress, got %+v", event.Goal)
+			}
+		case <-deadline:
+			t.Fatal("Expected two work sessions to complete")
+		}
+	}
+	cr.Stop()
+	for len(events) > 0 {
+		if event := <-events; event.Type == EventGoalReached {
+			goals++
+		}
+	}
+
+	if goals != 1 {
+		t.Errorf("Expected 1 goal event, got %d", goals)
+	}
+	if progress := cr.GetDailyGoalProgress(); progress.Percent != 100 {
+		t.Errorf("Expected 100%% progress, got %v", progress.Percent)
+	}
+}
+
+func TestSkipReachingGoalAnnouncesIt(t *testing.T) {
+	cr := NewShortDurationClockRunner()
+	cr.SetDurations(time.Second, time.Second, time.Second)
+	if err := cr.SetDailyWorkGoal(1); err != nil {
+		t.Fatalf("Failed to set daily work goal: %v", err)
+	}
+
+	events, unsubscribe := cr.Subscribe(64)
+	defer unsubscribe()
+
+	cr.Start()
+	defer cr.Stop()
+
+	// Skipping the work session counts toward the goal, so it is announced then
+	if _, err := cr.Skip(); err != nil {
+		t.Fatalf("Failed to skip: %v", err)
+	}
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.Type != EventGoalReached {
+				continue
+			}
+			if event.Goal == nil || !event.Goal.Reached || event.Goal.Completed != 1 {
+				t.Errorf("Expected the goal event to carry the reached progress, got %+v", event.Goal)
+			}
+			return
+		case <-deadline:
+			t.Fatal("Expected the skip to announce the reached goal")
+		}
+	}
+}
+
+func TestPauseWhenUnwatched(t *testing.T) {
+	cr := NewShortDurationClockRunner()
+	cr.SetDurations(2*time.Second, time.Second, time.Second)
+	cr.Start()
+	defer cr.Stop()
+
+	// Off by default: the last watcher leaving changes nothing
+	release := cr.Watch()
+	release()
+	time.Sleep(50 * time.Millisecond)
+	if cr.GetState() != StateWorking {
+		t.Fatalf("Expected the clock to keep running with the setting off, got %s", cr.GetState())
+	}
+
+	behavior := cr.GetBehavior()
+	behavior.PauseWhenUnwatched = true
+	behavior.UnwatchedGrace = 20 * time.Millisecond
+	if _, err := cr.SetBehavior(behavior); err != nil {
+		t.Fatalf("Failed to enable pausing when unwatched: %v", err)
+	}
+
+	// A watcher coming back within the grace keeps the session running
+	first := cr.Watch()
+	second := cr.Watch()
+	if n := cr.GetWatcherCount(); n != 2 {
+		t.Errorf("Expected 2 watchers, got %d", n)
+	}
+	first()
+	first()
+	second()
+	second = cr.Watch()
+	time.Sleep(50 * time.Millisecond)
+	if cr.GetState() != StateWorking {
+		t.Fatalf("Expected a reconnect within the grace to keep the session running, got %s", cr.GetState())
+	}
+
+	// Once nobody watches past the grace, the session pauses...
+	second()
+	if n := cr.GetWatcherCount(); n != 0 {
+		t.Errorf("Expected no watchers, got %d", n)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if cr.GetState() != StatePaused || cr.GetPauseReason() != UnwatchedPauseReason {
+		t.Fatalf("Expected the session paused as unwatched, got %s (%q)", cr.GetState(), cr.GetPauseReason())
+	}
+
+	// ...and resumes when a watcher reconnects
+	release = cr.Watch()
+	if cr.GetState() != StateWorking {
+		t.Errorf("Expected a reconnect to resume the session, got %s", cr.GetState())
+	}
+
+	// A session paused for another reason stays paused
+	cr.PauseWithReason("lunch")
+	release()
+	cr.Watch()
+	if cr.GetState() != StatePaused {
+		t.Errorf("Expected a session paused for lunch to stay paused, got %s", cr.GetState())
+	}
+
+	behavior.UnwatchedGrace = 2 * MaxUnwatchedGrace
+	if _, err := cr.SetBehavior(behavior); !errors.Is(err, ErrInvalidBehavior) {
+		t.Errorf("Expected ErrInvalidBehavior for a grace over the cap, got %v", err)
+	}
+}
+
+func TestAutoStartNext(t *testing.T) {
+	waitForSession := func(t *testing.T, cr *ClockRunner, session int) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for cr.GetCurrentSession() != session && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if cr.GetCurrentSession() != session {
+			t.Fatalf("Expected session %d, got %d", session, cr.GetCurrentSession())
+		}
+	}
+
+	t.Run("enabled by default", func(t *testing.T) {
+		cr := NewShortDurationClockRunner()
+		cr.SetDurations(100*time.Millisecond, time.Second, time.Second)
+		defer cr.Stop()
+
+		if !cr.GetAutoStartNext() {
+			t.Fatal("Expected auto-start to be enabled by default")
+		}
+		cr.Start()
+		waitForSession(t, cr, 1)
+		if cr.GetState() != StateShortBreak || !cr.IsRunning() {
+			t.Errorf("Expected the short break to start on its own, got %s", cr.GetState())
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		cr := NewShortDurationClockRunner()
+		cr.SetDurations(100*time.Millisecond, 200*time.Millisecond, time.Second)
+		cr.SetAutoStartNext(false)
+		defer cr.Stop()
+
+		cr.Start()
+		waitForSession(t, cr, 1)
+		time.Sleep(250 * time.Millisecond)
+		if cr.GetState() != StateWaiting {
+			t.Fatalf("Expected the clock to wait for the short break, got %s", cr.GetState())
+		}
+		if cr.IsPaused() || cr.IsRunning() {
+			t.Error("Expected a waiting clock to be neither paused nor running")
+		}
+		if work, _, _ := cr.GetStatistics(); work != 1 {
+			t.Errorf("Expected the work session to be recorded, got %d", work)
+		}
+		if remaining := cr.GetTimeRemaining(); remaining < 190*time.Millisecond {
+			t.Errorf("Expected the short break to keep its full duration while waiting, got %v", remaining)
+		}
+
+		result, err := cr.Start()
+		if err != nil {
+			t.Fatalf("Failed to start the waiting session: %v", err)
+		}
+		if result.State != StateShortBreak || result.Session != 1 {
+			t.Errorf("Expected the short break in session 1 to start, got %s in session %d", result.State, result.Session)
+		}
+		if remaining := cr.GetTimeRemaining(); remaining < 150*time.Millisecond {
+			t.Errorf("Expected the time spent waiting not to count against the session, got %v left", remaining)
+		}
+	})
+}
+
+func TestIdleTimeoutStopsPausedSession(t *testing.T) {
+	cr := NewShortDurationClockRunner()
+	cr.SetDurations(time.Second, 500*time.Millisecond, time.Second)
+
+	if err := cr.SetIdleTimeout(-time.Second); err == nil {
+		t.Error("Expected error for a negative idle timeout")
+	}
+	if err := cr.SetIdleTimeout(50 * time.Millisecond); err != nil {
+		t.Fatalf("Expected 50ms idle timeout to be accepted, got %v", err)
+	}
+
+	var mu sync.Mutex
+	var stateChanges []ClockState
+	cr.SetCallbacks(func(state ClockState) {
+		mu.Lock()
+		defer mu.Unlock()
+		stateChanges = append(stateChanges, state)
+	}, nil, nil)
+
+	// Resuming before the timeout disarms it
+	cr.Start()
+	defer cr.Stop()
+	cr.Pause()
+	time.Sleep(20 * time.Millisecond)
+	cr.Start()
+	time.Sleep(80 * time.Millisecond)
+	if cr.GetState() != StateWorking {
+		t.Fatalf("Expected resumed session to keep running, got %s", cr.GetState())
+	}
+
+	// Staying paused past the timeout returns the clock to idle
+	cr.Pause()
+	time.Sleep(100 * time.Millisecond)
+	if cr.GetState() != StateIdle {
+		t.Errorf("Expected clock to be idle after the idle timeout, got %s", cr.GetState())
+	}
+
+	mu.Lock()
+	last := stateChanges[len(stateChanges)-1]
+	mu.Unlock()
+	if last != StateIdle {
+		t.Errorf("Expected the last state change to be idle, got %s", last)
+	}
+
+	// Nothing is recorded for the abandoned session
+	if work, short, long := cr.GetStatistics(); work+short+long != 0 {
+		t.Errorf("Expected no recorded sessions, got %d/%d/%d", work, short, long)
+	}
+}
+
 func TestClockRunnerStatistics(t *testing.T) {
-	cr := NewClockRunner()
+	cr := NewShortDurationClockRunner()
 
 	// Set short durations
 	cr.SetDurations(50*time.Millisecond, 25*time.Millisecond, 75*time.Millisecond)
@@ -322,7 +1203,7 @@ func TestClockRunnerStatistics(t *testing.T) {
 }
 
 func TestClockRunnerConcurrentAccess(t *testing.T) {
-	cr := NewClockRunner()
+	cr := NewShortDurationClockRunner()
 	cr.SetDurations(1*time.Second, 500*time.Millisecond, 1*time.Second)
 
 	var wg sync.WaitGroup
@@ -363,7 +1244,7 @@ func TestClockRunnerConcurrentAccess(t *testing.T) {
 }
 
 func TestSessionProgression(t *testing.T) {
-	cr := NewClockRunner()
+	cr := NewShortDurationClockRunner()
 	cr.SetDurations(200*time.Millisecond, 100*time.Millisecond, 150*time.Millisecond)
 
 	// Start the clock
@@ -392,7 +1273,7 @@ func TestSessionProgression(t *testing.T) {
 }
 
 func TestClockRunnerCompleteCycle(t *testing.T) {
-	cr := NewClockRunner()
+	cr := NewShortDurationClockRunner()
 	cr.SetDurations(20*time.Millisecond, 10*time.Millisecond, 15*time.Millisecond)
 
 	// Start the clock
@@ -412,7 +1293,7 @@ func TestClockRunnerCompleteCycle(t *testing.T) {
 }
 
 func TestContextCancellation(t *testing.T) {
-	cr := NewClockRunner()
+	cr := NewShortDurationClockRunner()
 	cr.SetDurations(1*time.Second, 500*time.Millisecond, 1*time.Second)
 
 	// Start the clock
@@ -428,8 +1309,33 @@ func TestContextCancellation(t *testing.T) {
 	}
 }
 
+func TestCloseTwice(t *testing.T) {
+	cr := NewShortDurationClockRunner()
+	cr.Start()
+
+	if err := cr.Close(); err != nil {
+		t.Fatalf("First Close failed: %v", err)
+	}
+	if err := cr.Close(); err != nil {
+		t.Errorf("Second Close failed: %v", err)
+	}
+
+	// Concurrent closes must not double-close the save loop either
+	cr = NewShortDurationClockRunner()
+	cr.Start()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cr.Close()
+		}()
+	}
+	wg.Wait()
+}
+
 func BenchmarkClockRunner(b *testing.B) {
-	cr := NewClockRunner()
+	cr := NewShortDurationClockRunner()
 	cr.SetDurations(1*time.Second, 500*time.Millisecond, 1*time.Second)
 
 	b.ResetTimer()
@@ -443,7 +1349,7 @@ func BenchmarkClockRunner(b *testing.B) {
 }
 
 func BenchmarkGetTimeRemaining(b *testing.B) {
-	cr := NewClockRunner()
+	cr := NewShortDurationClockRunner()
 	cr.SetDurations(1*time.Second, 500*time.Millisecond, 1*time.Second)
 	cr.Start()
 
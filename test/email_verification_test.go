@@ -0,0 +1,61 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pomodoroService/internal/auth"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestEmailVerificationRoundTrip(t *testing.T) {
+	// Skip if Redis is not available
+	client := redis.NewClient(&redis.Options{
+		Addr:     redisAddr,
+		Password: "",
+		DB:       0,
+	})
+
+	ctx := context.Background()
+	_, err := client.Ping(ctx).Result()
+	if err != nil {
+		t.Skip("Redis not available, skipping test")
+	}
+	defer client.Close()
+
+	store, err := auth.NewVerificationStore(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create verification store: %v", err)
+	}
+	defer store.Close()
+
+	repo := newFakeAuthRepo()
+	user := repo.addUser("1", "newcomer", "USER")
+
+	token, err := store.CreateToken("newcomer")
+	if err != nil {
+		t.Fatalf("Failed to create token: %v", err)
+	}
+
+	username, err := store.ConsumeToken(token)
+	if err != nil {
+		t.Fatalf("Failed to consume token: %v", err)
+	}
+	if username != "newcomer" {
+		t.Errorf("Expected token to belong to newcomer, got %s", username)
+	}
+
+	if err := repo.VerifyEmail(username); err != nil {
+		t.Fatalf("Failed to verify email: %v", err)
+	}
+	if user.EmailVerified == nil || !*user.EmailVerified {
+		t.Error("Expected user to be verified after the round trip")
+	}
+
+	// Tokens are single use
+	if _, err := store.ConsumeToken(token); !errors.Is(err, auth.ErrInvalidVerificationToken) {
+		t.Errorf("Expected ErrInvalidVerificationToken on reuse, got %v", err)
+	}
+}
@@ -12,9 +12,15 @@ type TestClockRunner struct {
 	t *testing.T
 }
 
+// NewShortDurationClockRunner creates a clock runner that accepts the sub-second
+// durations used throughout the tests instead of the production one-minute minimum
+func NewShortDurationClockRunner() *clock.ClockRunner {
+	return clock.NewClockRunner(clock.WithMinDuration(time.Millisecond))
+}
+
 // NewTestClockRunner creates a new test clock runner with longer durations for reliability
 func NewTestClockRunner(t *testing.T) *TestClockRunner {
-	cr := clock.NewClockRunner()
+	cr := NewShortDurationClockRunner()
 	cr.SetDurations(200*time.Millisecond, 100*time.Millisecond, 150*time.Millisecond)
 
 	return &TestClockRunner{
@@ -95,7 +101,7 @@ func (tcr *TestClockRunner) RunCompleteCycle() (int, int, int) {
 	initialWork, initialShort, initialLong := tcr.GetStatistics()
 
 	// Start the clock
-	err := tcr.Start()
+	_, err := tcr.Start()
 	if err != nil {
 		tcr.t.Fatalf("Failed to start clock: %v", err)
 	}
@@ -0,0 +1,79 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pomodoroService/internal/httpjson"
+)
+
+type decodeTarget struct {
+	Username string `json:"username"`
+	Age      int    `json:"age"`
+}
+
+func decodeBody(body string, maxBytes int64) (decodeTarget, error) {
+	var dst decodeTarget
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	err := httpjson.Decode(httptest.NewRecorder(), r, &dst, maxBytes)
+	return dst, err
+}
+
+func TestDecodeJSONAcceptsValidBody(t *testing.T) {
+	dst, err := decodeBody(`{"username": "alice", "age": 30}`+"\n", httpjson.DefaultMaxBodyBytes)
+	if err != nil {
+		t.Fatalf("Expected a valid body to decode, got %v", err)
+	}
+	if dst.Username != "alice" || dst.Age != 30 {
+		t.Errorf("Expected alice/30, got %+v", dst)
+	}
+}
+
+func TestDecodeJSONRejectsOversizedBody(t *testing.T) {
+	body := `{"username": "` + strings.Repeat("a", 200) + `"}`
+	_, err := decodeBody(body, 64)
+	if !errors.Is(err, httpjson.ErrBodyTooLarge) {
+		t.Fatalf("Expected ErrBodyTooLarge, got %v", err)
+	}
+	if status := httpjson.StatusCode(err); status != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d", status)
+	}
+
+	// A value that fits followed by padding past the limit is too large as well
+	_, err = decodeBody(`{"username": "alice"}`+strings.Repeat(" ", 100), 64)
+	if !errors.Is(err, httpjson.ErrBodyTooLarge) {
+		t.Errorf("Expected ErrBodyTooLarge for trailing padding, got %v", err)
+	}
+}
+
+func TestDecodeJSONRejectsInvalidBodies(t *testing.T) {
+	cases := map[string]struct {
+		body    string
+		message string
+	}{
+		"extra field":    {`{"username": "alice", "role": "ADMIN"}`, `unknown field "role"`},
+		"trailing value": {`{"username": "alice"} {"username": "bob"}`, "single JSON value"},
+		"empty":          {``, "must not be empty"},
+		"truncated":      {`{"username": "al`, "malformed JSON"},
+		"syntax":         {`{"username" "alice"}`, "malformed JSON at position"},
+		"wrong type":     {`{"age": "thirty"}`, `wrong type for field "age"`},
+		"not an object":  {`["alice"]`, "unexpected JSON array"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := decodeBody(tc.body, httpjson.DefaultMaxBodyBytes)
+			if !errors.Is(err, httpjson.ErrInvalidBody) {
+				t.Fatalf("Expected ErrInvalidBody, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tc.message) {
+				t.Errorf("Expected the error to mention %q, got %q", tc.message, err.Error())
+			}
+			if status := httpjson.StatusCode(err); status != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", status)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pomodoroService/internal/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writeRSAKeys writes a fresh RSA key pair as PEM files and returns their paths
+func writeRSAKeys(t *testing.T) (privatePath, publicPath string, publicPEM []byte) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("Failed to marshal public key: %v", err)
+	}
+
+	dir := t.TempDir()
+	privatePath = filepath.Join(dir, "jwt.key")
+	publicPath = filepath.Join(dir, "jwt.pub")
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	publicPEM = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	if err := os.WriteFile(privatePath, privatePEM, 0o600); err != nil {
+		t.Fatalf("Failed to write private key: %v", err)
+	}
+	if err := os.WriteFile(publicPath, publicPEM, 0o644); err != nil {
+		t.Fatalf("Failed to write public key: %v", err)
+	}
+	return privatePath, publicPath, publicPEM
+}
+
+// useJWTEnv switches the JWT keys for the test and restores HS256 afterwards
+func useJWTEnv(t *testing.T, algorithm, privatePath, publicPath string) error {
+	t.Helper()
+	// Registered before t.Setenv so it runs after the environment is restored
+	t.Cleanup(func() {
+		if err := auth.LoadJWTKeys(); err != nil {
+			t.Errorf("Failed to restore JWT keys: %v", err)
+		}
+	})
+	t.Setenv("JWT_ALGORITHM", algorithm)
+	t.Setenv("JWT_PRIVATE_KEY_PATH", privatePath)
+	t.Setenv("JWT_PUBLIC_KEY_PATH", publicPath)
+	return auth.LoadJWTKeys()
+}
+
+func signedTestToken(t *testing.T, method jwt.SigningMethod, key any) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, &auth.JWTClaims{
+		Username: "alice",
+		UserID:   "1",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			Issuer:    "pomodoro-service",
+		},
+	})
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func TestRS256RoundTrip(t *testing.T) {
+	privatePath, publicPath, publicPEM := writeRSAKeys(t)
+	if err := useJWTEnv(t, "RS256", privatePath, publicPath); err != nil {
+		t.Fatalf("Failed to load RS256 keys: %v", err)
+	}
+
+	user := newFakeAuthRepo().addUser("1", "alice", "USER")
+	token, err := auth.GenerateJWT(user)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	claims, err := auth.ValidateJWT(token)
+	if err != nil {
+		t.Fatalf("Expected an RS256 token to validate, got %v", err)
+	}
+	if claims.UserID != "1" {
+		t.Errorf("Expected user ID 1, got %q", claims.UserID)
+	}
+
+	// Algorithm confusion: an HS256 token keyed with the public key PEM
+	if _, err := auth.ValidateJWT(signedTestToken(t, jwt.SigningMethodHS256, publicPEM)); err == nil {
+		t.Error("Expected an HS256 token signed with the public key to be rejected")
+	}
+	// A token signed with the shared secret is no longer accepted either
+	if _, err := auth.ValidateJWT(signedTestToken(t, jwt.SigningMethodHS256, []byte(os.Getenv("JWT_SECRET")))); err == nil {
+		t.Error("Expected an HS256 token to be rejected while RS256 is configured")
+	}
+}
+
+func TestRS256PublicKeyOnly(t *testing.T) {
+	privatePath, publicPath, _ := writeRSAKeys(t)
+
+	// Another instance holding the private key issues the token
+	if err := useJWTEnv(t, "RS256", privatePath, ""); err != nil {
+		t.Fatalf("Failed to load RS256 private key: %v", err)
+	}
+	token, err := auth.GenerateJWT(newFakeAuthRepo().addUser("1", "alice", "USER"))
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	if err := useJWTEnv(t, "RS256", "", publicPath); err != nil {
+		t.Fatalf("Failed to load RS256 public key: %v", err)
+	}
+	if _, err := auth.ValidateJWT(token); err != nil {
+		t.Errorf("Expected the token to validate with the public key, got %v", err)
+	}
+	if _, err := auth.GenerateJWT(newFakeAuthRepo().addUser("1", "alice", "USER")); !errors.Is(err, auth.ErrNoSigningKey) {
+		t.Errorf("Expected ErrNoSigningKey without a private key, got %v", err)
+	}
+}
+
+func TestLoadJWTKeysRejectsBadConfig(t *testing.T) {
+	privatePath, _, _ := writeRSAKeys(t)
+	_, otherPublicPath, _ := writeRSAKeys(t)
+
+	tests := []struct {
+		name                    string
+		algorithm, private, pub string
+	}{
+		{"unknown algorithm", "none", "", ""},
+		{"RS256 without keys", "RS256", "", ""},
+		{"missing key file", "RS256", filepath.Join(t.TempDir(), "missing.key"), ""},
+		{"mismatched key pair", "RS256", privatePath, otherPublicPath},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := useJWTEnv(t, tt.algorithm, tt.private, tt.pub); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestPreviousJWTSecretValidatesDuringRotation(t *testing.T) {
+	t.Cleanup(func() {
+		if err := auth.LoadJWTKeys(); err != nil {
+			t.Errorf("Failed to restore JWT keys: %v", err)
+		}
+	})
+	previousSecret := []byte("previous-test-secret")
+	oldToken := signedTestToken(t, jwt.SigningMethodHS256, previousSecret)
+
+	if _, err := auth.ValidateJWT(oldToken); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+		t.Fatalf("Expected the old token to be rejected before rotation, got %v", err)
+	}
+
+	t.Setenv("JWT_SECRET_PREVIOUS", string(previousSecret))
+	if err := auth.LoadJWTKeys(); err != nil {
+		t.Fatalf("Failed to load JWT keys: %v", err)
+	}
+
+	claims, err := auth.ValidateJWT(oldToken)
+	if err != nil {
+		t.Fatalf("Expected a token signed with the previous secret to validate, got %v", err)
+	}
+	if claims.Username != "alice" {
+		t.Errorf("Expected claims for alice, got %s", claims.Username)
+	}
+
+	// New tokens are signed with the current secret only
+	user := newFakeAuthRepo().addUser("1", "alice", "USER")
+	newToken, err := auth.GenerateJWT(user)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	if _, err := jwt.Parse(newToken, func(*jwt.Token) (any, error) { return previousSecret, nil }); err == nil {
+		t.Error("Expected new tokens not to be signed with the previous secret")
+	}
+	if _, err := auth.ValidateJWT(newToken); err != nil {
+		t.Errorf("Expected a new token to validate, got %v", err)
+	}
+
+	// Ending the rotation window rejects the old token again
+	t.Setenv("JWT_SECRET_PREVIOUS", "")
+	if err := auth.LoadJWTKeys(); err != nil {
+		t.Fatalf("Failed to load JWT keys: %v", err)
+	}
+	if _, err := auth.ValidateJWT(oldToken); err == nil {
+		t.Error("Expected the old token to be rejected once the previous secret is removed")
+	}
+}
@@ -0,0 +1,44 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"pomodoroService/internal/auth"
+)
+
+func TestLoginLockout(t *testing.T) {
+	lockout := auth.LoginLockout{MaxAttempts: 3, Duration: 10 * time.Minute}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var lockedUntil *time.Time
+	for attempt := 1; attempt <= lockout.MaxAttempts; attempt++ {
+		if lockout.IsLocked(lockedUntil, now) {
+			t.Fatalf("Account locked before failure %d", attempt)
+		}
+		lockedUntil = lockout.LockUntil(attempt, now)
+		if attempt < lockout.MaxAttempts && lockedUntil != nil {
+			t.Fatalf("Expected no lock after %d failures, got one until %v", attempt, *lockedUntil)
+		}
+	}
+
+	if lockedUntil == nil {
+		t.Fatalf("Expected a lock after %d failures", lockout.MaxAttempts)
+	}
+	if !lockedUntil.Equal(now.Add(lockout.Duration)) {
+		t.Errorf("Expected lock until %v, got %v", now.Add(lockout.Duration), *lockedUntil)
+	}
+	if !lockout.IsLocked(lockedUntil, now.Add(lockout.Duration-time.Second)) {
+		t.Error("Expected the account to stay locked within the window")
+	}
+	if lockout.IsLocked(lockedUntil, now.Add(lockout.Duration)) {
+		t.Error("Expected the account to unlock once the window has passed")
+	}
+}
+
+func TestLoginLockoutDisabled(t *testing.T) {
+	lockout := auth.LoginLockout{Duration: time.Minute}
+	if until := lockout.LockUntil(100, time.Now()); until != nil {
+		t.Errorf("Expected no lock with MaxAttempts 0, got one until %v", *until)
+	}
+}
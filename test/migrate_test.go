@@ -0,0 +1,34 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"pomodoroService/internal/migrate"
+)
+
+func TestMigrationsOrdered(t *testing.T) {
+	migrations, err := migrate.Migrations()
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("Expected at least one embedded migration")
+	}
+
+	for i, m := range migrations {
+		if i > 0 && m.Version <= migrations[i-1].Version {
+			t.Errorf("Migration %d (%s) is out of order after %d", m.Version, m.Name, migrations[i-1].Version)
+		}
+		if strings.TrimSpace(m.SQL) == "" {
+			t.Errorf("Migration %d (%s) is empty", m.Version, m.Name)
+		}
+	}
+
+	if !strings.Contains(migrations[0].SQL, "CREATE TABLE IF NOT EXISTS users") {
+		t.Error("Expected the first migration to create the users table")
+	}
+	if len(migrations) < 2 || !strings.Contains(migrations[1].SQL, "CREATE TABLE IF NOT EXISTS user_settings") {
+		t.Error("Expected the second migration to create the user_settings table")
+	}
+}
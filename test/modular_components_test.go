@@ -1,7 +1,10 @@
 package test
 
 import (
+	"errors"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -37,6 +40,20 @@ func TestModularComponents(t *testing.T) {
 		if !sm.CanPause() {
 			t.Error("Expected state manager to allow pausing when running")
 		}
+
+		if err := sm.ValidateTransition(StateWaiting); err != nil {
+			t.Errorf("Expected a running session to be able to wait for the next: %v", err)
+		}
+		sm.SetState(StateWaiting)
+		if !sm.IsWaiting() || sm.IsRunning() || sm.CanPause() {
+			t.Error("Expected a waiting state manager to be neither running nor pausable")
+		}
+		if !sm.CanStart() {
+			t.Error("Expected state manager to allow starting from waiting")
+		}
+		if err := sm.ValidateTransition(StateShortBreak); err != nil {
+			t.Errorf("Expected waiting to be able to begin the next session: %v", err)
+		}
 	})
 
 	// Test SessionManager
@@ -85,6 +102,7 @@ func TestModularComponents(t *testing.T) {
 		// Test timer start
 		var tickCount int
 		var completedState ClockState
+		done := make(chan struct{})
 
 		tm.StartTimer(100*time.Millisecond, StateWorking,
 			func(remaining time.Duration) {
@@ -92,6 +110,7 @@ func TestModularComponents(t *testing.T) {
 			},
 			func(state ClockState) {
 				completedState = state
+				close(done)
 			})
 
 		if !tm.IsRunning() {
@@ -99,7 +118,11 @@ func TestModularComponents(t *testing.T) {
 		}
 
 		// Wait for completion
-		time.Sleep(150 * time.Millisecond)
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Expected the timer to complete")
+		}
 
 		if completedState != StateWorking {
 			t.Errorf("Expected completed state to be StateWorking, got %s", completedState)
@@ -192,7 +215,7 @@ func TestModularComponents(t *testing.T) {
 }
 
 func TestModularClockRunner(t *testing.T) {
-	cr := NewClockRunner()
+	cr := NewShortDurationClockRunner()
 
 	// Test initial state
 	if cr.GetState() != StateIdle {
@@ -224,7 +247,7 @@ func TestModularClockRunner(t *testing.T) {
 	)
 
 	// Test starting
-	err := cr.Start()
+	_, err := cr.Start()
 	if err != nil {
 		t.Errorf("Expected no error when starting, got %v", err)
 	}
@@ -245,7 +268,7 @@ func TestModularClockRunner(t *testing.T) {
 	}
 
 	// Test pausing
-	err = cr.Pause()
+	_, err = cr.Pause()
 	if err != nil {
 		t.Errorf("Expected no error when pausing, got %v", err)
 	}
@@ -259,7 +282,7 @@ func TestModularClockRunner(t *testing.T) {
 	}
 
 	// Test resuming
-	err = cr.Start()
+	_, err = cr.Start()
 	if err != nil {
 		t.Errorf("Expected no error when resuming, got %v", err)
 	}
@@ -269,7 +292,7 @@ func TestModularClockRunner(t *testing.T) {
 	}
 
 	// Test stopping
-	err = cr.Stop()
+	_, err = cr.Stop()
 	if err != nil {
 		t.Errorf("Expected no error when stopping, got %v", err)
 	}
@@ -289,7 +312,7 @@ func TestModularClockRunner(t *testing.T) {
 }
 
 func TestModularClockRunnerConcurrency(t *testing.T) {
-	cr := NewClockRunner()
+	cr := NewShortDurationClockRunner()
 	cr.SetDurations(100*time.Millisecond, 50*time.Millisecond, 75*time.Millisecond)
 
 	var wg sync.WaitGroup
@@ -329,3 +352,661 @@ func TestModularClockRunnerConcurrency(t *testing.T) {
 
 	cr.Stop()
 }
+
+func TestSessionSpecSchedule(t *testing.T) {
+	specs, err := ParseScheduling("W:50-SB:10-W-LB:20")
+	if err != nil {
+		t.Fatalf("Expected valid scheduling string, got error: %v", err)
+	}
+
+	if len(specs) != 4 {
+		t.Fatalf("Expected 4 sessions, got %d", len(specs))
+	}
+
+	if specs[0].State != StateWorking || specs[0].Duration != 50*time.Minute {
+		t.Errorf("Expected first session to be W:50m, got %s:%v", specs[0].State, specs[0].Duration)
+	}
+
+	if specs[2].Duration != 0 {
+		t.Errorf("Expected bare token to have no custom duration, got %v", specs[2].Duration)
+	}
+
+	if formatted := FormatSchedulingSpecs(specs); formatted != "W:50-SB:10-W-LB:20" {
+		t.Errorf("Expected round-trip formatting, got %s", formatted)
+	}
+
+	for _, invalid := range []string{"W:0", "W:abc", "X:10", "W:-5"} {
+		if _, err := ParseScheduling(invalid); err == nil {
+			t.Errorf("Expected error for scheduling string %q", invalid)
+		}
+	}
+
+	sm := NewSessionManager()
+	sm.SetDurations(25*time.Minute, 5*time.Minute, 15*time.Minute)
+	if err := sm.SetScheduleSpecs(specs); err != nil {
+		t.Fatalf("Expected schedule specs to be accepted, got error: %v", err)
+	}
+
+	expected := []time.Duration{50 * time.Minute, 10 * time.Minute, 25 * time.Minute, 20 * time.Minute}
+	for i, want := range expected {
+		if got := sm.GetCurrentSessionDuration(); got != want {
+			t.Errorf("Expected session %d duration to be %v, got %v", i, want, got)
+		}
+		if _, _, _, duration := sm.GetSessionInfoAt(i); duration != want {
+			t.Errorf("Expected GetSessionInfoAt(%d) duration to be %v, got %v", i, want, duration)
+		}
+		sm.NextSession()
+	}
+}
+
+func TestEscalatingLongBreaks(t *testing.T) {
+	specs, err := ParseScheduling("W-SB-W-LB:10-W-SB-W-LB:20")
+	if err != nil {
+		t.Fatalf("Expected per-break durations to parse, got error: %v", err)
+	}
+
+	sm := NewSessionManager()
+	sm.SetDurations(25*time.Minute, 5*time.Minute, 15*time.Minute)
+	if err := sm.SetScheduleSpecs(specs); err != nil {
+		t.Fatalf("Expected schedule specs to be accepted, got error: %v", err)
+	}
+
+	expected := []struct {
+		state    ClockState
+		duration time.Duration
+	}{
+		{StateWorking, 25 * time.Minute},
+		{StateShortBreak, 5 * time.Minute},
+		{StateWorking, 25 * time.Minute},
+		{StateLongBreak, 10 * time.Minute},
+		{StateWorking, 25 * time.Minute},
+		{StateShortBreak, 5 * time.Minute},
+		{StateWorking, 25 * time.Minute},
+		{StateLongBreak, 20 * time.Minute},
+	}
+	for i, want := range expected {
+		if state := sm.GetCurrentSessionState(); state != want.state {
+			t.Errorf("Expected session %d to be %s, got %s", i, want.state, state)
+		}
+		if got := sm.GetCurrentSessionDuration(); got != want.duration {
+			t.Errorf("Expected session %d duration to be %v, got %v", i, want.duration, got)
+		}
+		sm.NextSession()
+	}
+
+	if total, _ := sm.GetCycleDurations(); total != 140*time.Minute {
+		t.Errorf("Expected the cycle to total 140m, got %v", total)
+	}
+	if formatted := FormatSchedulingSpecs(sm.GetScheduleSpecs()); formatted != "W-SB-W-LB:10-W-SB-W-LB:20" {
+		t.Errorf("Expected round-trip formatting, got %s", formatted)
+	}
+
+	// Each override is held to the same limits as the default durations
+	for _, invalid := range []string{"W-LB:10-W-LB:300", "W-LB:241"} {
+		specs, err := ParseScheduling(invalid)
+		if err != nil {
+			t.Fatalf("Expected %q to parse, got error: %v", invalid, err)
+		}
+		if err := sm.SetScheduleSpecs(specs); err == nil {
+			t.Errorf("Expected schedule %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestCustomBreakSchedule(t *testing.T) {
+	specs, err := ParseScheduling("W-CB-W-CB:45-LB")
+	if err != nil {
+		t.Fatalf("Expected CB tokens to be accepted, got error: %v", err)
+	}
+
+	if err := NewClockUtils().ValidateSchedule(ScheduleStates(specs)); err != nil {
+		t.Errorf("Expected schedule with custom breaks to validate, got %v", err)
+	}
+
+	sm := NewSessionManager()
+	if err := sm.SetCustomBreakDuration(30 * time.Minute); err != nil {
+		t.Fatalf("Expected 30m custom break duration to be accepted, got %v", err)
+	}
+	if err := sm.SetCustomBreakDuration(5 * time.Hour); err == nil {
+		t.Error("Expected custom break duration above the maximum to be rejected")
+	}
+	if err := sm.SetScheduleSpecs(specs); err != nil {
+		t.Fatalf("Expected schedule specs to be accepted, got error: %v", err)
+	}
+
+	// The bare CB uses the configured default, CB:45 its own override
+	if state, _, _, duration := sm.GetSessionInfoAt(1); state != StateCustomBreak || duration != 30*time.Minute {
+		t.Errorf("Expected session 1 to be CB for 30m, got %s for %v", state, duration)
+	}
+	if _, _, _, duration := sm.GetSessionInfoAt(3); duration != 45*time.Minute {
+		t.Errorf("Expected session 3 to last 45m, got %v", duration)
+	}
+
+	if formatted := FormatSchedulingSpecs(sm.GetScheduleSpecs()); formatted != "W-CB-W-CB:45-LB" {
+		t.Errorf("Expected round-trip formatting, got %s", formatted)
+	}
+}
+
+func TestSetScheduleValidation(t *testing.T) {
+	sm := NewSessionManager()
+
+	if err := sm.SetSchedule([]ClockState{}); err == nil {
+		t.Error("Expected error for an empty schedule")
+	}
+
+	if err := sm.SetSchedule([]ClockState{StateShortBreak, StateLongBreak, StateCustomBreak}); err == nil {
+		t.Error("Expected error for a schedule without work sessions")
+	}
+
+	err := sm.SetSchedule([]ClockState{StateWorking, StateShortBreak, StateWorking, ClockState("X"), StateLongBreak})
+	if err == nil {
+		t.Fatal("Expected error for an invalid state")
+	}
+	if !strings.Contains(err.Error(), "position 3") {
+		t.Errorf("Expected error to report position 3, got %q", err)
+	}
+
+	tooLong := make([]ClockState, MaxSessions+1)
+	for i := range tooLong {
+		tooLong[i] = StateWorking
+	}
+	if err := sm.SetSchedule(tooLong); err == nil {
+		t.Errorf("Expected error for a schedule longer than %d sessions", MaxSessions)
+	}
+
+	// A rejected schedule leaves the previous one in place
+	if total := sm.GetTotalSessions(); total != 8 {
+		t.Errorf("Expected default schedule of 8 sessions to remain, got %d", total)
+	}
+
+	if err := sm.SetSchedule(tooLong[:MaxSessions]); err != nil {
+		t.Errorf("Expected a schedule of exactly %d sessions to be accepted, got %v", MaxSessions, err)
+	}
+}
+
+func TestSessionManagerPreviousSession(t *testing.T) {
+	sm := NewSessionManager()
+
+	if sm.PreviousSession() {
+		t.Error("Expected PreviousSession to report false at the first session")
+	}
+	if session := sm.GetCurrentSession(); session != 0 {
+		t.Errorf("Expected to stay at session 0, got %d", session)
+	}
+
+	sm.SetCurrentSession(2)
+	sm.SetCurrentTag("draft")
+	if !sm.PreviousSession() {
+		t.Error("Expected PreviousSession to move back from session 2")
+	}
+	if session := sm.GetCurrentSession(); session != 1 {
+		t.Errorf("Expected session 1, got %d", session)
+	}
+	if tag := sm.GetCurrentTag(); tag != "" {
+		t.Errorf("Expected the tag to be cleared, got %q", tag)
+	}
+
+	if !sm.PreviousSession() || sm.PreviousSession() {
+		t.Error("Expected exactly one more step back to reach the first session")
+	}
+	if session := sm.GetCurrentSession(); session != 0 {
+		t.Errorf("Expected the floor at session 0, got %d", session)
+	}
+}
+
+func TestSessionManagerGetRemainingSessions(t *testing.T) {
+	sm := NewSessionManager()
+
+	// W-SB-W-SB-W-SB-W-LB: from the start the whole schedule is ahead
+	remaining := sm.GetRemainingSessions()
+	if remaining[StateWorking] != 4 || remaining[StateShortBreak] != 3 || remaining[StateLongBreak] != 1 {
+		t.Errorf("Expected 4 W, 3 SB and 1 LB at the start, got %v", remaining)
+	}
+
+	// The current session counts until it is done
+	sm.SetCurrentSession(6)
+	remaining = sm.GetRemainingSessions()
+	if remaining[StateWorking] != 1 || remaining[StateShortBreak] != 0 || remaining[StateLongBreak] != 1 {
+		t.Errorf("Expected 1 W and 1 LB from session 6, got %v", remaining)
+	}
+
+	sm.SetCurrentSession(7)
+	remaining = sm.GetRemainingSessions()
+	if remaining[StateWorking] != 0 || remaining[StateLongBreak] != 1 {
+		t.Errorf("Expected only the long break on the last session, got %v", remaining)
+	}
+
+	// Finishing the cycle starts it over
+	sm.NextSession()
+	if remaining = sm.GetRemainingSessions(); remaining[StateWorking] != 4 {
+		t.Errorf("Expected the full schedule after the cycle wraps, got %v", remaining)
+	}
+}
+
+func TestSessionManagerScheduleIsShared(t *testing.T) {
+	sm := NewSessionManager()
+
+	before := sm.GetSchedule()
+	if again := sm.GetSchedule(); &again[0] != &before[0] {
+		t.Error("Expected reads to share the schedule instead of copying it")
+	}
+
+	// Setting a schedule swaps in a new slice and leaves the old one intact
+	if err := sm.SetSchedule([]ClockState{StateWorking, StateLongBreak}); err != nil {
+		t.Fatalf("Failed to set schedule: %v", err)
+	}
+	if len(before) != 8 || before[7] != StateLongBreak {
+		t.Errorf("Expected the earlier schedule to be unchanged, got %v", before)
+	}
+	if after := sm.GetSchedule(); len(after) != 2 || sm.GetTotalSessions() != 2 {
+		t.Errorf("Expected the new 2-session schedule, got %v", after)
+	}
+}
+
+func TestSessionManagerConcurrentScheduleChange(t *testing.T) {
+	sm := NewSessionManager()
+	long := []ClockState{StateWorking, StateShortBreak, StateWorking, StateShortBreak, StateWorking, StateShortBreak, StateWorking, StateLongBreak}
+	short := []ClockState{StateWorking, StateLongBreak}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	// Readers walk the schedule while it is shortened and lengthened underneath
+	// them; run with -race to verify the read path is synchronized
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					if total, completed := sm.GetCycleDurations(); completed > total {
+						t.Errorf("Expected completed %v within total %v", completed, total)
+					}
+					_, session, total, _ := sm.GetSessionInfo()
+					if session >= total {
+						t.Errorf("Expected session %d below total %d", session, total)
+					}
+					_ = sm.GetCurrentSessionDuration()
+					_ = sm.IsLastSession()
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 2000; i++ {
+		schedule := long
+		if i%2 == 1 {
+			schedule = short
+		}
+		if err := sm.SetSchedule(schedule); err != nil {
+			t.Fatalf("Failed to set schedule: %v", err)
+		}
+		sm.SetCurrentSession(len(schedule) - 1)
+		sm.NextSession()
+	}
+
+	close(stop)
+	wg.Wait()
+}
+
+func TestValidateDurations(t *testing.T) {
+	cu := NewClockUtils()
+
+	cases := []struct {
+		name                   string
+		work, shortBreak, long time.Duration
+		inverted               bool
+	}{
+		{"classic", 25 * time.Minute, 5 * time.Minute, 15 * time.Minute, false},
+		{"equal breaks", 25 * time.Minute, 5 * time.Minute, 5 * time.Minute, false},
+		{"work shorter than short break", 5 * time.Minute, 10 * time.Minute, 15 * time.Minute, true},
+		{"long break shorter than short break", 25 * time.Minute, 10 * time.Minute, 5 * time.Minute, true},
+		{"both inverted", 5 * time.Minute, 10 * time.Minute, 5 * time.Minute, true},
+	}
+	for _, c := range cases {
+		err := cu.ValidateDurations(c.work, c.shortBreak, c.long)
+		if c.inverted != errors.Is(err, ErrInvertedDurations) {
+			t.Errorf("%s: expected inverted=%v, got %v", c.name, c.inverted, err)
+		}
+	}
+
+	err := cu.ValidateDurations(5*time.Minute, 10*time.Minute, 5*time.Minute)
+	if err == nil || !strings.Contains(err.Error(), "work") || !strings.Contains(err.Error(), "long break") {
+		t.Errorf("Expected both problems to be reported, got %v", err)
+	}
+}
+
+func TestApplyPreset(t *testing.T) {
+	cr := NewClockRunner()
+
+	if err := cr.ApplyPreset("long"); err != nil {
+		t.Fatalf("Expected long preset to apply, got %v", err)
+	}
+	if work, short, long := cr.GetDurations(); work != 45 || short != 5 || long != 15 {
+		t.Errorf("Expected long preset durations 45/5/15, got %d/%d/%d", work, short, long)
+	}
+
+	if err := cr.ApplyPreset("micro"); err != nil {
+		t.Fatalf("Expected micro preset to apply, got %v", err)
+	}
+	if work, short, long := cr.GetDurations(); work != 5 || short != 1 || long != 5 {
+		t.Errorf("Expected micro preset durations 5/1/5, got %d/%d/%d", work, short, long)
+	}
+
+	// Break-only entries are not presets, and unknown names leave durations alone
+	for _, name := range []string{"short_break", "pomodoro", ""} {
+		if err := cr.ApplyPreset(name); !errors.Is(err, ErrUnknownPreset) {
+			t.Errorf("Expected ErrUnknownPreset for %q, got %v", name, err)
+		}
+	}
+	if work, _, _ := cr.GetDurations(); work != 5 {
+		t.Errorf("Expected durations to stay at the micro preset, got work %d", work)
+	}
+}
+
+func TestTimerManagerConcurrentReads(t *testing.T) {
+	tm := NewTimerManager()
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	// Readers poll remaining time while the timer is restarted underneath them;
+	// run with -race to verify the read path is synchronized
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					if remaining := tm.GetTimeRemaining(); remaining < 0 || remaining > time.Second {
+						t.Errorf("Expected remaining time within [0, 1s], got %v", remaining)
+					}
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		tm.StartTimer(time.Second, StateWorking, nil, nil)
+		tm.PauseTimer()
+		tm.ResumeTimer()
+	}
+
+	close(stop)
+	wg.Wait()
+	tm.StopTimer()
+}
+
+func TestTimerManagerRemainingDuringCompletion(t *testing.T) {
+	tm := NewTimerManager()
+
+	var completing atomic.Bool
+	done := make(chan struct{})
+	tm.StartTimer(20*time.Millisecond, StateWorking, nil, func(ClockState) {
+		completing.Store(true)
+		// Hold the completion window open so readers observe it
+		time.Sleep(20 * time.Millisecond)
+		close(done)
+	})
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	// Run with -race: readers poll while the timer fires and resets its state
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					inCompletion := completing.Load()
+					remaining := tm.GetTimeRemaining()
+					if inCompletion && remaining != 0 {
+						t.Errorf("Expected 0 remaining once completion started, got %v", remaining)
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	<-done
+	time.Sleep(5 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+
+	if remaining := tm.GetTimeRemaining(); remaining != 0 {
+		t.Errorf("Expected 0 remaining after completion, got %v", remaining)
+	}
+}
+
+func TestTimerManagerFinalTickIsZero(t *testing.T) {
+	tm := NewTimerManager()
+
+	var mu sync.Mutex
+	var ticks []time.Duration
+	ticksAtCompletion := -1
+	done := make(chan struct{})
+	tm.StartTimer(250*time.Millisecond, StateWorking, func(remaining time.Duration) {
+		mu.Lock()
+		ticks = append(ticks, remaining)
+		mu.Unlock()
+	}, func(ClockState) {
+		mu.Lock()
+		ticksAtCompletion = len(ticks)
+		mu.Unlock()
+		close(done)
+	})
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the timer to complete")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if ticksAtCompletion != len(ticks) || len(ticks) < 2 {
+		t.Fatalf("Expected every tick before the completion, got %d ticks with %d before it", len(ticks), ticksAtCompletion)
+	}
+	if last := ticks[len(ticks)-1]; last != 0 {
+		t.Errorf("Expected the last tick before completion to be exactly 0, got %v", last)
+	}
+	for _, remaining := range ticks[:len(ticks)-1] {
+		if remaining <= 0 {
+			t.Errorf("Expected earlier ticks to count down above 0, got %v", remaining)
+		}
+	}
+}
+
+func TestTimerManagerTicksDoNotOverlapCompletion(t *testing.T) {
+	tm := NewTimerManager()
+	if err := tm.SetTickInterval(5 * time.Millisecond); err != nil {
+		t.Fatalf("Failed to set tick interval: %v", err)
+	}
+
+	// Slow ticks make it likely that one is still being delivered when the
+	// session ends; the final zero tick must wait for it. The unsynchronized
+	// counter lets -race catch overlapping ticks too.
+	for i := 0; i < 10; i++ {
+		var inFlight atomic.Int32
+		tickCount := 0
+		done := make(chan struct{})
+		tm.StartTimer(50*time.Millisecond, StateWorking, func(time.Duration) {
+			if inFlight.Add(1) > 1 {
+				t.Error("Expected ticks to be delivered one at a time")
+			}
+			tickCount++
+			time.Sleep(4 * time.Millisecond)
+			inFlight.Add(-1)
+		}, func(ClockState) {
+			close(done)
+		})
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Expected the timer to complete")
+		}
+		if tickCount == 0 {
+			t.Error("Expected ticks before completion")
+		}
+	}
+}
+
+func TestFormatTimeRemainingCompletionGrace(t *testing.T) {
+	tf := NewTimeFormatter()
+
+	cases := []struct {
+		remaining time.Duration
+		want      string
+	}{
+		{time.Second, "00:01 remaining"},
+		{0, "00:00 remaining"},
+		{-DefaultCompletionGrace, "00:00 remaining"},
+		{-DefaultCompletionGrace - time.Millisecond, "Time's up!"},
+	}
+	for _, c := range cases {
+		if got := tf.FormatTimeRemaining(c.remaining); got != c.want {
+			t.Errorf("FormatTimeRemaining(%v) = %q, want %q", c.remaining, got, c.want)
+		}
+	}
+
+	tf.SetCompletionGrace(0)
+	if got := tf.FormatTimeRemaining(-time.Millisecond); got != "Time's up!" {
+		t.Errorf("Expected time to be up just below 0 without a grace, got %q", got)
+	}
+}
+
+func TestTimerManagerGetElapsed(t *testing.T) {
+	tm := NewTimerManager()
+	if elapsed := tm.GetElapsed(); elapsed != 0 {
+		t.Errorf("Expected no elapsed time without a timer, got %v", elapsed)
+	}
+
+	tm.StartTimer(time.Second, StateWorking, nil, nil)
+	defer tm.StopTimer()
+	time.Sleep(50 * time.Millisecond)
+	elapsed := tm.PauseTimer()
+	elapsed = time.Second - elapsed
+	if got := tm.GetElapsed(); got != elapsed || got < 50*time.Millisecond {
+		t.Errorf("Expected %v elapsed at the pause, got %v", elapsed, got)
+	}
+
+	// Frozen while paused
+	time.Sleep(30 * time.Millisecond)
+	if got := tm.GetElapsed(); got != elapsed {
+		t.Errorf("Expected elapsed to stay at %v while paused, got %v", elapsed, got)
+	}
+
+	// Resuming keeps counting from the time before the pause
+	tm.ResumeTimer()
+	time.Sleep(50 * time.Millisecond)
+	if got := tm.GetElapsed(); got < elapsed+50*time.Millisecond || got > elapsed+150*time.Millisecond {
+		t.Errorf("Expected about %v elapsed after resuming, got %v", elapsed+50*time.Millisecond, got)
+	}
+
+	// Adding time lengthens the session without changing what has elapsed
+	before := tm.GetElapsed()
+	tm.AdjustRemaining(time.Minute)
+	if got := tm.GetElapsed(); got < before || got > before+20*time.Millisecond {
+		t.Errorf("Expected elapsed to stay at about %v after adding time, got %v", before, got)
+	}
+}
+
+func TestTimerManagerAdjustRemaining(t *testing.T) {
+	tm := NewTimerManager()
+	if _, err := tm.AdjustRemaining(time.Second); !errors.Is(err, ErrNoTimer) {
+		t.Errorf("Expected ErrNoTimer without a timer, got %v", err)
+	}
+
+	completed := make(chan struct{}, 1)
+	onComplete := func(ClockState) { completed <- struct{}{} }
+
+	// Running: time is added and the timer is rescheduled
+	tm.StartTimer(time.Second, StateWorking, nil, onComplete)
+	remaining, err := tm.AdjustRemaining(time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to add time: %v", err)
+	}
+	if remaining <= time.Minute || remaining > time.Minute+time.Second {
+		t.Errorf("Expected about 1m1s remaining, got %v", remaining)
+	}
+	if got := tm.GetTimeRemaining(); got > remaining || got < remaining-100*time.Millisecond {
+		t.Errorf("Expected GetTimeRemaining to follow the adjustment, got %v", got)
+	}
+
+	// Running: removing more than remains clamps to zero and completes now
+	if remaining, err = tm.AdjustRemaining(-time.Hour); err != nil || remaining != 0 {
+		t.Fatalf("Expected 0 remaining, got %v (err %v)", remaining, err)
+	}
+	select {
+	case <-completed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the session to complete after removing all its time")
+	}
+
+	// Paused: only the stored remaining time changes until resumed
+	tm.StartTimer(time.Minute, StateWorking, nil, onComplete)
+	tm.PauseTimer()
+	if remaining, err = tm.AdjustRemaining(-30 * time.Second); err != nil || remaining > 30*time.Second || remaining < 29*time.Second {
+		t.Errorf("Expected about 30s remaining while paused, got %v (err %v)", remaining, err)
+	}
+	if remaining, err = tm.AdjustRemaining(-time.Hour); err != nil || remaining != 0 {
+		t.Fatalf("Expected 0 remaining while paused, got %v (err %v)", remaining, err)
+	}
+	select {
+	case <-completed:
+		t.Fatal("Expected a paused session not to complete until resumed")
+	case <-time.After(50 * time.Millisecond):
+	}
+	tm.ResumeTimer()
+	select {
+	case <-completed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected a paused session adjusted to zero to complete on resume")
+	}
+
+	// Adjustments are capped at the longest session
+	tm.StartTimer(time.Minute, StateWorking, nil, nil)
+	defer tm.StopTimer()
+	if remaining, _ = tm.AdjustRemaining(24 * time.Hour); remaining != MaxSessionDuration {
+		t.Errorf("Expected remaining capped at %v, got %v", MaxSessionDuration, remaining)
+	}
+}
+
+func TestStateManagerConcurrentAccess(t *testing.T) {
+	sm := NewStateManager()
+	states := []ClockState{StateWorking, StatePaused, StateShortBreak, StateIdle}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				sm.SetState(states[(i+j)%len(states)])
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = sm.IsRunning()
+				_ = sm.IsPaused()
+				if _, ok := ClockStateMap[string(sm.GetState())]; !ok {
+					t.Errorf("Observed unknown state %s", sm.GetState())
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,59 @@
+package test
+
+import (
+	"testing"
+
+	"pomodoroService/internal/auth"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	cases := map[string]string{
+		"A@x.com":               "a@x.com",
+		"  Alice@Example.COM\t": "alice@example.com",
+		"bob@example.com":       "bob@example.com",
+	}
+	for input, want := range cases {
+		if got := auth.NormalizeEmail(input); got != want {
+			t.Errorf("NormalizeEmail(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestNormalizeUsernameFollowsConfig(t *testing.T) {
+	t.Cleanup(func() { auth.SetCaseInsensitiveUsernames(false) })
+
+	if got := auth.NormalizeUsername("  Alice "); got != "Alice" {
+		t.Errorf("Expected usernames to keep their case by default, got %q", got)
+	}
+
+	t.Setenv("CASE_INSENSITIVE_USERNAMES", "true")
+	if err := auth.LoadUsernameNormalization(); err != nil {
+		t.Fatalf("LoadUsernameNormalization failed: %v", err)
+	}
+	if got := auth.NormalizeUsername("  Alice "); got != "alice" {
+		t.Errorf("Expected a lowercased username, got %q", got)
+	}
+
+	t.Setenv("CASE_INSENSITIVE_USERNAMES", "sometimes")
+	if err := auth.LoadUsernameNormalization(); err == nil {
+		t.Error("Expected an error for an invalid CASE_INSENSITIVE_USERNAMES")
+	}
+}
+
+func TestNewUserFromRequestNormalizes(t *testing.T) {
+	t.Cleanup(func() { auth.SetCaseInsensitiveUsernames(false) })
+	auth.SetCaseInsensitiveUsernames(true)
+
+	user := auth.NewUserFromRequest(&auth.NewUserRequest{
+		Username: " Alice",
+		Password: " Password1! ",
+		Email:    "A@x.com ",
+	})
+	if *user.Username != "alice" || *user.Email != "a@x.com" {
+		t.Errorf("Expected alice and a@x.com, got %q and %q", *user.Username, *user.Email)
+	}
+	// Passwords are used exactly as given
+	if *user.Password != " Password1! " {
+		t.Errorf("Expected the password to be left alone, got %q", *user.Password)
+	}
+}
@@ -0,0 +1,57 @@
+package test
+
+import (
+	"testing"
+
+	"pomodoroService/internal/auth"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHashPasswordUsesConfiguredCost(t *testing.T) {
+	t.Cleanup(func() { auth.SetBcryptCost(auth.DefaultBcryptCost) })
+
+	t.Setenv("BCRYPT_COST", "5")
+	if err := auth.LoadBcryptCost(); err != nil {
+		t.Fatalf("LoadBcryptCost failed: %v", err)
+	}
+
+	hash, err := auth.HashPassword("Password1!")
+	if err != nil {
+		t.Fatalf("HashPassword failed: %v", err)
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		t.Fatalf("Failed to decode hash cost: %v", err)
+	}
+	if cost != 5 {
+		t.Errorf("Expected cost 5, got %d", cost)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte("Password1!")); err != nil {
+		t.Errorf("Hash does not match password: %v", err)
+	}
+}
+
+func TestLoadBcryptCostRejectsBadConfig(t *testing.T) {
+	t.Cleanup(func() { auth.SetBcryptCost(auth.DefaultBcryptCost) })
+
+	for _, value := range []string{"3", "32", "twelve"} {
+		t.Run(value, func(t *testing.T) {
+			t.Setenv("BCRYPT_COST", value)
+			if err := auth.LoadBcryptCost(); err == nil {
+				t.Error("Expected an error")
+			}
+			if got := auth.GetBcryptCost(); got != auth.DefaultBcryptCost {
+				t.Errorf("Expected cost to stay %d, got %d", auth.DefaultBcryptCost, got)
+			}
+		})
+	}
+
+	t.Setenv("BCRYPT_COST", "")
+	if err := auth.LoadBcryptCost(); err != nil {
+		t.Fatalf("LoadBcryptCost failed: %v", err)
+	}
+	if got := auth.GetBcryptCost(); got != auth.DefaultBcryptCost {
+		t.Errorf("Expected default cost %d, got %d", auth.DefaultBcryptCost, got)
+	}
+}
@@ -2,6 +2,7 @@ package test
 
 import (
 	"context"
+	"errors"
 	"log"
 	"testing"
 	"time"
@@ -54,6 +55,24 @@ func TestRedisIntegration(t *testing.T) {
 	if loadedSettings.WorkTime != settings.WorkTime {
 		t.Errorf("Expected work time %d, got %d", settings.WorkTime, loadedSettings.WorkTime)
 	}
+	if loadedSettings.WaitForStart {
+		t.Error("Expected auto-start to stay on when the toggle was not set")
+	}
+
+	settings.WaitForStart = true
+	if err := redisPersistence.SaveSettings(settings); err != nil {
+		t.Fatalf("Failed to save settings: %v", err)
+	}
+	if loadedSettings, err = redisPersistence.LoadSettings(); err != nil {
+		t.Fatalf("Failed to load settings: %v", err)
+	}
+	if !loadedSettings.WaitForStart {
+		t.Error("Expected turning auto-start off to be persisted")
+	}
+	settings.WaitForStart = false
+	if err := redisPersistence.SaveSettings(settings); err != nil {
+		t.Fatalf("Failed to restore settings: %v", err)
+	}
 
 	// Test system state save/load
 	loc := time.Now().Location()
@@ -132,3 +151,946 @@ func TestClockRunnerWithRedis(t *testing.T) {
 
 	log.Println("Clock runner with Redis test passed")
 }
+
+func TestStatisticsPersistAcrossRestart(t *testing.T) {
+	// Skip if Redis is not available
+	client := redis.NewClient(&redis.Options{
+		Addr:     redisAddr,
+		Password: "",
+		DB:       0,
+	})
+
+	ctx := context.Background()
+	_, err := client.Ping(ctx).Result()
+	if err != nil {
+		t.Skip("Redis not available, skipping test")
+	}
+	defer client.Close()
+
+	redisPersistence, err := clock.NewRedisPersistence(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create Redis persistence: %v", err)
+	}
+	defer redisPersistence.Close()
+
+	// Start from a clean slate and clean up afterwards
+	if _, err := redisPersistence.DeleteSessionStatistics(); err != nil {
+		t.Fatalf("Failed to clear session statistics: %v", err)
+	}
+	defer redisPersistence.DeleteSessionStatistics()
+
+	now := time.Now()
+	redisPersistence.SaveSessionStatistics(string(clock.StateWorking), 25*time.Minute, now)
+	redisPersistence.SaveSessionStatistics(string(clock.StateWorking), 25*time.Minute, now)
+	redisPersistence.SaveSessionStatistics(string(clock.StateShortBreak), 5*time.Minute, now)
+	redisPersistence.SaveSessionStatistics(string(clock.StateLongBreak), 15*time.Minute, now.AddDate(0, 0, -1))
+
+	// A fresh runner simulates a process restart
+	cr, err := clock.NewClockRunnerWithRedis(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create clock runner: %v", err)
+	}
+	defer cr.Close()
+
+	work, short, long := cr.GetStatistics()
+	if work != 2 || short != 1 || long != 1 {
+		t.Errorf("Expected restored statistics 2/1/1, got %d/%d/%d", work, short, long)
+	}
+
+	workTime, breakTime, totalTime := cr.GetTimingStatistics()
+	if workTime != 50*time.Minute || breakTime != 20*time.Minute || totalTime != 70*time.Minute {
+		t.Errorf("Expected restored timing 50m/20m/70m, got %v/%v/%v", workTime, breakTime, totalTime)
+	}
+
+	// The history is restored as well, so it matches the counts and today's
+	// figures derived from it survive the restart
+	snapshot := cr.GetStatisticsSnapshot()
+	if len(snapshot.History) != 4 {
+		t.Fatalf("Expected 4 restored history records, got %d", len(snapshot.History))
+	}
+	if first := snapshot.History[0]; first.State != clock.StateLongBreak || first.Duration != 15*time.Minute {
+		t.Errorf("Expected yesterday's long break first, got %+v", first)
+	}
+	if focus := cr.GetTodayFocusTime(); focus != 50*time.Minute {
+		t.Errorf("Expected 50m of focus time today after a restart, got %v", focus)
+	}
+}
+
+func TestCompletedSessionsRecordedToRedis(t *testing.T) {
+	// Skip if Redis is not available
+	client := redis.NewClient(&redis.Options{
+		Addr:     redisAddr,
+		Password: "",
+		DB:       0,
+	})
+
+	ctx := context.Background()
+	_, err := client.Ping(ctx).Result()
+	if err != nil {
+		t.Skip("Redis not available, skipping test")
+	}
+	defer client.Close()
+
+	redisPersistence, err := clock.NewRedisPersistence(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create Redis persistence: %v", err)
+	}
+	defer redisPersistence.Close()
+
+	if _, err := redisPersistence.DeleteSessionStatistics(); err != nil {
+		t.Fatalf("Failed to clear session statistics: %v", err)
+	}
+	defer redisPersistence.DeleteSessionStatistics()
+	// Restore production-sized settings after running sub-second sessions
+	defer redisPersistence.SaveSettings(&clock.PomodoroSettings{
+		WorkTime:       25,
+		ShortBreakTime: 5,
+		LongBreakTime:  15,
+		Scheduling:     "default",
+	})
+
+	cr, err := clock.NewClockRunnerWithRedis(redisAddr, clock.WithMinDuration(time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create clock runner: %v", err)
+	}
+	defer cr.Close()
+
+	cr.Stop() // discard any session resumed from Redis
+	if err := cr.SetDurations(100*time.Millisecond, 50*time.Millisecond, 75*time.Millisecond); err != nil {
+		t.Fatalf("Failed to set durations: %v", err)
+	}
+	if _, err := cr.Start(); err != nil {
+		t.Fatalf("Failed to start clock: %v", err)
+	}
+
+	// Wait for the first work session to complete
+	time.Sleep(300 * time.Millisecond)
+	cr.Stop()
+
+	key := "session_stats:" + string(clock.StateWorking) + ":" + time.Now().Format("2006-01-02")
+	count, err := client.HGet(ctx, key, "count").Int()
+	if err != nil {
+		t.Fatalf("Expected %s to exist after a completed work session: %v", key, err)
+	}
+	if count < 1 {
+		t.Errorf("Expected at least 1 recorded work session, got %d", count)
+	}
+}
+
+func TestCompletionEventBuffer(t *testing.T) {
+	// Skip if Redis is not available
+	client := redis.NewClient(&redis.Options{
+		Addr:     redisAddr,
+		Password: "",
+		DB:       0,
+	})
+
+	ctx := context.Background()
+	_, err := client.Ping(ctx).Result()
+	if err != nil {
+		t.Skip("Redis not available, skipping test")
+	}
+	defer client.Close()
+
+	redisPersistence, err := clock.NewRedisPersistence(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create Redis persistence: %v", err)
+	}
+	defer redisPersistence.Close()
+
+	client.Del(ctx, "completionEvents")
+	defer client.Del(ctx, "completionEvents")
+
+	if err := redisPersistence.SetCompletionEventBuffer(0, time.Hour); err == nil {
+		t.Error("Expected an error for a limit of 0")
+	}
+	if err := redisPersistence.SetCompletionEventBuffer(3, clock.MaxCompletionEventTTL+time.Hour); err == nil {
+		t.Error("Expected an error for an expiry above the maximum")
+	}
+	if err := redisPersistence.SetCompletionEventBuffer(3, time.Hour); err != nil {
+		t.Fatalf("Failed to set the completion event buffer: %v", err)
+	}
+
+	// An event older than the expiry is dropped, and only the latest 3 are kept
+	expired := clock.CompletionEvent{CompletedState: clock.StateWorking, CompletedAt: time.Now().Add(-2 * time.Hour)}
+	if err := redisPersistence.SaveCompletionEvent(expired); err != nil {
+		t.Fatalf("Failed to save completion event: %v", err)
+	}
+	base := time.Now().Add(-10 * time.Minute).Truncate(time.Second).Add(500 * time.Millisecond)
+	for i := 0; i < 5; i++ {
+		event := clock.CompletionEvent{
+			CompletedState: clock.StateWorking,
+			NextState:      clock.StateShortBreak,
+			SessionNumber:  i,
+			WorkMinutes:    25,
+			CompletedAt:    base.Add(time.Duration(i) * time.Minute),
+		}
+		if err := redisPersistence.SaveCompletionEvent(event); err != nil {
+			t.Fatalf("Failed to save completion event %d: %v", i, err)
+		}
+	}
+
+	events, err := redisPersistence.LoadCompletionEvents(time.Time{})
+	if err != nil {
+		t.Fatalf("Failed to load completion events: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("Expected the latest 3 events, got %d", len(events))
+	}
+	for i, event := range events {
+		if event.SessionNumber != i+2 || event.WorkMinutes != 25 || event.NextState != clock.StateShortBreak {
+			t.Errorf("Expected event %d to be session %d, got %+v", i, i+2, event)
+		}
+	}
+
+	// since is exclusive to the millisecond, the precision of the completedAt
+	// clients see
+	const timeFormatMillis = "2006-01-02T15:04:05.000Z07:00"
+	since, _ := time.Parse(time.RFC3339, events[1].CompletedAt.Format(timeFormatMillis))
+	events, err = redisPersistence.LoadCompletionEvents(since)
+	if err != nil {
+		t.Fatalf("Failed to load completion events: %v", err)
+	}
+	if len(events) != 1 || events[0].SessionNumber != 4 {
+		t.Fatalf("Expected only session 4 after %v, got %+v", since, events)
+	}
+
+	// An event completed later in the same second as the cursor is not dropped
+	last := events[0].CompletedAt
+	sameSecond := clock.CompletionEvent{CompletedState: clock.StateShortBreak, SessionNumber: 5, CompletedAt: last.Add(200 * time.Millisecond)}
+	if err := redisPersistence.SaveCompletionEvent(sameSecond); err != nil {
+		t.Fatalf("Failed to save completion event: %v", err)
+	}
+	since, _ = time.Parse(time.RFC3339, last.Format(timeFormatMillis))
+	events, err = redisPersistence.LoadCompletionEvents(since)
+	if err != nil {
+		t.Fatalf("Failed to load completion events: %v", err)
+	}
+	if len(events) != 1 || events[0].SessionNumber != 5 {
+		t.Errorf("Expected only session 5 after %v, got %+v", since, events)
+	}
+}
+
+func TestStatsRetentionSetsTTL(t *testing.T) {
+	// Skip if Redis is not available
+	client := redis.NewClient(&redis.Options{
+		Addr:     redisAddr,
+		Password: "",
+		DB:       0,
+	})
+
+	ctx := context.Background()
+	_, err := client.Ping(ctx).Result()
+	if err != nil {
+		t.Skip("Redis not available, skipping test")
+	}
+	defer client.Close()
+
+	redisPersistence, err := clock.NewRedisPersistence(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create Redis persistence: %v", err)
+	}
+	defer redisPersistence.Close()
+
+	if got := redisPersistence.GetStatsRetention(); got != clock.DefaultStatsRetention {
+		t.Errorf("Expected the default retention of %v, got %v", clock.DefaultStatsRetention, got)
+	}
+	for _, invalid := range []time.Duration{0, -time.Hour, clock.MaxStatsRetention + time.Hour} {
+		if err := redisPersistence.SetStatsRetention(invalid); err == nil {
+			t.Errorf("Expected an error for a retention of %v", invalid)
+		}
+	}
+
+	retention := 7 * 24 * time.Hour
+	if err := redisPersistence.SetStatsRetention(retention); err != nil {
+		t.Fatalf("Failed to set retention: %v", err)
+	}
+
+	// A day no other test records on
+	completed := time.Date(2001, 2, 3, 12, 0, 0, 0, time.UTC)
+	key := "session_stats:W:2001-02-03"
+	defer client.Del(ctx, key)
+	if err := redisPersistence.SaveSessionStatistics(string(clock.StateWorking), 25*time.Minute, completed); err != nil {
+		t.Fatalf("Failed to save session statistics: %v", err)
+	}
+
+	ttl, err := client.TTL(ctx, key).Result()
+	if err != nil {
+		t.Fatalf("Failed to read TTL of %s: %v", key, err)
+	}
+	if ttl > retention || ttl < retention-time.Minute {
+		t.Errorf("Expected a TTL of about %v, got %v", retention, ttl)
+	}
+}
+
+func TestStartAtPersistsSession(t *testing.T) {
+	// Skip if Redis is not available
+	client := redis.NewClient(&redis.Options{
+		Addr:     redisAddr,
+		Password: "",
+		DB:       0,
+	})
+
+	ctx := context.Background()
+	_, err := client.Ping(ctx).Result()
+	if err != nil {
+		t.Skip("Redis not available, skipping test")
+	}
+	defer client.Close()
+
+	cr, err := clock.NewClockRunnerWithRedis(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create clock runner: %v", err)
+	}
+	defer cr.Close()
+
+	cr.Stop() // discard any session resumed from Redis
+	defer cr.Stop()
+	if _, err := cr.StartAt(2); err != nil {
+		t.Fatalf("Failed to start at session 2: %v", err)
+	}
+
+	redisPersistence, err := clock.NewRedisPersistence(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create Redis persistence: %v", err)
+	}
+	defer redisPersistence.Close()
+
+	state, err := redisPersistence.LoadSystemState()
+	if err != nil {
+		t.Fatalf("Failed to load system state: %v", err)
+	}
+	if state.CurrentSession != 2 || state.State != string(clock.StateWorking) {
+		t.Errorf("Expected work session 2 to be persisted, got %s at session %d", state.State, state.CurrentSession)
+	}
+}
+
+func TestCloseTwiceWithRedis(t *testing.T) {
+	// Skip if Redis is not available
+	client := redis.NewClient(&redis.Options{
+		Addr:     redisAddr,
+		Password: "",
+		DB:       0,
+	})
+
+	ctx := context.Background()
+	_, err := client.Ping(ctx).Result()
+	if err != nil {
+		t.Skip("Redis not available, skipping test")
+	}
+	defer client.Close()
+
+	cr, err := clock.NewClockRunnerWithRedis(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create clock runner: %v", err)
+	}
+
+	if err := cr.Close(); err != nil {
+		t.Fatalf("First Close failed: %v", err)
+	}
+	// The Redis client is already closed; a second Close must not report it
+	if err := cr.Close(); err != nil {
+		t.Errorf("Second Close failed: %v", err)
+	}
+}
+
+func TestStaleSystemStateRefused(t *testing.T) {
+	// Skip if Redis is not available
+	client := redis.NewClient(&redis.Options{
+		Addr:     redisAddr,
+		Password: "",
+		DB:       0,
+	})
+
+	ctx := context.Background()
+	_, err := client.Ping(ctx).Result()
+	if err != nil {
+		t.Skip("Redis not available, skipping test")
+	}
+	defer client.Close()
+
+	redisPersistence, err := clock.NewRedisPersistence(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create Redis persistence: %v", err)
+	}
+	defer redisPersistence.Close()
+	// Start from and leave behind an idle, unversioned state
+	idle := &clock.SystemState{State: string(clock.StateIdle), EndTime: time.Now()}
+	if err := redisPersistence.SaveSystemState(idle); err != nil {
+		t.Fatalf("Failed to reset state: %v", err)
+	}
+	defer redisPersistence.SaveSystemState(idle)
+
+	newer := &clock.SystemState{CurrentSession: 3, State: string(clock.StateWorking), EndTime: time.Now(), Version: 200}
+	if err := redisPersistence.SaveSystemState(newer); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	older := &clock.SystemState{CurrentSession: 1, State: string(clock.StateShortBreak), EndTime: time.Now(), Version: 100}
+	if err := redisPersistence.SaveSystemState(older); !errors.Is(err, clock.ErrStaleSystemState) {
+		t.Errorf("Expected ErrStaleSystemState for an older version, got %v", err)
+	}
+
+	loaded, err := redisPersistence.LoadSystemState()
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if loaded.CurrentSession != 3 || loaded.Version != 200 {
+		t.Errorf("Expected the newer state to be kept, got session %d version %d", loaded.CurrentSession, loaded.Version)
+	}
+
+	// Unversioned saves are always written
+	unversioned := &clock.SystemState{CurrentSession: 5, State: string(clock.StateWorking), EndTime: time.Now()}
+	if err := redisPersistence.SaveSystemState(unversioned); err != nil {
+		t.Errorf("Expected an unversioned save to be written, got %v", err)
+	}
+}
+
+func TestLoadLegacySystemState(t *testing.T) {
+	// Skip if Redis is not available
+	client := redis.NewClient(&redis.Options{
+		Addr:     redisAddr,
+		Password: "",
+		DB:       0,
+	})
+
+	ctx := context.Background()
+	_, err := client.Ping(ctx).Result()
+	if err != nil {
+		t.Skip("Redis not available, skipping test")
+	}
+	defer client.Close()
+
+	redisPersistence, err := clock.NewRedisPersistence(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create Redis persistence: %v", err)
+	}
+	defer redisPersistence.Close()
+	idle := &clock.SystemState{State: string(clock.StateIdle), EndTime: time.Now()}
+	defer redisPersistence.SaveSystemState(idle)
+
+	// A state saved by an older server only exists as a hash
+	client.Del(ctx, "systemState:json")
+	client.HSet(ctx, "systemState", map[string]interface{}{
+		"currentSession": 2,
+		"endTime":        "2025-01-15T10:00:00Z",
+		"state":          string(clock.StatePaused),
+		"timeRemaining":  90000,
+		"isRunning":      "false",
+		"isPaused":       "1",
+	})
+
+	state, err := redisPersistence.LoadSystemState()
+	if err != nil {
+		t.Fatalf("Failed to load legacy state: %v", err)
+	}
+	if state.CurrentSession != 2 || state.TimeRemaining != 90000 || !state.IsPaused || state.IsRunning {
+		t.Errorf("Expected the legacy hash to be parsed, got %+v", state)
+	}
+
+	// Saving writes the JSON document and drops the hash
+	state.Version = 1
+	if err := redisPersistence.SaveSystemState(state); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	if exists := client.Exists(ctx, "systemState").Val(); exists != 0 {
+		t.Error("Expected the legacy hash to be removed after saving")
+	}
+	reloaded, err := redisPersistence.LoadSystemState()
+	if err != nil {
+		t.Fatalf("Failed to reload state: %v", err)
+	}
+	if reloaded.CurrentSession != 2 || reloaded.TimeRemaining != 90000 || !reloaded.IsPaused {
+		t.Errorf("Expected the state to round-trip through JSON, got %+v", reloaded)
+	}
+}
+
+func TestLoadSystemStateReportsRepairs(t *testing.T) {
+	// Skip if Redis is not available
+	client := redis.NewClient(&redis.Options{
+		Addr:     redisAddr,
+		Password: "",
+		DB:       0,
+	})
+
+	ctx := context.Background()
+	_, err := client.Ping(ctx).Result()
+	if err != nil {
+		t.Skip("Redis not available, skipping test")
+	}
+	defer client.Close()
+
+	redisPersistence, err := clock.NewRedisPersistence(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create Redis persistence: %v", err)
+	}
+	defer redisPersistence.Close()
+	idle := &clock.SystemState{State: string(clock.StateIdle), EndTime: time.Now()}
+	defer redisPersistence.SaveSystemState(idle)
+
+	// A valid state needs no repairs
+	if err := redisPersistence.SaveSystemState(idle); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	load, err := redisPersistence.LoadSystemStateWithRepairs()
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if len(load.Repairs) != 0 {
+		t.Errorf("Expected no repairs for a valid state, got %v", load.Repairs)
+	}
+
+	// An unknown state with a negative remaining time is repaired twice over
+	client.Set(ctx, "systemState:json", `{"schemaVersion":2,"currentSession":1,"state":"XX","timeRemaining":-5,"endTime":0}`, 0)
+	load, err = redisPersistence.LoadSystemStateWithRepairs()
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if load.Raw.State != "XX" || load.Raw.TimeRemaining != -5 {
+		t.Errorf("Expected the raw state to be left as stored, got %+v", load.Raw)
+	}
+	if load.Repaired.State != string(clock.StateIdle) || load.Repaired.TimeRemaining != 0 {
+		t.Errorf("Expected the repaired state to be idle with no time remaining, got %+v", load.Repaired)
+	}
+	if len(load.Repairs) != 2 {
+		t.Errorf("Expected 2 repairs, got %v", load.Repairs)
+	}
+
+	// LoadSystemState returns the repaired state
+	state, err := redisPersistence.LoadSystemState()
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if state.State != string(clock.StateIdle) {
+		t.Errorf("Expected LoadSystemState to repair the state, got %s", state.State)
+	}
+}
+
+func TestSystemStateEndTimePrecision(t *testing.T) {
+	// Skip if Redis is not available
+	client := redis.NewClient(&redis.Options{
+		Addr:     redisAddr,
+		Password: "",
+		DB:       0,
+	})
+
+	ctx := context.Background()
+	_, err := client.Ping(ctx).Result()
+	if err != nil {
+		t.Skip("Redis not available, skipping test")
+	}
+	defer client.Close()
+
+	redisPersistence, err := clock.NewRedisPersistence(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create Redis persistence: %v", err)
+	}
+	defer redisPersistence.Close()
+	defer redisPersistence.SaveSystemState(&clock.SystemState{State: string(clock.StateIdle), EndTime: time.Now()})
+
+	endTime := time.Date(2025, 1, 15, 10, 0, 0, 987654321, time.UTC)
+	state := &clock.SystemState{State: string(clock.StateWorking), EndTime: endTime, TimeRemaining: 1500}
+	if err := redisPersistence.SaveSystemState(state); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	loaded, err := redisPersistence.LoadSystemState()
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if !loaded.EndTime.Equal(endTime.Truncate(time.Millisecond)) {
+		t.Errorf("Expected end time %v to keep its milliseconds, got %v", endTime, loaded.EndTime)
+	}
+	if remaining := loaded.RemainingAt(endTime.Add(-1500 * time.Millisecond)); remaining < 1499*time.Millisecond || remaining > 1500*time.Millisecond {
+		t.Errorf("Expected about 1.5s remaining, got %v", remaining)
+	}
+}
+
+func TestRedisRetryAndHealthCallback(t *testing.T) {
+	// Skip if Redis is not available
+	client := redis.NewClient(&redis.Options{
+		Addr:     redisAddr,
+		Password: "",
+		DB:       0,
+	})
+
+	ctx := context.Background()
+	_, err := client.Ping(ctx).Result()
+	if err != nil {
+		t.Skip("Redis not available, skipping test")
+	}
+	defer client.Close()
+
+	redisPersistence, err := clock.NewRedisPersistence(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create Redis persistence: %v", err)
+	}
+
+	if err := redisPersistence.SetRetryPolicy(0, time.Millisecond); err == nil {
+		t.Error("Expected zero retry attempts to be rejected")
+	}
+	if err := redisPersistence.SetRetryPolicy(3, -time.Millisecond); err == nil {
+		t.Error("Expected negative backoff to be rejected")
+	}
+	if err := redisPersistence.SetRetryPolicy(3, time.Millisecond); err != nil {
+		t.Fatalf("Failed to set retry policy: %v", err)
+	}
+
+	var transitions []bool
+	redisPersistence.SetHealthCallback(func(healthy bool, err error) {
+		transitions = append(transitions, healthy)
+	})
+
+	if !redisPersistence.IsHealthy() {
+		t.Fatal("Expected persistence to start healthy")
+	}
+
+	// Writes on a closed client fail on every attempt and degrade persistence
+	redisPersistence.Close()
+	if err := redisPersistence.SaveSettings(&clock.PomodoroSettings{WorkTime: 25}); err == nil {
+		t.Fatal("Expected save on a closed connection to fail")
+	}
+	if redisPersistence.IsHealthy() {
+		t.Error("Expected persistence to be unhealthy after failed writes")
+	}
+	if len(transitions) != 1 || transitions[0] {
+		t.Errorf("Expected a single degraded transition, got %v", transitions)
+	}
+}
+
+func TestStateSaveWindowCoalescesSaves(t *testing.T) {
+	// Skip if Redis is not available
+	client := redis.NewClient(&redis.Options{
+		Addr:     redisAddr,
+		Password: "",
+		DB:       0,
+	})
+
+	ctx := context.Background()
+	_, err := client.Ping(ctx).Result()
+	if err != nil {
+		t.Skip("Redis not available, skipping test")
+	}
+	defer client.Close()
+
+	const window = 200 * time.Millisecond
+	cr, err := clock.NewClockRunnerWithRedis(redisAddr, clock.WithStateSaveWindow(window))
+	if err != nil {
+		t.Fatalf("Failed to create clock runner: %v", err)
+	}
+	defer cr.Close()
+
+	// Stop saves right away, so the changes that follow fall in its window
+	cr.Start()
+	cr.Stop()
+	defer cr.Stop()
+
+	redisPersistence, err := clock.NewRedisPersistence(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create Redis persistence: %v", err)
+	}
+	defer redisPersistence.Close()
+
+	// Rapid changes right after the stop's save are written once, as the last state
+	saves := cr.GetRedisPersistence().SystemStateSaves()
+	cr.Start()
+	cr.Pause()
+	cr.Start()
+	cr.Pause()
+	if n := cr.GetRedisPersistence().SystemStateSaves() - saves; n != 0 {
+		t.Errorf("Expected the changes to wait for the save window, got %d saves", n)
+	}
+
+	time.Sleep(window + 100*time.Millisecond)
+	if n := cr.GetRedisPersistence().SystemStateSaves() - saves; n != 1 {
+		t.Errorf("Expected the changes to be coalesced into 1 save, got %d", n)
+	}
+	state, err := redisPersistence.LoadSystemState()
+	if err != nil {
+		t.Fatalf("Failed to load system state: %v", err)
+	}
+	if state.State != string(clock.StatePaused) {
+		t.Errorf("Expected the last state, paused, to be saved, got %s", state.State)
+	}
+
+	// Stop saves immediately even inside the window
+	cr.Start()
+	cr.Stop()
+	state, err = redisPersistence.LoadSystemState()
+	if err != nil {
+		t.Fatalf("Failed to load system state: %v", err)
+	}
+	if state.State != string(clock.StateIdle) {
+		t.Errorf("Expected Stop to save the idle state right away, got %s", state.State)
+	}
+}
+
+func TestScheduleTemplates(t *testing.T) {
+	// Skip if Redis is not available
+	client := redis.NewClient(&redis.Options{
+		Addr:     redisAddr,
+		Password: "",
+		DB:       0,
+	})
+
+	ctx := context.Background()
+	_, err := client.Ping(ctx).Result()
+	if err != nil {
+		t.Skip("Redis not available, skipping test")
+	}
+	defer client.Close()
+	defer client.Del(ctx, "scheduleTemplates")
+
+	cr, err := clock.NewClockRunnerWithRedis(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create clock runner: %v", err)
+	}
+	defer cr.Close()
+	cr.Stop()
+	defer cr.SetDurations(25*time.Minute, 5*time.Minute, 15*time.Minute)
+	// Restored first, so the durations' save persists the default schedule
+	defer cr.SetSchedule(clock.NewSessionManager().GetSchedule())
+
+	deepWork := []clock.ClockState{clock.StateWorking, clock.StateShortBreak, clock.StateWorking, clock.StateLongBreak}
+	if err := cr.SaveTemplate("deep-work", deepWork, 50*time.Minute, 10*time.Minute, 30*time.Minute); err != nil {
+		t.Fatalf("Failed to save template: %v", err)
+	}
+	if err := cr.SaveTemplate("study", []clock.ClockState{clock.StateWorking, clock.StateShortBreak}, 25*time.Minute, 5*time.Minute, 15*time.Minute); err != nil {
+		t.Fatalf("Failed to save template: %v", err)
+	}
+
+	// Invalid templates are rejected before they are stored
+	invalid := map[string]error{
+		"bad name":     cr.SaveTemplate("deep work", deepWork, 50*time.Minute, 10*time.Minute, 30*time.Minute),
+		"bad schedule": cr.SaveTemplate("breaks", []clock.ClockState{clock.StateShortBreak}, 50*time.Minute, 10*time.Minute, 30*time.Minute),
+		"bad duration": cr.SaveTemplate("marathon", deepWork, 5*time.Hour, 10*time.Minute, 30*time.Minute),
+	}
+	for name, err := range invalid {
+		if !errors.Is(err, clock.ErrInvalidTemplate) {
+			t.Errorf("Expected ErrInvalidTemplate for %s, got %v", name, err)
+		}
+	}
+
+	templates, err := cr.ListTemplates()
+	if err != nil {
+		t.Fatalf("Failed to list templates: %v", err)
+	}
+	if len(templates) != 2 || templates[0].Name != "deep-work" || templates[1].Name != "study" {
+		t.Errorf("Expected deep-work and study sorted by name, got %+v", templates)
+	}
+
+	if _, err := cr.ApplyTemplate("missing"); !errors.Is(err, clock.ErrTemplateNotFound) {
+		t.Errorf("Expected ErrTemplateNotFound, got %v", err)
+	}
+
+	// Applying sets the durations and the schedule together
+	if _, err := cr.ApplyTemplate("deep-work"); err != nil {
+		t.Fatalf("Failed to apply template: %v", err)
+	}
+	if work, shortBreak, longBreak := cr.GetDurations(); work != 50 || shortBreak != 10 || longBreak != 30 {
+		t.Errorf("Expected 50/10/30 minutes, got %d/%d/%d", work, shortBreak, longBreak)
+	}
+	if schedule := cr.GetSchedule(); clock.FormatScheduling(schedule) != "W-SB-W-LB" {
+		t.Errorf("Expected schedule W-SB-W-LB, got %s", clock.FormatScheduling(schedule))
+	}
+
+	// The applied template survives a restart, schedule included
+	restarted, err := clock.NewClockRunnerWithRedis(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create clock runner: %v", err)
+	}
+	if work, shortBreak, longBreak := restarted.GetDurations(); work != 50 || shortBreak != 10 || longBreak != 30 {
+		t.Errorf("Expected 50/10/30 minutes after a restart, got %d/%d/%d", work, shortBreak, longBreak)
+	}
+	if schedule := restarted.GetSchedule(); clock.FormatScheduling(schedule) != "W-SB-W-LB" {
+		t.Errorf("Expected schedule W-SB-W-LB after a restart, got %s", clock.FormatScheduling(schedule))
+	}
+	restarted.Close()
+
+	// A stored template that no longer validates changes nothing
+	client.HSet(ctx, "scheduleTemplates", "broken", `{"name":"broken","schedule":["W"],"workTime":0,"shortBreakTime":5,"longBreakTime":15}`)
+	if _, err := cr.ApplyTemplate("broken"); !errors.Is(err, clock.ErrInvalidTemplate) {
+		t.Errorf("Expected ErrInvalidTemplate for a corrupt template, got %v", err)
+	}
+	if work, _, _ := cr.GetDurations(); work != 50 {
+		t.Errorf("Expected the durations to stay unchanged, got %d minutes of work", work)
+	}
+
+	// Templates are applied only while idle
+	cr.Start()
+	if _, err := cr.ApplyTemplate("study"); !errors.Is(err, clock.ErrClockNotIdle) {
+		t.Errorf("Expected ErrClockNotIdle while running, got %v", err)
+	}
+	cr.Stop()
+}
+
+func TestScheduleOverridesSurviveRestart(t *testing.T) {
+	// Skip if Redis is not available
+	client := redis.NewClient(&redis.Options{
+		Addr:     redisAddr,
+		Password: "",
+		DB:       0,
+	})
+
+	ctx := context.Background()
+	_, err := client.Ping(ctx).Result()
+	if err != nil {
+		t.Skip("Redis not available, skipping test")
+	}
+	defer client.Close()
+
+	cr, err := clock.NewClockRunnerWithRedis(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create clock runner: %v", err)
+	}
+	defer cr.Close()
+	cr.Stop()
+	defer cr.SetSchedule(clock.NewSessionManager().GetSchedule())
+
+	specs, err := clock.ParseScheduling("W:50-SB:10-W-LB:30")
+	if err != nil {
+		t.Fatalf("Failed to parse scheduling: %v", err)
+	}
+	if err := cr.SetScheduleSpecs(specs); err != nil {
+		t.Fatalf("Failed to set schedule: %v", err)
+	}
+
+	restarted, err := clock.NewClockRunnerWithRedis(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create clock runner: %v", err)
+	}
+	defer restarted.Close()
+
+	if scheduling := clock.FormatSchedulingSpecs(restarted.GetScheduleSpecs()); scheduling != "W:50-SB:10-W-LB:30" {
+		t.Errorf("Expected schedule W:50-SB:10-W-LB:30 after a restart, got %s", scheduling)
+	}
+	if _, _, _, duration := restarted.GetSessionInfo(); duration != 50*time.Minute {
+		t.Errorf("Expected the first session to last 50m after a restart, got %v", duration)
+	}
+	if _, _, _, duration := restarted.GetSessionInfoAt(2); duration != 25*time.Minute {
+		t.Errorf("Expected the session without an override to last 25m, got %v", duration)
+	}
+	if _, _, _, duration := restarted.GetSessionInfoAt(3); duration != 30*time.Minute {
+		t.Errorf("Expected the long break to last 30m after a restart, got %v", duration)
+	}
+}
+
+func BenchmarkClockRunnerRedisSaves(b *testing.B) {
+	// Skip if Redis is not available
+	client := redis.NewClient(&redis.Options{Addr: redisAddr})
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		b.Skip("Redis not available, skipping benchmark")
+	}
+	client.Close()
+
+	for _, window := range []time.Duration{0, 100 * time.Millisecond} {
+		b.Run("window="+window.String(), func(b *testing.B) {
+			cr, err := clock.NewClockRunnerWithRedis(redisAddr, clock.WithStateSaveWindow(window))
+			if err != nil {
+				b.Fatalf("Failed to create clock runner: %v", err)
+			}
+			defer cr.Close()
+			cr.Stop()
+
+			saves := cr.GetRedisPersistence().SystemStateSaves()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cr.Start()
+				cr.Pause()
+				cr.Start()
+				cr.Stop()
+			}
+			b.StopTimer()
+			b.ReportMetric(float64(cr.GetRedisPersistence().SystemStateSaves()-saves)/float64(b.N), "saves/op")
+		})
+	}
+}
+
+func TestBehaviorSettingsPersist(t *testing.T) {
+	// Skip if Redis is not available
+	client := redis.NewClient(&redis.Options{
+		Addr:     redisAddr,
+		Password: "",
+		DB:       0,
+	})
+
+	ctx := context.Background()
+	_, err := client.Ping(ctx).Result()
+	if err != nil {
+		t.Skip("Redis not available, skipping test")
+	}
+	defer client.Close()
+	defer client.Del(ctx, "behaviorSettings")
+
+	cr, err := clock.NewClockRunnerWithRedis(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create clock runner: %v", err)
+	}
+	defer cr.Close()
+	cr.Stop()
+	// Leave the defaults behind for the other tests
+	defer cr.SetBehavior(clock.BehaviorSettings{
+		AutoStartNext:  true,
+		RepeatWindow:   clock.DefaultRepeatWindow,
+		UnwatchedGrace: clock.DefaultUnwatchedGrace,
+	})
+	original := cr.GetBehavior()
+
+	if _, err := cr.SetBehavior(clock.BehaviorSettings{RepeatWindow: 2 * time.Hour}); !errors.Is(err, clock.ErrInvalidBehavior) {
+		t.Errorf("Expected ErrInvalidBehavior for a repeat window over the cap, got %v", err)
+	}
+	if cr.GetBehavior() != original {
+		t.Errorf("Expected an invalid update to change nothing, got %+v", cr.GetBehavior())
+	}
+
+	behavior := clock.BehaviorSettings{
+		AutoStartNext: false,
+		StrictResume:  true,
+		IdleTimeout:   30 * time.Minute,
+		RepeatWindow:  90 * time.Second,
+
+		PauseWhenUnwatched: true,
+		UnwatchedGrace:     time.Minute,
+	}
+	effective, err := cr.SetBehavior(behavior)
+	if err != nil {
+		t.Fatalf("Failed to set behavior: %v", err)
+	}
+	if effective != behavior {
+		t.Errorf("Expected effective settings %+v, got %+v", behavior, effective)
+	}
+
+	// A new runner loads the stored settings at startup...
+	restarted, err := clock.NewClockRunnerWithRedis(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create clock runner: %v", err)
+	}
+	defer restarted.Close()
+	if got := restarted.GetBehavior(); got != behavior {
+		t.Errorf("Expected the stored settings %+v after a restart, got %+v", behavior, got)
+	}
+
+	// ...unless an option fixes strict resume
+	fixed, err := clock.NewClockRunnerWithRedis(redisAddr, clock.WithStrictResume(false))
+	if err != nil {
+		t.Fatalf("Failed to create clock runner: %v", err)
+	}
+	defer fixed.Close()
+	if fixed.GetBehavior().StrictResume {
+		t.Error("Expected WithStrictResume(false) to override the stored strict resume")
+	}
+	if got, _ := fixed.SetBehavior(behavior); got.StrictResume {
+		t.Error("Expected SetBehavior to keep the strict resume fixed by the option")
+	}
+
+	// The option is not saved over the stored setting
+	redisPersistence, err := clock.NewRedisPersistence(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create Redis persistence: %v", err)
+	}
+	defer redisPersistence.Close()
+	stored, err := redisPersistence.LoadBehaviorSettings()
+	if err != nil || stored == nil || !stored.StrictResume {
+		t.Errorf("Expected the stored strict resume to stay true, got %+v and %v", stored, err)
+	}
+}
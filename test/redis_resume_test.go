@@ -77,38 +77,35 @@ func TestRedisResumeFunctionality(t *testing.T) {
 
 	// Test 2: Resume paused session
 	t.Run("ResumePausedSession", func(t *testing.T) {
-		redisPersistence, err := clock.NewRedisPersistence(redisAddr)
+		// Pause a real session, so the state is saved the way the server saves it
+		cr, err := clock.NewClockRunnerWithRedis(redisAddr)
 		if err != nil {
-			t.Fatalf("Failed to create Redis persistence: %v", err)
+			t.Fatalf("Failed to create clock runner: %v", err)
 		}
-		defer redisPersistence.Close()
-
-		// Save a paused session state
-		state := &clock.SystemState{
-			CurrentSession: 2,
-			EndTime:        time.Now().Add(5 * time.Minute), // 5 minutes from now
-			Timezone:       time.Now().Location().String(),
-			State:          string(clock.StateShortBreak),
-			TimeRemaining:  300, // 5 minutes in seconds
-			IsRunning:      false,
-			IsPaused:       true,
+		cr.Stop()
+		if _, err := cr.Start(); err != nil {
+			t.Fatalf("Failed to start: %v", err)
 		}
-
-		err = redisPersistence.SaveSystemState(state)
-		if err != nil {
-			t.Fatalf("Failed to save state: %v", err)
+		if _, err := cr.PauseWithReason("lunch"); err != nil {
+			t.Fatalf("Failed to pause: %v", err)
 		}
+		pausedRemaining := cr.GetTimeRemaining()
+		cr.Close()
+
+		// Wait long enough that a session still counting down would show it
+		time.Sleep(50 * time.Millisecond)
 
 		// Create clock runner with Redis
-		cr, err := clock.NewClockRunnerWithRedis(redisAddr)
+		cr, err = clock.NewClockRunnerWithRedis(redisAddr)
 		if err != nil {
 			t.Fatalf("Failed to create clock runner: %v", err)
 		}
 		defer cr.Close()
+		defer cr.Stop()
 
 		// Verify the state was resumed
-		if cr.GetCurrentSession() != 2 {
-			t.Errorf("Expected current session 2, got %d", cr.GetCurrentSession())
+		if cr.GetCurrentSession() != 0 {
+			t.Errorf("Expected current session 0, got %d", cr.GetCurrentSession())
 		}
 
 		if cr.GetState() != clock.StatePaused {
@@ -119,6 +116,22 @@ func TestRedisResumeFunctionality(t *testing.T) {
 			t.Error("Expected clock to be paused")
 		}
 
+		if reason := cr.GetPauseReason(); reason != "lunch" {
+			t.Errorf("Expected pause reason lunch, got %q", reason)
+		}
+
+		if diff := pausedRemaining - cr.GetTimeRemaining(); diff < 0 || diff > time.Millisecond {
+			t.Errorf("Expected %v remaining as when paused, got %v", pausedRemaining, cr.GetTimeRemaining())
+		}
+
+		// Resuming continues the paused work session
+		if _, err := cr.Start(); err != nil {
+			t.Fatalf("Failed to resume: %v", err)
+		}
+		if cr.GetState() != clock.StateWorking {
+			t.Errorf("Expected state working after resuming, got %s", cr.GetState())
+		}
+
 		log.Println("Resume paused session test passed")
 	})
 
@@ -130,10 +143,11 @@ func TestRedisResumeFunctionality(t *testing.T) {
 		}
 		defer redisPersistence.Close()
 
-		// Save a state with end time in the past (server was offline too long)
+		// Save a state with end time in the past (server was offline longer
+		// than the 100 minutes left in the cycle)
 		state := &clock.SystemState{
 			CurrentSession: 1,
-			EndTime:        time.Now().Add(-1 * time.Hour), // 1 hour ago
+			EndTime:        time.Now().Add(-2 * time.Hour), // 2 hours ago
 			Timezone:       time.Now().Location().String(),
 			State:          string(clock.StateWorking),
 			TimeRemaining:  600,
@@ -217,6 +231,225 @@ func TestRedisResumeFunctionality(t *testing.T) {
 	})
 }
 
+func TestResumeAfterSessionEndedDuringRestart(t *testing.T) {
+	// Skip if Redis is not available
+	client := redis.NewClient(&redis.Options{
+		Addr:     redisAddr,
+		Password: "",
+		DB:       0,
+	})
+
+	ctx := context.Background()
+	_, err := client.Ping(ctx).Result()
+	if err != nil {
+		t.Skip("Redis not available, skipping test")
+	}
+	defer client.Close()
+
+	tests := []struct {
+		name            string
+		overdue         time.Duration
+		policy          clock.ResumePolicy
+		expectedState   clock.ClockState
+		expectedSession int
+	}{
+		{"down 2 seconds past end", 2 * time.Second, clock.ResumeAuto, clock.StateShortBreak, 1},
+		{"down 2 seconds past end, paused policy", 2 * time.Second, clock.ResumePaused, clock.StatePaused, 1},
+		{"down past the rest of the cycle", 2 * time.Hour, clock.ResumeAuto, clock.StateIdle, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redisPersistence, err := clock.NewRedisPersistence(redisAddr)
+			if err != nil {
+				t.Fatalf("Failed to create Redis persistence: %v", err)
+			}
+			defer redisPersistence.Close()
+
+			// A work session that should have completed while the server was down
+			err = redisPersistence.SaveSystemState(&clock.SystemState{
+				CurrentSession: 0,
+				EndTime:        time.Now().Add(-tt.overdue),
+				Timezone:       time.Now().Location().String(),
+				State:          string(clock.StateWorking),
+				IsRunning:      true,
+			})
+			if err != nil {
+				t.Fatalf("Failed to save state: %v", err)
+			}
+
+			cr, err := clock.NewClockRunnerWithRedis(redisAddr,
+				clock.WithResumePolicy(tt.policy),
+				clock.WithResumeGracePeriod(time.Minute))
+			if err != nil {
+				t.Fatalf("Failed to create clock runner: %v", err)
+			}
+			defer cr.Close()
+			defer cr.Stop()
+
+			if cr.GetState() != tt.expectedState {
+				t.Errorf("Expected state %s, got %s", tt.expectedState, cr.GetState())
+			}
+			if cr.GetCurrentSession() != tt.expectedSession {
+				t.Errorf("Expected session %d, got %d", tt.expectedSession, cr.GetCurrentSession())
+			}
+		})
+	}
+}
+
+func TestResumeCatchesUpAfterLongOutage(t *testing.T) {
+	// Skip if Redis is not available
+	client := redis.NewClient(&redis.Options{
+		Addr:     redisAddr,
+		Password: "",
+		DB:       0,
+	})
+
+	ctx := context.Background()
+	_, err := client.Ping(ctx).Result()
+	if err != nil {
+		t.Skip("Redis not available, skipping test")
+	}
+	defer client.Close()
+
+	redisPersistence, err := clock.NewRedisPersistence(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create Redis persistence: %v", err)
+	}
+	defer redisPersistence.Close()
+
+	// Only the sessions recorded here should be restored into the history
+	if _, err := redisPersistence.DeleteSessionStatistics(); err != nil {
+		t.Fatalf("Failed to clear session statistics: %v", err)
+	}
+
+	// With the default 25m/5m durations, W (ended 32m ago), SB (27m ago) and
+	// W (2m ago) all elapsed; the SB after them has 3 minutes left. The rest
+	// of the cycle outlasts the outage, so the default options catch up.
+	workEnd := time.Now().Add(-32 * time.Minute).Truncate(time.Millisecond)
+	err = redisPersistence.SaveSystemState(&clock.SystemState{
+		CurrentSession: 0,
+		EndTime:        workEnd,
+		Timezone:       time.Now().Location().String(),
+		State:          string(clock.StateWorking),
+		IsRunning:      true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	cr, err := clock.NewClockRunnerWithRedis(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create clock runner: %v", err)
+	}
+	defer cr.Close()
+	defer cr.Stop()
+
+	if cr.GetState() != clock.StateShortBreak || cr.GetCurrentSession() != 3 {
+		t.Fatalf("Expected short break in session 3, got %s in session %d", cr.GetState(), cr.GetCurrentSession())
+	}
+	if remaining := cr.GetTimeRemaining(); remaining > 3*time.Minute || remaining < 2*time.Minute {
+		t.Errorf("Expected about 3m remaining, got %v", remaining)
+	}
+
+	history := cr.GetSessionHistory()
+	expected := []clock.ClockState{clock.StateWorking, clock.StateShortBreak, clock.StateWorking}
+	if len(history) != len(expected) {
+		t.Fatalf("Expected %d recorded sessions, got %d", len(expected), len(history))
+	}
+	ends := []time.Time{workEnd, workEnd.Add(5 * time.Minute), workEnd.Add(30 * time.Minute)}
+	for i, record := range history {
+		if record.State != expected[i] {
+			t.Errorf("Record %d: expected %s, got %s", i, expected[i], record.State)
+		}
+		if !record.Completed.Equal(ends[i]) {
+			t.Errorf("Record %d: expected completion at %v, got %v", i, ends[i], record.Completed)
+		}
+	}
+}
+
+func TestStrictResume(t *testing.T) {
+	// Skip if Redis is not available
+	client := redis.NewClient(&redis.Options{
+		Addr:     redisAddr,
+		Password: "",
+		DB:       0,
+	})
+
+	ctx := context.Background()
+	_, err := client.Ping(ctx).Result()
+	if err != nil {
+		t.Skip("Redis not available, skipping test")
+	}
+	defer client.Close()
+
+	tests := []struct {
+		name            string
+		strict          bool
+		expectedState   clock.ClockState
+		expectedSession int
+		expectedRecords int
+	}{
+		{"catch-up", false, clock.StateShortBreak, 3, 3},
+		{"strict", true, clock.StateIdle, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redisPersistence, err := clock.NewRedisPersistence(redisAddr)
+			if err != nil {
+				t.Fatalf("Failed to create Redis persistence: %v", err)
+			}
+			defer redisPersistence.Close()
+
+			// Only the sessions recorded here should be restored into the history
+			if _, err := redisPersistence.DeleteSessionStatistics(); err != nil {
+				t.Fatalf("Failed to clear session statistics: %v", err)
+			}
+
+			// W, SB and W elapsed during the outage, as in TestResumeCatchesUpAfterLongOutage
+			err = redisPersistence.SaveSystemState(&clock.SystemState{
+				CurrentSession: 0,
+				EndTime:        time.Now().Add(-32 * time.Minute),
+				Timezone:       time.Now().Location().String(),
+				State:          string(clock.StateWorking),
+				IsRunning:      true,
+			})
+			if err != nil {
+				t.Fatalf("Failed to save state: %v", err)
+			}
+			before, err := redisPersistence.LoadSessionStatistics()
+			if err != nil {
+				t.Fatalf("Failed to load statistics: %v", err)
+			}
+
+			cr, err := clock.NewClockRunnerWithRedis(redisAddr, clock.WithStrictResume(tt.strict))
+			if err != nil {
+				t.Fatalf("Failed to create clock runner: %v", err)
+			}
+			defer cr.Close()
+			defer cr.Stop()
+
+			if cr.GetState() != tt.expectedState || cr.GetCurrentSession() != tt.expectedSession {
+				t.Errorf("Expected %s in session %d, got %s in session %d",
+					tt.expectedState, tt.expectedSession, cr.GetState(), cr.GetCurrentSession())
+			}
+			if n := len(cr.GetSessionHistory()); n != tt.expectedRecords {
+				t.Errorf("Expected %d recorded sessions, got %d", tt.expectedRecords, n)
+			}
+
+			after, err := redisPersistence.LoadSessionStatistics()
+			if err != nil {
+				t.Fatalf("Failed to load statistics: %v", err)
+			}
+			if tt.strict && after.WorkSessions != before.WorkSessions {
+				t.Errorf("Expected strict resume to leave persisted work sessions at %d, got %d",
+					before.WorkSessions, after.WorkSessions)
+			}
+		})
+	}
+}
+
 func TestSessionManagerSetCurrentSession(t *testing.T) {
 	sm := clock.NewSessionManager()
 
@@ -241,3 +474,204 @@ func TestSessionManagerSetCurrentSession(t *testing.T) {
 
 	log.Println("SessionManager SetCurrentSession test passed")
 }
+
+func TestResumePolicy(t *testing.T) {
+	// Skip if Redis is not available
+	client := redis.NewClient(&redis.Options{
+		Addr:     redisAddr,
+		Password: "",
+		DB:       0,
+	})
+
+	ctx := context.Background()
+	_, err := client.Ping(ctx).Result()
+	if err != nil {
+		t.Skip("Redis not available, skipping test")
+	}
+	defer client.Close()
+
+	if _, err := clock.ParseResumePolicy("sometimes"); err == nil {
+		t.Error("Expected an unknown resume policy to be rejected")
+	}
+
+	tests := []struct {
+		policy        clock.ResumePolicy
+		expectedState clock.ClockState
+	}{
+		{clock.ResumeAuto, clock.StateWorking},
+		{clock.ResumePaused, clock.StatePaused},
+		{clock.ResumeIdle, clock.StateIdle},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			redisPersistence, err := clock.NewRedisPersistence(redisAddr)
+			if err != nil {
+				t.Fatalf("Failed to create Redis persistence: %v", err)
+			}
+			defer redisPersistence.Close()
+
+			// Save a running session state
+			err = redisPersistence.SaveSystemState(&clock.SystemState{
+				CurrentSession: 0,
+				EndTime:        time.Now().Add(10 * time.Minute),
+				Timezone:       time.Now().Location().String(),
+				State:          string(clock.StateWorking),
+				TimeRemaining:  600000,
+				IsRunning:      true,
+			})
+			if err != nil {
+				t.Fatalf("Failed to save state: %v", err)
+			}
+
+			cr, err := clock.NewClockRunnerWithRedis(redisAddr, clock.WithResumePolicy(tt.policy))
+			if err != nil {
+				t.Fatalf("Failed to create clock runner: %v", err)
+			}
+			defer cr.Close()
+			defer cr.Stop()
+
+			if cr.GetState() != tt.expectedState {
+				t.Errorf("Expected state %s, got %s", tt.expectedState, cr.GetState())
+			}
+			if tt.policy == clock.ResumePaused {
+				// Resuming must continue from the saved remaining time
+				result, err := cr.Start()
+				if err != nil {
+					t.Fatalf("Failed to resume paused session: %v", err)
+				}
+				if result.State != clock.StateWorking || result.TimeRemaining < 9*time.Minute {
+					t.Errorf("Expected working with about 10m remaining, got %s with %v", result.State, result.TimeRemaining)
+				}
+			}
+		})
+	}
+}
+
+func TestResumeWaitingSession(t *testing.T) {
+	// Skip if Redis is not available
+	client := redis.NewClient(&redis.Options{
+		Addr:     redisAddr,
+		Password: "",
+		DB:       0,
+	})
+
+	ctx := context.Background()
+	_, err := client.Ping(ctx).Result()
+	if err != nil {
+		t.Skip("Redis not available, skipping test")
+	}
+	defer client.Close()
+
+	redisPersistence, err := clock.NewRedisPersistence(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create Redis persistence: %v", err)
+	}
+	defer redisPersistence.Close()
+
+	// The work session completed and the short break is waiting to be started
+	err = redisPersistence.SaveSystemState(&clock.SystemState{
+		CurrentSession: 1,
+		EndTime:        time.Now().Add(5 * time.Minute),
+		Timezone:       time.Now().Location().String(),
+		State:          string(clock.StateWaiting),
+		TimeRemaining:  300000,
+	})
+	if err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	cr, err := clock.NewClockRunnerWithRedis(redisAddr)
+	if err != nil {
+		t.Fatalf("Failed to create clock runner: %v", err)
+	}
+	defer cr.Close()
+	defer cr.Stop()
+
+	if cr.GetState() != clock.StateWaiting || cr.GetCurrentSession() != 1 {
+		t.Fatalf("Expected to wait in session 1, got %s in session %d", cr.GetState(), cr.GetCurrentSession())
+	}
+
+	// Starting begins the waiting session rather than a new cycle
+	result, err := cr.Start()
+	if err != nil {
+		t.Fatalf("Failed to start the waiting session: %v", err)
+	}
+	if result.State != clock.StateShortBreak || result.Session != 1 {
+		t.Errorf("Expected the short break in session 1, got %s in session %d", result.State, result.Session)
+	}
+}
+
+func TestResumeDoesNotRecordSessionTwice(t *testing.T) {
+	// Skip if Redis is not available
+	client := redis.NewClient(&redis.Options{
+		Addr:     redisAddr,
+		Password: "",
+		DB:       0,
+	})
+
+	ctx := context.Background()
+	_, err := client.Ping(ctx).Result()
+	if err != nil {
+		t.Skip("Redis not available, skipping test")
+	}
+	defer client.Close()
+
+	tests := []struct {
+		name        string
+		window      time.Duration
+		newSessions int
+	}{
+		{"completion already recorded", clock.DefaultDuplicateRecordWindow, 0},
+		{"duplicate check disabled", 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redisPersistence, err := clock.NewRedisPersistence(redisAddr)
+			if err != nil {
+				t.Fatalf("Failed to create Redis persistence: %v", err)
+			}
+			defer redisPersistence.Close()
+
+			// The work session completed and was recorded, but the server
+			// stopped before it saved the state that moved past it
+			endTime := time.Now().Add(-2 * time.Second)
+			err = redisPersistence.SaveRecordedSession(
+				clock.RecordedSession{Session: 0, State: clock.StateWorking, Completed: endTime.Add(50 * time.Millisecond)},
+				clock.SessionRecord{State: clock.StateWorking, Duration: 25 * time.Minute, Completed: endTime.Add(50 * time.Millisecond)})
+			if err != nil {
+				t.Fatalf("Failed to save recorded session: %v", err)
+			}
+			err = redisPersistence.SaveSystemState(&clock.SystemState{
+				CurrentSession: 0,
+				EndTime:        endTime,
+				Timezone:       time.Now().Location().String(),
+				State:          string(clock.StateWorking),
+				IsRunning:      true,
+			})
+			if err != nil {
+				t.Fatalf("Failed to save state: %v", err)
+			}
+
+			persisted, err := redisPersistence.LoadSessionStatistics()
+			if err != nil {
+				t.Fatalf("Failed to load statistics: %v", err)
+			}
+
+			cr, err := clock.NewClockRunnerWithRedis(redisAddr, clock.WithDuplicateRecordWindow(tt.window))
+			if err != nil {
+				t.Fatalf("Failed to create clock runner: %v", err)
+			}
+			defer cr.Close()
+			defer cr.Stop()
+
+			if cr.GetState() != clock.StateShortBreak || cr.GetCurrentSession() != 1 {
+				t.Errorf("Expected to move on to the short break, got %s in session %d", cr.GetState(), cr.GetCurrentSession())
+			}
+			if work, _, _ := cr.GetStatistics(); work != persisted.WorkSessions+tt.newSessions {
+				t.Errorf("Expected %d work sessions, got %d", persisted.WorkSessions+tt.newSessions, work)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"pomodoroService/internal/auth"
+)
+
+func TestParseRoleRejectsUnknownRoles(t *testing.T) {
+	for _, role := range auth.ValidRoles() {
+		if got, err := auth.ParseRole(role); err != nil || got != role {
+			t.Errorf("Expected %s to be valid, got %q, %v", role, got, err)
+		}
+	}
+	for _, role := range []string{"", "admin", "SUPERUSER", " USER"} {
+		if _, err := auth.ParseRole(role); !errors.Is(err, auth.ErrInvalidRole) {
+			t.Errorf("Expected ErrInvalidRole for %q, got %v", role, err)
+		}
+	}
+}
+
+func TestDefaultRoleIsConfigurable(t *testing.T) {
+	t.Cleanup(func() { auth.SetDefaultRole(auth.DefaultUserRole) })
+
+	req := &auth.NewUserRequest{Username: "alice", Password: "Password1!", Email: "alice@example.com"}
+	if role := *auth.NewUserFromRequest(req).Role; role != "USER" {
+		t.Errorf("Expected new users to get USER by default, got %s", role)
+	}
+
+	t.Setenv("DEFAULT_USER_ROLE", "ADMIN")
+	if err := auth.LoadDefaultRole(); err != nil {
+		t.Fatalf("LoadDefaultRole failed: %v", err)
+	}
+	if role := *auth.NewUserFromRequest(req).Role; role != "ADMIN" {
+		t.Errorf("Expected new users to get the configured ADMIN role, got %s", role)
+	}
+
+	// An unknown role is an error and leaves the default alone
+	t.Setenv("DEFAULT_USER_ROLE", "superuser")
+	if err := auth.LoadDefaultRole(); !errors.Is(err, auth.ErrInvalidRole) {
+		t.Errorf("Expected ErrInvalidRole for an unknown DEFAULT_USER_ROLE, got %v", err)
+	}
+	if role := auth.GetDefaultRole(); role != "ADMIN" {
+		t.Errorf("Expected the default role to stay ADMIN, got %s", role)
+	}
+}
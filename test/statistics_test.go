@@ -0,0 +1,479 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"pomodoroService/internal/clock"
+)
+
+func TestTodaySessionsRespectTimezone(t *testing.T) {
+	// 23:30 UTC on Jan 15 is 18:30 on Jan 15 in UTC-5
+	completedAt := time.Date(2025, 1, 15, 23, 30, 0, 0, time.UTC)
+	now := time.Date(2025, 1, 16, 1, 0, 0, 0, time.UTC)
+
+	sm := clock.NewStatisticsManager()
+	sm.SetNowFunc(func() time.Time { return now })
+	sm.RecordSessionAt(clock.StateWorking, 25*time.Minute, completedAt)
+
+	sm.SetLocation(time.UTC)
+	if today := sm.GetTodaySessions(); len(today) != 0 {
+		t.Errorf("Expected no sessions today in UTC, got %d", len(today))
+	}
+
+	sm.SetLocation(time.FixedZone("UTC-5", -5*60*60))
+	if today := sm.GetTodaySessions(); len(today) != 1 {
+		t.Errorf("Expected 1 session today in UTC-5, got %d", len(today))
+	}
+}
+
+func TestClockRunnerLocation(t *testing.T) {
+	cr := NewShortDurationClockRunner()
+
+	if cr.GetLocation() != time.Local {
+		t.Errorf("Expected default location to be local, got %s", cr.GetLocation())
+	}
+
+	loc := time.FixedZone("UTC+9", 9*60*60)
+	cr.SetLocation(loc)
+	if cr.GetLocation() != loc {
+		t.Errorf("Expected location to be %s, got %s", loc, cr.GetLocation())
+	}
+}
+
+func TestTodaySessionsLateNight(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	completedAt := time.Date(2025, 1, 15, 23, 30, 0, 0, loc)
+
+	sm := clock.NewStatisticsManager()
+	sm.SetLocation(loc)
+	sm.RecordSessionAt(clock.StateWorking, 25*time.Minute, completedAt)
+
+	// Later the same evening the session belongs to today
+	sm.SetNowFunc(func() time.Time { return time.Date(2025, 1, 15, 23, 45, 0, 0, loc) })
+	if today := sm.GetTodaySessions(); len(today) != 1 {
+		t.Errorf("Expected session at 23:30 to count for the same day, got %d sessions", len(today))
+	}
+
+	// After local midnight it belongs to yesterday
+	sm.SetNowFunc(func() time.Time { return time.Date(2025, 1, 16, 0, 30, 0, 0, loc) })
+	if today := sm.GetTodaySessions(); len(today) != 0 {
+		t.Errorf("Expected session at 23:30 to count for the previous day, got %d sessions", len(today))
+	}
+
+	// With a 4am day start, 00:30 is still part of the previous day
+	if err := sm.SetDayStart(4 * time.Hour); err != nil {
+		t.Fatalf("Failed to set day start: %v", err)
+	}
+	if today := sm.GetTodaySessions(); len(today) != 1 {
+		t.Errorf("Expected session to count for the current day with a 4am boundary, got %d sessions", len(today))
+	}
+
+	if err := sm.SetDayStart(24 * time.Hour); err == nil {
+		t.Error("Expected error for a day start of 24h")
+	}
+}
+
+func TestDailyGoalProgressAcrossDayBoundary(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	now := time.Date(2025, 1, 15, 23, 0, 0, 0, loc)
+
+	sm := clock.NewStatisticsManager()
+	sm.SetLocation(loc)
+	sm.SetNowFunc(func() time.Time { return now })
+	if err := sm.SetDayStart(4 * time.Hour); err != nil {
+		t.Fatalf("Failed to set day start: %v", err)
+	}
+
+	if err := sm.SetDailyWorkGoal(-1); err == nil {
+		t.Error("Expected error for a negative daily work goal")
+	}
+	if progress := sm.GetDailyGoalProgress(); progress.Percent != 0 || progress.Reached {
+		t.Errorf("Expected no progress without a goal, got %+v", progress)
+	}
+	if err := sm.SetDailyWorkGoal(2); err != nil {
+		t.Fatalf("Failed to set daily work goal: %v", err)
+	}
+
+	sm.RecordSessionAt(clock.StateWorking, 25*time.Minute, now.Add(-time.Hour))
+	sm.RecordSessionAt(clock.StateShortBreak, 5*time.Minute, now.Add(-30*time.Minute))
+	progress := sm.GetDailyGoalProgress()
+	if progress.Completed != 1 || progress.Goal != 2 || progress.Percent != 50 || progress.Reached {
+		t.Errorf("Expected 1 of 2 work sessions at 50%%, got %+v", progress)
+	}
+	if _, reached := sm.MarkDailyGoalReached(); reached {
+		t.Error("Expected the goal not to be reached after 1 work session")
+	}
+
+	sm.RecordSessionAt(clock.StateWorking, 25*time.Minute, now)
+	if _, reached := sm.MarkDailyGoalReached(); !reached {
+		t.Error("Expected the goal to be reached after 2 work sessions")
+	}
+	sm.RecordSessionAt(clock.StateWorking, 25*time.Minute, now)
+	if progress, reached := sm.MarkDailyGoalReached(); reached || progress.Percent != 100 {
+		t.Errorf("Expected the goal to be reported once per day at 100%%, got reached %v at %v%%", reached, progress.Percent)
+	}
+
+	// 02:00 the next morning is still the same day with a 4am boundary
+	now = time.Date(2025, 1, 16, 2, 0, 0, 0, loc)
+	if progress := sm.GetDailyGoalProgress(); progress.Completed != 3 {
+		t.Errorf("Expected 3 work sessions before the day boundary, got %d", progress.Completed)
+	}
+
+	// After 04:00 the count and the reached flag start over
+	now = time.Date(2025, 1, 16, 5, 0, 0, 0, loc)
+	if progress := sm.GetDailyGoalProgress(); progress.Completed != 0 || progress.Reached {
+		t.Errorf("Expected no progress after the day boundary, got %+v", progress)
+	}
+	sm.RecordSessionAt(clock.StateWorking, 25*time.Minute, now)
+	sm.RecordSessionAt(clock.StateWorking, 25*time.Minute, now)
+	if _, reached := sm.MarkDailyGoalReached(); !reached {
+		t.Error("Expected the goal to be reached again on the next day")
+	}
+}
+
+func TestGetStatsForRange(t *testing.T) {
+	monday := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+
+	sm := clock.NewStatisticsManager()
+	sm.RecordSessionAt(clock.StateWorking, 25*time.Minute, monday.Add(-time.Hour))
+	sm.RecordSessionAt(clock.StateWorking, 25*time.Minute, monday)
+	sm.RecordSessionAt(clock.StateShortBreak, 5*time.Minute, monday.Add(30*time.Minute))
+	sm.RecordSessionAt(clock.StateCustomBreak, 10*time.Minute, monday.Add(time.Hour))
+	sm.RecordSessionAt(clock.StateLongBreak, 15*time.Minute, monday.AddDate(0, 0, 6))
+	sm.RecordSessionAt(clock.StateWorking, 25*time.Minute, monday.AddDate(0, 0, 7))
+
+	// The window includes its start and excludes its end
+	stats, err := sm.GetStatsForRange(monday, monday.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatalf("Failed to get stats for range: %v", err)
+	}
+	if stats.WorkSessions != 1 || stats.ShortBreaks != 1 || stats.LongBreaks != 1 || stats.CustomBreaks != 1 {
+		t.Errorf("Expected 1 session of each type in the week, got %+v", stats)
+	}
+	if stats.WorkTime != 25*time.Minute || stats.BreakTime != 20*time.Minute || stats.TotalTime != 45*time.Minute {
+		t.Errorf("Expected 25m work and 20m break, custom breaks excluded, got %v, %v and %v total",
+			stats.WorkTime, stats.BreakTime, stats.TotalTime)
+	}
+	if len(stats.History) != 4 {
+		t.Errorf("Expected the 4 sessions in the window, got %d", len(stats.History))
+	}
+
+	if stats, err := sm.GetStatsForRange(monday, monday); err != nil || len(stats.History) != 0 {
+		t.Errorf("Expected an empty window to count nothing, got %d sessions and %v", len(stats.History), err)
+	}
+	if _, err := sm.GetStatsForRange(monday, monday.Add(-time.Second)); !errors.Is(err, clock.ErrInvalidStatsRange) {
+		t.Errorf("Expected ErrInvalidStatsRange for a reversed window, got %v", err)
+	}
+	if _, err := sm.GetStatsForRange(monday, monday.Add(clock.MaxStatsRange+time.Second)); !errors.Is(err, clock.ErrInvalidStatsRange) {
+		t.Errorf("Expected ErrInvalidStatsRange for a window over the cap, got %v", err)
+	}
+}
+
+func TestImportSessions(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	sm := clock.NewStatisticsManager()
+	sm.SetNowFunc(func() time.Time { return now })
+	sm.RecordSessionAt(clock.StateWorking, 25*time.Minute, now.Add(-time.Hour))
+
+	imported := []clock.SessionRecord{
+		{State: clock.StateWorking, Duration: 50 * time.Minute, Completed: now.AddDate(0, 0, -3)},
+		{State: clock.StateShortBreak, Duration: 10 * time.Minute, Completed: now.AddDate(0, 0, -3).Add(10 * time.Minute)},
+		{State: clock.StateLongBreak, Duration: 30 * time.Minute, Completed: now.AddDate(0, 0, -2)},
+		{State: clock.StateCustomBreak, Duration: 5 * time.Minute, Completed: now.AddDate(0, 0, -1)},
+	}
+	if err := sm.ImportSessions(imported); err != nil {
+		t.Fatalf("Failed to import sessions: %v", err)
+	}
+
+	stats := sm.Snapshot()
+	if stats.WorkSessions != 2 || stats.ShortBreaks != 1 || stats.LongBreaks != 1 || stats.CustomBreaks != 1 {
+		t.Errorf("Expected the imported sessions in the counts, got %+v", stats)
+	}
+	if stats.WorkTime != 75*time.Minute || stats.BreakTime != 40*time.Minute || stats.TotalTime != 115*time.Minute {
+		t.Errorf("Expected 75m work and 40m break, got %v, %v and %v total", stats.WorkTime, stats.BreakTime, stats.TotalTime)
+	}
+	if stats.CustomBreakTime != 5*time.Minute {
+		t.Errorf("Expected 5m of custom breaks, got %v", stats.CustomBreakTime)
+	}
+	// The imported sessions are older, so they come first in the history
+	if len(stats.History) != 5 || !stats.History[0].Completed.Equal(imported[0].Completed) ||
+		!stats.History[4].Completed.Equal(now.Add(-time.Hour)) {
+		t.Errorf("Expected the history ordered by completion time, got %+v", stats.History)
+	}
+
+	invalid := map[string]clock.SessionRecord{
+		"idle state":       {State: clock.StateIdle, Duration: time.Minute, Completed: now},
+		"zero duration":    {State: clock.StateWorking, Completed: now},
+		"no completion":    {State: clock.StateWorking, Duration: time.Minute},
+		"future timestamp": {State: clock.StateWorking, Duration: time.Minute, Completed: now.Add(time.Minute)},
+	}
+	for name, record := range invalid {
+		batch := []clock.SessionRecord{imported[0], record}
+		if err := sm.ImportSessions(batch); !errors.Is(err, clock.ErrInvalidSessionRecord) {
+			t.Errorf("%s: expected ErrInvalidSessionRecord, got %v", name, err)
+		}
+	}
+	// A rejected batch imports nothing, not even its valid entries
+	if work, _, _ := sm.GetStatistics(); work != 2 {
+		t.Errorf("Expected rejected batches to leave 2 work sessions, got %d", work)
+	}
+}
+
+func TestTodayFocusTime(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	now := time.Date(2025, 1, 16, 1, 0, 0, 0, time.UTC) // 20:00 on Jan 15 in UTC-5
+
+	sm := clock.NewStatisticsManager()
+	sm.SetNowFunc(func() time.Time { return now })
+	sm.RecordSessionAt(clock.StateWorking, 25*time.Minute, time.Date(2025, 1, 15, 14, 0, 0, 0, time.UTC))
+	sm.RecordSessionAt(clock.StateShortBreak, 5*time.Minute, time.Date(2025, 1, 15, 14, 30, 0, 0, time.UTC))
+	sm.RecordSessionAt(clock.StateWorking, 50*time.Minute, time.Date(2025, 1, 15, 23, 30, 0, 0, time.UTC))
+
+	// In UTC it is already Jan 16, so nothing was completed today
+	sm.SetLocation(time.UTC)
+	if focus := sm.GetTodayFocusTime(); focus != 0 {
+		t.Errorf("Expected no focus time today in UTC, got %v", focus)
+	}
+
+	// In UTC-5 both work sessions fall on Jan 15; the break does not count
+	sm.SetLocation(loc)
+	if focus := sm.GetTodayFocusTime(); focus != 75*time.Minute {
+		t.Errorf("Expected 75m of focus time today in UTC-5, got %v", focus)
+	}
+}
+
+func TestWeeklyStatsRespectTimezone(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*60*60)
+
+	sm := clock.NewStatisticsManager()
+	sm.SetLocation(loc)
+	// Wednesday Jan 15, 2025 local time; the week starts on Sunday Jan 12
+	sm.SetNowFunc(func() time.Time { return time.Date(2025, 1, 15, 12, 0, 0, 0, loc) })
+
+	// Saturday 23:30 local belongs to the previous week even though it is Sunday in UTC
+	sm.RecordSessionAt(clock.StateWorking, 25*time.Minute, time.Date(2025, 1, 11, 23, 30, 0, 0, loc))
+	sm.RecordSessionAt(clock.StateWorking, 25*time.Minute, time.Date(2025, 1, 12, 0, 0, 0, 0, loc))
+
+	work, _, _, workTime, _ := sm.GetWeeklyStats()
+	if work != 1 {
+		t.Errorf("Expected 1 work session this week, got %d", work)
+	}
+	if workTime != 25*time.Minute {
+		t.Errorf("Expected 25m of work this week, got %v", workTime)
+	}
+}
+
+func TestStatisticsSnapshotConsistency(t *testing.T) {
+	sm := clock.NewStatisticsManager()
+	states := []clock.ClockState{clock.StateWorking, clock.StateShortBreak, clock.StateLongBreak}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				sm.RecordSession(states[(i+j)%len(states)], time.Minute)
+			}
+		}(i)
+	}
+
+	for i := 0; i < 200; i++ {
+		snapshot := sm.Snapshot()
+		total := snapshot.WorkSessions + snapshot.ShortBreaks + snapshot.LongBreaks
+		if total != len(snapshot.History) {
+			t.Fatalf("Snapshot counts (%d) do not match history length (%d)", total, len(snapshot.History))
+		}
+		if snapshot.TotalTime != time.Duration(total)*time.Minute {
+			t.Fatalf("Snapshot total time %v does not match %d sessions", snapshot.TotalTime, total)
+		}
+	}
+
+	wg.Wait()
+
+	snapshot := sm.Snapshot()
+	if len(snapshot.History) != 1000 {
+		t.Errorf("Expected 1000 recorded sessions, got %d", len(snapshot.History))
+	}
+}
+
+func TestClockRunnerResetStatistics(t *testing.T) {
+	cr := NewShortDurationClockRunner()
+	cr.SetDurations(50*time.Millisecond, 50*time.Millisecond, 50*time.Millisecond)
+
+	if _, err := cr.Start(); err != nil {
+		t.Fatalf("Failed to start clock: %v", err)
+	}
+	time.Sleep(120 * time.Millisecond)
+	cr.Stop()
+
+	if snapshot := cr.GetStatisticsSnapshot(); len(snapshot.History) == 0 {
+		t.Fatal("Expected at least one recorded session before reset")
+	}
+
+	if err := cr.ResetStatistics(); err != nil {
+		t.Fatalf("Expected reset to succeed, got %v", err)
+	}
+
+	snapshot := cr.GetStatisticsSnapshot()
+	if snapshot.WorkSessions != 0 || snapshot.ShortBreaks != 0 || snapshot.LongBreaks != 0 || len(snapshot.History) != 0 {
+		t.Errorf("Expected zeroed statistics after reset, got %+v", snapshot)
+	}
+}
+
+func TestStatisticsHydrate(t *testing.T) {
+	sm := clock.NewStatisticsManager()
+	sm.Hydrate(3, 2, 1, 75*time.Minute, 25*time.Minute, 0, 0)
+
+	work, short, long := sm.GetStatistics()
+	if work != 3 || short != 2 || long != 1 {
+		t.Errorf("Expected hydrated counts 3/2/1, got %d/%d/%d", work, short, long)
+	}
+
+	// New sessions add on top of the hydrated totals
+	sm.RecordSession(clock.StateWorking, 25*time.Minute)
+	workTime, breakTime, totalTime := sm.GetTimingStatistics()
+	if workTime != 100*time.Minute || breakTime != 25*time.Minute || totalTime != 125*time.Minute {
+		t.Errorf("Expected timing 100m/25m/125m, got %v/%v/%v", workTime, breakTime, totalTime)
+	}
+	if len(sm.GetSessionHistory()) != 1 {
+		t.Errorf("Expected history to contain only the newly recorded session, got %d", len(sm.GetSessionHistory()))
+	}
+}
+
+func TestCustomBreakStatistics(t *testing.T) {
+	sm := clock.NewStatisticsManager()
+	sm.RecordSession(clock.StateWorking, 25*time.Minute)
+	sm.RecordSession(clock.StateShortBreak, 5*time.Minute)
+	sm.RecordSession(clock.StateCustomBreak, 30*time.Minute)
+
+	work, short, long := sm.GetStatistics()
+	if work != 1 || short != 1 || long != 0 {
+		t.Errorf("Expected counts 1/1/0, got %d/%d/%d", work, short, long)
+	}
+
+	// Custom breaks stay out of the break and total timing
+	_, breakTime, totalTime := sm.GetTimingStatistics()
+	if breakTime != 5*time.Minute || totalTime != 30*time.Minute {
+		t.Errorf("Expected break 5m and total 30m, got %v/%v", breakTime, totalTime)
+	}
+
+	customBreaks, customBreakTime := sm.GetCustomBreakStatistics()
+	if customBreaks != 1 || customBreakTime != 30*time.Minute {
+		t.Errorf("Expected 1 custom break of 30m, got %d of %v", customBreaks, customBreakTime)
+	}
+
+	snapshot := sm.Snapshot()
+	if snapshot.CustomBreaks != 1 || len(snapshot.History) != 3 {
+		t.Errorf("Expected snapshot with 1 custom break and 3 history entries, got %+v", snapshot)
+	}
+}
+
+func TestPauseCount(t *testing.T) {
+	cr := clock.NewClockRunner()
+	defer cr.Close()
+
+	if _, err := cr.Start(); err != nil {
+		t.Fatalf("Failed to start clock: %v", err)
+	}
+	if _, err := cr.Pause(); err != nil {
+		t.Fatalf("Failed to pause clock: %v", err)
+	}
+	// Pausing an already paused clock fails and is not counted
+	cr.Pause()
+
+	if pauses := cr.GetStatisticsSnapshot().Pauses; pauses != 1 {
+		t.Errorf("Expected 1 pause, got %d", pauses)
+	}
+
+	cr.Stop()
+	cr.ResetStatistics()
+	if pauses := cr.GetStatisticsSnapshot().Pauses; pauses != 0 {
+		t.Errorf("Expected pauses to reset to 0, got %d", pauses)
+	}
+}
+
+func TestSessionTag(t *testing.T) {
+	cr := clock.NewClockRunner()
+	defer cr.Close()
+
+	if err := cr.SetSessionTag("report"); !errors.Is(err, clock.ErrNoActiveSession) {
+		t.Errorf("Expected ErrNoActiveSession while idle, got %v", err)
+	}
+
+	if _, err := cr.Start(); err != nil {
+		t.Fatalf("Failed to start clock: %v", err)
+	}
+	if err := cr.SetSessionTag(strings.Repeat("x", clock.MaxSessionTagLength+1)); !errors.Is(err, clock.ErrTagTooLong) {
+		t.Errorf("Expected ErrTagTooLong, got %v", err)
+	}
+	if err := cr.SetSessionTag("  write report  "); err != nil {
+		t.Fatalf("Failed to tag session: %v", err)
+	}
+	if tag := cr.GetSessionTag(); tag != "write report" {
+		t.Errorf("Expected trimmed tag %q, got %q", "write report", tag)
+	}
+
+	if _, err := cr.Skip(); err != nil {
+		t.Fatalf("Failed to skip session: %v", err)
+	}
+	history := cr.GetStatisticsSnapshot().History
+	if len(history) != 1 || history[0].Tag != "write report" {
+		t.Errorf("Expected the skipped session to be recorded with its tag, got %+v", history)
+	}
+	if tag := cr.GetSessionTag(); tag != "" {
+		t.Errorf("Expected the tag to be cleared for the next session, got %q", tag)
+	}
+	cr.Stop()
+}
+
+func TestProductivityScore(t *testing.T) {
+	sm := clock.NewStatisticsManager()
+	if score := sm.GetProductivityScore(); score != 0 {
+		t.Errorf("Expected score 0 with no sessions, got %v", score)
+	}
+	if average := sm.GetAverageSessionDuration(); average != 0 {
+		t.Errorf("Expected average 0 with no sessions, got %v", average)
+	}
+
+	// 3 work sessions and 1 of each break; the custom break is left out
+	for i := 0; i < 3; i++ {
+		sm.RecordSession(clock.StateWorking, 25*time.Minute)
+	}
+	sm.RecordSession(clock.StateShortBreak, 5*time.Minute)
+	sm.RecordSession(clock.StateLongBreak, 15*time.Minute)
+	sm.RecordSession(clock.StateCustomBreak, 60*time.Minute)
+
+	if score := sm.GetProductivityScore(); score != 60 {
+		t.Errorf("Expected score 60, got %v", score)
+	}
+	if average := sm.GetAverageSessionDuration(); average != 19*time.Minute {
+		t.Errorf("Expected average 19m, got %v", average)
+	}
+}
+
+func TestDurationDistribution(t *testing.T) {
+	sm := clock.NewStatisticsManager()
+	for _, d := range []time.Duration{10 * time.Minute, 15 * time.Minute, 25 * time.Minute, 25 * time.Minute, 30 * time.Minute, 50 * time.Minute} {
+		sm.RecordSession(clock.StateWorking, d)
+	}
+	// Breaks are not counted
+	sm.RecordSession(clock.StateShortBreak, 5*time.Minute)
+	sm.RecordSession(clock.StateLongBreak, 20*time.Minute)
+
+	expected := map[string]int{"<15m": 1, "15-25m": 3, "25-45m": 1, ">45m": 1}
+	buckets := sm.GetDurationDistribution()
+	if len(buckets) != len(expected) {
+		t.Fatalf("Expected %d buckets, got %d", len(expected), len(buckets))
+	}
+	for _, bucket := range buckets {
+		if bucket.Count != expected[bucket.Label] {
+			t.Errorf("Bucket %s: expected %d, got %d", bucket.Label, expected[bucket.Label], bucket.Count)
+		}
+	}
+}
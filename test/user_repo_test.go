@@ -0,0 +1,163 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"pomodoroService/internal/auth"
+	"pomodoroService/internal/migrate"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// newTestUserRepository connects to the Postgres in DSN and applies the
+// migrations, skipping the test when none is available
+func newTestUserRepository(t *testing.T) auth.AuthRepository {
+	t.Helper()
+	dsn := os.Getenv("DSN")
+	if dsn == "" {
+		t.Skip("DSN not set, skipping Postgres test")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err == nil {
+		err = pool.Ping(ctx)
+	}
+	if err != nil {
+		t.Skipf("Postgres not available, skipping test: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	if err := migrate.Run(ctx, pool); err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
+
+	// Hashing at the production cost would dominate the test
+	if err := auth.SetBcryptCost(4); err != nil {
+		t.Fatalf("Failed to lower bcrypt cost: %v", err)
+	}
+	t.Cleanup(func() { auth.SetBcryptCost(auth.DefaultBcryptCost) })
+
+	return auth.NewPostgresRepository(pool)
+}
+
+func TestConcurrentRegistrationCreatesOneUser(t *testing.T) {
+	repo := newTestUserRepository(t)
+
+	username := fmt.Sprintf("race%d", time.Now().UnixNano())
+	const attempts = 8
+
+	var wg sync.WaitGroup
+	users := make([]*auth.User, attempts)
+	errs := make([]error, attempts)
+	for i := range attempts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			users[i] = auth.NewUserFromRequest(&auth.NewUserRequest{
+				Username: username,
+				Password: "Password1!",
+			})
+			email := fmt.Sprintf("%s-%d@example.com", username, i)
+			users[i].Email = &email
+			errs[i] = repo.CreateUser(users[i])
+		}()
+	}
+	wg.Wait()
+
+	created := 0
+	for i, err := range errs {
+		if err == nil {
+			created++
+			t.Cleanup(func() { repo.DeleteUser(*users[i].ID) })
+			continue
+		}
+		if !errors.Is(err, auth.ErrUserExists) {
+			t.Errorf("Expected a losing registration to report the user already exists, got %v", err)
+		}
+	}
+	if created != 1 {
+		t.Errorf("Expected exactly one registration of %s to succeed, got %d", username, created)
+	}
+}
+
+func TestDuplicateUserIsReportedAsExisting(t *testing.T) {
+	repo := newTestUserRepository(t)
+
+	username := fmt.Sprintf("dup%d", time.Now().UnixNano())
+	newUser := func(name, email string) *auth.User {
+		user := auth.NewUserFromRequest(&auth.NewUserRequest{Username: name, Password: "Password1!"})
+		user.Email = &email
+		return user
+	}
+
+	first := newUser(username, username+"@example.com")
+	if err := repo.CreateUser(first); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	t.Cleanup(func() { repo.DeleteUser(*first.ID) })
+
+	cases := map[string]struct {
+		user    *auth.User
+		message string
+	}{
+		"same username": {newUser(username, username+"-other@example.com"), "username already exists"},
+		"same email":    {newUser(username+"x", username+"@example.com"), "email already exists"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := repo.CreateUser(tc.user)
+			if !errors.Is(err, auth.ErrUserExists) {
+				t.Fatalf("Expected ErrUserExists, got %v", err)
+			}
+			if err.Error() != tc.message {
+				t.Errorf("Expected %q, got %q", tc.message, err.Error())
+			}
+		})
+	}
+}
+
+func TestRegistrationIsCaseInsensitive(t *testing.T) {
+	repo := newTestUserRepository(t)
+	t.Cleanup(func() { auth.SetCaseInsensitiveUsernames(false) })
+	auth.SetCaseInsensitiveUsernames(true)
+
+	suffix := time.Now().UnixNano()
+	username := fmt.Sprintf("Case%d", suffix)
+	email := fmt.Sprintf("A%d@X.com", suffix)
+
+	user := auth.NewUserFromRequest(&auth.NewUserRequest{Username: username, Password: "Password1!", Email: email})
+	if err := repo.CreateUser(user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	t.Cleanup(func() { repo.DeleteUser(*user.ID) })
+	if *user.Email != strings.ToLower(email) {
+		t.Errorf("Expected the email to be stored as %s, got %s", strings.ToLower(email), *user.Email)
+	}
+
+	// Logging in looks the user up by the normalized username
+	creds := &auth.UserLoginCredentials{Username: auth.NormalizeUsername(strings.ToUpper(username)), Password: "Password1!"}
+	if ok, err := repo.AuthenticateUser(creds); err != nil || !ok {
+		t.Errorf("Expected login as %s to succeed, got %v, %v", strings.ToUpper(username), ok, err)
+	}
+
+	duplicates := map[string]*auth.NewUserRequest{
+		"username in other case": {Username: strings.ToUpper(username), Password: "Password1!", Email: fmt.Sprintf("other%d@x.com", suffix)},
+		"email in other case":    {Username: fmt.Sprintf("other%d", suffix), Password: "Password1!", Email: strings.ToLower(email)},
+	}
+	for name, req := range duplicates {
+		t.Run(name, func(t *testing.T) {
+			if err := repo.CreateUser(auth.NewUserFromRequest(req)); !errors.Is(err, auth.ErrUserExists) {
+				t.Errorf("Expected ErrUserExists, got %v", err)
+			}
+		})
+	}
+}
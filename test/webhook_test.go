@@ -0,0 +1,73 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pomodoroService/internal/clock"
+)
+
+func TestCompletionWebhook(t *testing.T) {
+	payloads := make(chan clock.CompletionWebhookPayload, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload clock.CompletionWebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Failed to decode webhook payload: %v", err)
+		}
+		payloads <- payload
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cr := NewShortDurationClockRunner()
+	cr.SetDurations(100*time.Millisecond, 100*time.Millisecond, 100*time.Millisecond)
+	cr.SetCompletionWebhook(server.URL)
+
+	if _, err := cr.Start(); err != nil {
+		t.Fatalf("Failed to start clock: %v", err)
+	}
+	defer cr.Stop()
+
+	select {
+	case payload := <-payloads:
+		if payload.CompletedState != clock.StateWorking {
+			t.Errorf("Expected completed state to be W, got %s", payload.CompletedState)
+		}
+		if payload.NextState != clock.StateShortBreak {
+			t.Errorf("Expected next state to be SB, got %s", payload.NextState)
+		}
+		if payload.SessionNumber != 0 {
+			t.Errorf("Expected session number to be 0, got %d", payload.SessionNumber)
+		}
+		if payload.CompletedAt.IsZero() {
+			t.Error("Expected completedAt to be set")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for completion webhook")
+	}
+}
+
+func TestCompletionWebhookFailureIsNonFatal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	cr := NewShortDurationClockRunner()
+	cr.SetDurations(100*time.Millisecond, 100*time.Millisecond, 100*time.Millisecond)
+	cr.SetCompletionWebhook(server.URL)
+
+	if _, err := cr.Start(); err != nil {
+		t.Fatalf("Failed to start clock: %v", err)
+	}
+	defer cr.Stop()
+
+	// The clock should keep advancing even though every delivery fails
+	time.Sleep(350 * time.Millisecond)
+	if cr.GetCurrentSession() == 0 {
+		t.Error("Expected clock to advance past the first session despite webhook failures")
+	}
+}